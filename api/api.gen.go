@@ -0,0 +1,3007 @@
+// Package api provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for Amenity.
+const (
+	AirConditioning Amenity = "air_conditioning"
+	Appliances      Amenity = "appliances"
+	Dishwasher      Amenity = "dishwasher"
+	Fridge          Amenity = "fridge"
+	Furniture       Amenity = "furniture"
+	Internet        Amenity = "internet"
+	Tv              Amenity = "tv"
+	WashingMachine  Amenity = "washing_machine"
+)
+
+// Defines values for Balcony.
+const (
+	BalconyBalcony Balcony = "balcony"
+	BalconyLoggia  Balcony = "loggia"
+	BalconyNone    Balcony = "none"
+)
+
+// Defines values for ConstructionStatus.
+const (
+	Built             ConstructionStatus = "built"
+	UnderConstruction ConstructionStatus = "under_construction"
+)
+
+// Defines values for HealthStatus.
+const (
+	Fail HealthStatus = "fail"
+	Ok   HealthStatus = "ok"
+)
+
+// Defines values for LogLevelLevel.
+const (
+	LogLevelLevelDebug LogLevelLevel = "debug"
+	LogLevelLevelError LogLevelLevel = "error"
+	LogLevelLevelInfo  LogLevelLevel = "info"
+	LogLevelLevelWarn  LogLevelLevel = "warn"
+)
+
+// Defines values for Material.
+const (
+	Block         Material = "block"
+	Brick         Material = "brick"
+	Monolith      Material = "monolith"
+	MonolithBrick Material = "monolith_brick"
+	Panel         Material = "panel"
+	Wood          Material = "wood"
+)
+
+// Defines values for NotificationStatus.
+const (
+	Failed  NotificationStatus = "failed"
+	Pending NotificationStatus = "pending"
+	Sent    NotificationStatus = "sent"
+)
+
+// Defines values for Parking.
+const (
+	ParkingCovered     Parking = "covered"
+	ParkingNone        Parking = "none"
+	ParkingOpen        Parking = "open"
+	ParkingUnderground Parking = "underground"
+)
+
+// Defines values for PhotoKind.
+const (
+	PhotoKindFloorPlan PhotoKind = "floor_plan"
+	PhotoKindPhoto     PhotoKind = "photo"
+)
+
+// Defines values for Renovation.
+const (
+	Cosmetic Renovation = "cosmetic"
+	Designer Renovation = "designer"
+	Euro     Renovation = "euro"
+	None     Renovation = "none"
+)
+
+// Defines values for Role.
+const (
+	RoleAdmin     Role = "admin"
+	RoleClient    Role = "client"
+	RoleModerator Role = "moderator"
+)
+
+// Defines values for Status.
+const (
+	Approved     Status = "approved"
+	Created      Status = "created"
+	Declined     Status = "declined"
+	OnModeration Status = "on moderation"
+)
+
+// Defines values for UserStatus.
+const (
+	Active    UserStatus = "active"
+	Erased    UserStatus = "erased"
+	Suspended UserStatus = "suspended"
+)
+
+// Defines values for UserType.
+const (
+	UserTypeClient    UserType = "client"
+	UserTypeModerator UserType = "moderator"
+)
+
+// Defines values for GetFlatsSearchParamsSort.
+const (
+	GetFlatsSearchParamsSortId         GetFlatsSearchParamsSort = "id"
+	GetFlatsSearchParamsSortMinusId    GetFlatsSearchParamsSort = "-id"
+	GetFlatsSearchParamsSortMinusPrice GetFlatsSearchParamsSort = "-price"
+	GetFlatsSearchParamsSortMinusRooms GetFlatsSearchParamsSort = "-rooms"
+	GetFlatsSearchParamsSortMinusYear  GetFlatsSearchParamsSort = "-year"
+	GetFlatsSearchParamsSortPrice      GetFlatsSearchParamsSort = "price"
+	GetFlatsSearchParamsSortRooms      GetFlatsSearchParamsSort = "rooms"
+	GetFlatsSearchParamsSortYear       GetFlatsSearchParamsSort = "year"
+)
+
+// Address Адрес дома
+type Address = string
+
+// Amenities Набор удобств без повторов
+type Amenities = []Amenity
+
+// Amenity Удобство в квартире
+type Amenity string
+
+// Area Площадь в м², до двух знаков после запятой
+type Area = float64
+
+// Balcony Балкон или лоджия
+type Balcony string
+
+// CeilingHeight Высота потолков в метрах
+type CeilingHeight = float64
+
+// ConstructionStatus Дом построен или строится
+type ConstructionStatus string
+
+// Date Дата + время
+type Date = time.Time
+
+// Developer Застройщик
+type Developer = string
+
+// DeveloperCompany Застройщик
+type DeveloperCompany struct {
+	// CreatedAt Дата + время
+	CreatedAt *Date `json:"created_at,omitempty"`
+
+	// Id Идентификатор застройщика
+	Id DeveloperId `json:"id"`
+
+	// Name Название застройщика
+	Name DeveloperName `json:"name"`
+
+	// Website Сайт
+	Website *Website `json:"website,omitempty"`
+}
+
+// DeveloperId Идентификатор застройщика
+type DeveloperId = int
+
+// DeveloperName Название застройщика
+type DeveloperName = string
+
+// Elevators Количество лифтов в подъезде
+type Elevators = int
+
+// Email Email пользователя
+type Email = openapi_types.Email
+
+// Error defines model for Error.
+type Error struct {
+	// Code Код ошибки. Стабилен между версиями и не зависит от текста сообщения: 40000 невалидный запрос, 40001 невалидное тело, 40002 невалидный параметр, 40003 нарушение ограничений данных, 40100 нет авторизации, 40101 невалидный токен, 40102 неверные учетные данные, 40300 недостаточно прав, 40301 пользователь неактивен, 40302 требуется сброс пароля, 40400 объект не найден, 40401 пользователь не найден, 40402 невалидный токен сброса, 40403 маршрут не найден, 40500 метод не поддерживается, 40900 объект уже существует, 40901 запрос с этим Idempotency-Key еще выполняется, 40902 достигнут лимит фотографий квартиры, 40903 объект занят другим пользователем (квартира на модерации у другого модератора), 41200 объект изменен с версии из If-Match, 41300 слишком большой файл, 41500 неподдерживаемый тип файла, 42200 Idempotency-Key уже использован для другого запроса, 42800 требуется If-Match, 42900 превышен лимит запросов, 50000 внутренняя ошибка, 50300 сервис временно недоступен.
+	Code ErrorCode `json:"code"`
+
+	// Errors Ошибки валидации по полям (только для кода 40000)
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Message Описание ошибки
+	Message string `json:"message"`
+
+	// RequestId Идентификатор запроса. Предназначен для более быстрого поиска проблем.
+	RequestId string `json:"request_id"`
+}
+
+// ErrorCode Код ошибки. Стабилен между версиями и не зависит от текста сообщения: 40000 невалидный запрос, 40001 невалидное тело, 40002 невалидный параметр, 40003 нарушение ограничений данных, 40100 нет авторизации, 40101 невалидный токен, 40102 неверные учетные данные, 40300 недостаточно прав, 40301 пользователь неактивен, 40302 требуется сброс пароля, 40400 объект не найден, 40401 пользователь не найден, 40402 невалидный токен сброса, 40403 маршрут не найден, 40500 метод не поддерживается, 40900 объект уже существует, 40901 запрос с этим Idempotency-Key еще выполняется, 40902 достигнут лимит фотографий квартиры, 40903 объект занят другим пользователем (квартира на модерации у другого модератора), 41200 объект изменен с версии из If-Match, 41300 слишком большой файл, 41500 неподдерживаемый тип файла, 42200 Idempotency-Key уже использован для другого запроса, 42800 требуется If-Match, 42900 превышен лимит запросов, 50000 внутренняя ошибка, 50300 сервис временно недоступен.
+type ErrorCode = int
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Flat Квартира
+type Flat struct {
+	// Amenities Набор удобств без повторов
+	Amenities *Amenities `json:"amenities,omitempty"`
+
+	// Balcony Балкон или лоджия
+	Balcony *Balcony `json:"balcony,omitempty"`
+
+	// CeilingHeight Высота потолков в метрах
+	CeilingHeight *CeilingHeight `json:"ceiling_height,omitempty"`
+
+	// Floor Этаж квартиры
+	Floor *Floor `json:"floor,omitempty"`
+
+	// HouseId Идентификатор дома
+	HouseId HouseId `json:"house_id"`
+
+	// Id Идентификатор квартиры
+	Id FlatId `json:"id"`
+
+	// LivingArea Площадь в м², до двух знаков после запятой
+	LivingArea *Area `json:"living_area,omitempty"`
+
+	// Number Номер квартиры в доме
+	Number *FlatNumber `json:"number,omitempty"`
+
+	// Photos Фотографии в порядке показа, только у одобренных квартир
+	Photos *[]Photo `json:"photos,omitempty"`
+
+	// Price Цена квартиры в у.е.
+	Price Price `json:"price"`
+
+	// PricePerSqm Цена квадратного метра общей площади, округленная до целого. Отсутствует, если общая площадь не указана
+	PricePerSqm *int `json:"price_per_sqm,omitempty"`
+
+	// Renovation Ремонт - без ремонта, косметический, евроремонт или дизайнерский
+	Renovation *Renovation `json:"renovation,omitempty"`
+
+	// Rooms Количество комнат в квартире
+	Rooms Rooms `json:"rooms"`
+
+	// Status Статус квартиры
+	Status Status `json:"status"`
+
+	// TotalArea Площадь в м², до двух знаков после запятой
+	TotalArea *Area `json:"total_area,omitempty"`
+
+	// UserId Идентификатор пользователя
+	UserId *UserId `json:"user_id,omitempty"`
+
+	// Version Версия объекта, увеличивается при каждом изменении. Совпадает со значением ETag
+	Version Version `json:"version"`
+}
+
+// FlatId Идентификатор квартиры
+type FlatId = int
+
+// FlatNumber Номер квартиры в доме
+type FlatNumber = int
+
+// FlatSearchResult defines model for FlatSearchResult.
+type FlatSearchResult struct {
+	Flats []Flat `json:"flats"`
+
+	// NextCursor Курсор следующей страницы, отсутствует на последней
+	NextCursor *string `json:"next_cursor,omitempty"`
+
+	// Total Количество всех найденных квартир, а не только на этой странице
+	Total int `json:"total"`
+}
+
+// Floor Этаж квартиры
+type Floor = int
+
+// Floors Количество этажей в доме
+type Floors = int
+
+// Health defines model for Health.
+type Health struct {
+	// Checks Результаты проверок по имени зависимости (только для /readyz)
+	Checks *map[string]struct {
+		DurationMs float32      `json:"duration_ms"`
+		Error      *string      `json:"error,omitempty"`
+		Status     HealthStatus `json:"status"`
+	} `json:"checks,omitempty"`
+	Status HealthStatus `json:"status"`
+}
+
+// HealthStatus defines model for HealthStatus.
+type HealthStatus string
+
+// House Дом
+type House struct {
+	// Address Адрес дома
+	Address Address `json:"address"`
+
+	// CompletionQuarter Ожидаемый квартал сдачи строящегося дома
+	CompletionQuarter *Quarter `json:"completion_quarter,omitempty"`
+
+	// ConstructionStatus Дом построен или строится
+	ConstructionStatus *ConstructionStatus `json:"construction_status,omitempty"`
+
+	// CreatedAt Дата + время
+	CreatedAt *Date `json:"created_at,omitempty"`
+
+	// Developer Застройщик
+	Developer *Developer `json:"developer"`
+
+	// DeveloperId Идентификатор застройщика
+	DeveloperId *DeveloperId `json:"developer_id,omitempty"`
+
+	// Elevators Количество лифтов в подъезде
+	Elevators *Elevators `json:"elevators,omitempty"`
+
+	// Floors Количество этажей в доме
+	Floors *Floors `json:"floors,omitempty"`
+
+	// Id Идентификатор дома
+	Id HouseId `json:"id"`
+
+	// Lat Широта в градусах
+	Lat *Latitude `json:"lat,omitempty"`
+
+	// Lon Долгота в градусах
+	Lon *Longitude `json:"lon,omitempty"`
+
+	// Material Материал стен
+	Material *Material `json:"material,omitempty"`
+
+	// Parking Парковка - нет, открытая, крытая или подземная
+	Parking *Parking `json:"parking,omitempty"`
+
+	// Postcode Почтовый индекс, выделенный из адреса
+	Postcode *Postcode `json:"postcode,omitempty"`
+
+	// UpdateAt Дата + время
+	UpdateAt *Date `json:"update_at,omitempty"`
+
+	// Version Версия объекта, увеличивается при каждом изменении. Совпадает со значением ETag
+	Version Version `json:"version"`
+
+	// Year Год постройки дома
+	Year Year `json:"year"`
+}
+
+// HouseCreated defines model for HouseCreated.
+type HouseCreated struct {
+	// Address Адрес дома
+	Address Address `json:"address"`
+
+	// CompletionQuarter Ожидаемый квартал сдачи строящегося дома
+	CompletionQuarter *Quarter `json:"completion_quarter,omitempty"`
+
+	// ConstructionStatus Дом построен или строится
+	ConstructionStatus *ConstructionStatus `json:"construction_status,omitempty"`
+
+	// CreatedAt Дата + время
+	CreatedAt *Date `json:"created_at,omitempty"`
+
+	// Developer Застройщик
+	Developer *Developer `json:"developer"`
+
+	// DeveloperId Идентификатор застройщика
+	DeveloperId *DeveloperId `json:"developer_id,omitempty"`
+
+	// Elevators Количество лифтов в подъезде
+	Elevators *Elevators `json:"elevators,omitempty"`
+
+	// Floors Количество этажей в доме
+	Floors *Floors `json:"floors,omitempty"`
+
+	// Id Идентификатор дома
+	Id HouseId `json:"id"`
+
+	// Lat Широта в градусах
+	Lat *Latitude `json:"lat,omitempty"`
+
+	// Lon Долгота в градусах
+	Lon *Longitude `json:"lon,omitempty"`
+
+	// Material Материал стен
+	Material *Material `json:"material,omitempty"`
+
+	// Parking Парковка - нет, открытая, крытая или подземная
+	Parking *Parking `json:"parking,omitempty"`
+
+	// PossibleDuplicates Уже существующие дома, похожие на созданный по адресу, от самых похожих. Отсутствует, если таких нет
+	PossibleDuplicates *[]House `json:"possible_duplicates,omitempty"`
+
+	// Postcode Почтовый индекс, выделенный из адреса
+	Postcode *Postcode `json:"postcode,omitempty"`
+
+	// UpdateAt Дата + время
+	UpdateAt *Date `json:"update_at,omitempty"`
+
+	// Version Версия объекта, увеличивается при каждом изменении. Совпадает со значением ETag
+	Version Version `json:"version"`
+
+	// Year Год постройки дома
+	Year Year `json:"year"`
+}
+
+// HouseId Идентификатор дома
+type HouseId = int
+
+// HouseSearchResult defines model for HouseSearchResult.
+type HouseSearchResult struct {
+	Houses []House `json:"houses"`
+}
+
+// Latitude Широта в градусах
+type Latitude = float64
+
+// LogLevel defines model for LogLevel.
+type LogLevel struct {
+	Level LogLevelLevel `json:"level"`
+}
+
+// LogLevelLevel defines model for LogLevel.Level.
+type LogLevelLevel string
+
+// Longitude Долгота в градусах
+type Longitude = float64
+
+// Material Материал стен
+type Material string
+
+// NearbyHouse defines model for NearbyHouse.
+type NearbyHouse struct {
+	// Address Адрес дома
+	Address Address `json:"address"`
+
+	// CompletionQuarter Ожидаемый квартал сдачи строящегося дома
+	CompletionQuarter *Quarter `json:"completion_quarter,omitempty"`
+
+	// ConstructionStatus Дом построен или строится
+	ConstructionStatus *ConstructionStatus `json:"construction_status,omitempty"`
+
+	// CreatedAt Дата + время
+	CreatedAt *Date `json:"created_at,omitempty"`
+
+	// Developer Застройщик
+	Developer *Developer `json:"developer"`
+
+	// DeveloperId Идентификатор застройщика
+	DeveloperId *DeveloperId `json:"developer_id,omitempty"`
+
+	// Distance Расстояние от точки поиска в метрах
+	Distance float64 `json:"distance"`
+
+	// Elevators Количество лифтов в подъезде
+	Elevators *Elevators `json:"elevators,omitempty"`
+
+	// Floors Количество этажей в доме
+	Floors *Floors `json:"floors,omitempty"`
+
+	// Id Идентификатор дома
+	Id HouseId `json:"id"`
+
+	// Lat Широта в градусах
+	Lat *Latitude `json:"lat,omitempty"`
+
+	// Lon Долгота в градусах
+	Lon *Longitude `json:"lon,omitempty"`
+
+	// Material Материал стен
+	Material *Material `json:"material,omitempty"`
+
+	// Parking Парковка - нет, открытая, крытая или подземная
+	Parking *Parking `json:"parking,omitempty"`
+
+	// Postcode Почтовый индекс, выделенный из адреса
+	Postcode *Postcode `json:"postcode,omitempty"`
+
+	// UpdateAt Дата + время
+	UpdateAt *Date `json:"update_at,omitempty"`
+
+	// Version Версия объекта, увеличивается при каждом изменении. Совпадает со значением ETag
+	Version Version `json:"version"`
+
+	// Year Год постройки дома
+	Year Year `json:"year"`
+}
+
+// Notification Отправленное подписчику уведомление
+type Notification struct {
+	// CreatedAt Дата + время
+	CreatedAt *Date  `json:"created_at,omitempty"`
+	Email     string `json:"email"`
+
+	// HouseId Идентификатор дома
+	HouseId HouseId            `json:"house_id"`
+	Id      int                `json:"id"`
+	Message string             `json:"message"`
+	Status  NotificationStatus `json:"status"`
+}
+
+// NotificationStatus defines model for Notification.Status.
+type NotificationStatus string
+
+// Parking Парковка - нет, открытая, крытая или подземная
+type Parking string
+
+// Password Пароль пользователя
+type Password = string
+
+// Photo Фотография или планировка квартиры
+type Photo struct {
+	Height int `json:"height"`
+
+	// Id Идентификатор фотографии
+	Id PhotoId `json:"id"`
+
+	// Kind Фотография или планировка
+	Kind PhotoKind `json:"kind"`
+
+	// Position Порядковый номер при показе, с 1
+	Position int `json:"position"`
+
+	// ThumbnailUrl Путь к миниатюре
+	ThumbnailUrl string `json:"thumbnail_url"`
+
+	// Url Путь к файлу фотографии
+	Url   string `json:"url"`
+	Width int    `json:"width"`
+}
+
+// PhotoId Идентификатор фотографии
+type PhotoId = int
+
+// PhotoKind Фотография или планировка
+type PhotoKind string
+
+// PhotoList defines model for PhotoList.
+type PhotoList struct {
+	Photos []Photo `json:"photos"`
+}
+
+// Postcode Почтовый индекс, выделенный из адреса
+type Postcode = string
+
+// Price Цена квартиры в у.е.
+type Price = int
+
+// PricePoint Цена квартиры, действовавшая с момента changed_at
+type PricePoint struct {
+	// ChangedAt Дата + время
+	ChangedAt Date `json:"changed_at"`
+
+	// Price Цена квартиры в у.е.
+	Price Price `json:"price"`
+}
+
+// Quarter Ожидаемый квартал сдачи строящегося дома
+type Quarter = string
+
+// Renovation Ремонт - без ремонта, косметический, евроремонт или дизайнерский
+type Renovation string
+
+// ResetToken Одноразовый токен сброса пароля
+type ResetToken = openapi_types.UUID
+
+// Role Тип пользователя, назначаемый администратором
+type Role string
+
+// Rooms Количество комнат в квартире
+type Rooms = int
+
+// Session Сессия пользователя, выданная при входе
+type Session struct {
+	// CreatedAt Дата + время
+	CreatedAt Date `json:"created_at"`
+
+	// ExpiresAt Дата + время
+	ExpiresAt Date               `json:"expires_at"`
+	Id        openapi_types.UUID `json:"id"`
+
+	// RevokedAt Дата + время
+	RevokedAt *Date `json:"revoked_at,omitempty"`
+
+	// UserId Идентификатор пользователя
+	UserId UserId `json:"user_id"`
+}
+
+// Status Статус квартиры
+type Status string
+
+// Subscription Подписка на новые квартиры в доме
+type Subscription struct {
+	// CreatedAt Дата + время
+	CreatedAt *Date `json:"created_at,omitempty"`
+
+	// Email Email пользователя
+	Email Email `json:"email"`
+
+	// HouseId Идентификатор дома
+	HouseId HouseId `json:"house_id"`
+}
+
+// Token Авторизационный токен
+type Token = string
+
+// User Пользователь
+type User struct {
+	// CreatedAt Дата + время
+	CreatedAt *Date `json:"created_at,omitempty"`
+
+	// Email Email пользователя
+	Email Email `json:"email"`
+
+	// Id Идентификатор пользователя
+	Id            UserId `json:"id"`
+	ResetRequired *bool  `json:"reset_required,omitempty"`
+
+	// Status Состояние учетной записи
+	Status UserStatus `json:"status"`
+
+	// UserType Тип пользователя, назначаемый администратором
+	UserType Role `json:"user_type"`
+}
+
+// UserExport Архив персональных данных пользователя
+type UserExport struct {
+	// ExportedAt Дата + время
+	ExportedAt    Date           `json:"exported_at"`
+	Flats         []Flat         `json:"flats"`
+	Notifications []Notification `json:"notifications"`
+	Subscriptions []Subscription `json:"subscriptions"`
+
+	// User Пользователь
+	User User `json:"user"`
+}
+
+// UserId Идентификатор пользователя
+type UserId = openapi_types.UUID
+
+// UserStatus Состояние учетной записи
+type UserStatus string
+
+// UserType Тип пользователя
+type UserType string
+
+// Version Версия объекта, увеличивается при каждом изменении. Совпадает со значением ETag
+type Version = int
+
+// Website Сайт
+type Website = string
+
+// Year Год постройки дома
+type Year = int
+
+// IdempotencyKey defines model for IdempotencyKey.
+type IdempotencyKey = string
+
+// UserIdPath Идентификатор пользователя
+type UserIdPath = UserId
+
+// N400 defines model for 400.
+type N400 = Error
+
+// N401 defines model for 401.
+type N401 = Error
+
+// N403 defines model for 403.
+type N403 = Error
+
+// N404 defines model for 404.
+type N404 = Error
+
+// N409 defines model for 409.
+type N409 = Error
+
+// N412 defines model for 412.
+type N412 = Error
+
+// N413 defines model for 413.
+type N413 = Error
+
+// N415 defines model for 415.
+type N415 = Error
+
+// N422 defines model for 422.
+type N422 = Error
+
+// N428 defines model for 428.
+type N428 = Error
+
+// N429 defines model for 429.
+type N429 = Error
+
+// N5xx defines model for 5xx.
+type N5xx = Error
+
+// GetAdminUsersParams defines parameters for GetAdminUsers.
+type GetAdminUsersParams struct {
+	Email *string `form:"email,omitempty" json:"email,omitempty"`
+}
+
+// PostAdminUsersIdRoleJSONBody defines parameters for PostAdminUsersIdRole.
+type PostAdminUsersIdRoleJSONBody struct {
+	// UserType Тип пользователя, назначаемый администратором
+	UserType Role `json:"user_type"`
+}
+
+// PostDeveloperCreateJSONBody defines parameters for PostDeveloperCreate.
+type PostDeveloperCreateJSONBody struct {
+	// Name Название застройщика
+	Name DeveloperName `json:"name"`
+
+	// Website Сайт
+	Website *Website `json:"website,omitempty"`
+}
+
+// GetDummyLoginParams defines parameters for GetDummyLogin.
+type GetDummyLoginParams struct {
+	UserType UserType `form:"user_type" json:"user_type"`
+}
+
+// PostFlatCreateJSONBody defines parameters for PostFlatCreate.
+type PostFlatCreateJSONBody struct {
+	// Amenities Набор удобств без повторов
+	Amenities *Amenities `json:"amenities,omitempty"`
+
+	// Balcony Балкон или лоджия
+	Balcony *Balcony `json:"balcony,omitempty"`
+
+	// CeilingHeight Высота потолков в метрах
+	CeilingHeight *CeilingHeight `json:"ceiling_height,omitempty"`
+
+	// Floor Этаж квартиры
+	Floor *Floor `json:"floor,omitempty"`
+
+	// HouseId Идентификатор дома
+	HouseId HouseId `json:"house_id"`
+
+	// LivingArea Площадь в м², до двух знаков после запятой
+	LivingArea *Area `json:"living_area,omitempty"`
+
+	// Number Номер квартиры в доме
+	Number *FlatNumber `json:"number,omitempty"`
+
+	// Price Цена квартиры в у.е.
+	Price Price `json:"price"`
+
+	// Renovation Ремонт - без ремонта, косметический, евроремонт или дизайнерский
+	Renovation *Renovation `json:"renovation,omitempty"`
+
+	// Rooms Количество комнат в квартире
+	Rooms Rooms `json:"rooms"`
+
+	// TotalArea Площадь в м², до двух знаков после запятой
+	TotalArea *Area `json:"total_area,omitempty"`
+}
+
+// PostFlatCreateParams defines parameters for PostFlatCreate.
+type PostFlatCreateParams struct {
+	// IdempotencyKey Ключ, общий для всех попыток одной операции. Повтор запроса с тем же ключом и телом в течение суток возвращает сохраненный ответ первой попытки (с заголовком Idempotent-Replayed) вместо повторного выполнения.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// PostFlatUpdateJSONBody defines parameters for PostFlatUpdate.
+type PostFlatUpdateJSONBody struct {
+	// Amenities Набор удобств без повторов
+	Amenities *Amenities `json:"amenities,omitempty"`
+
+	// Balcony Балкон или лоджия
+	Balcony *Balcony `json:"balcony,omitempty"`
+
+	// CeilingHeight Высота потолков в метрах
+	CeilingHeight *CeilingHeight `json:"ceiling_height,omitempty"`
+
+	// Floor Этаж квартиры
+	Floor *Floor `json:"floor,omitempty"`
+
+	// HouseId Идентификатор дома
+	HouseId *HouseId `json:"house_id,omitempty"`
+
+	// Id Идентификатор квартиры
+	Id FlatId `json:"id"`
+
+	// LivingArea Площадь в м², до двух знаков после запятой
+	LivingArea *Area `json:"living_area,omitempty"`
+
+	// Number Номер квартиры в доме
+	Number *FlatNumber `json:"number,omitempty"`
+
+	// Price Цена квартиры в у.е.
+	Price *Price `json:"price,omitempty"`
+
+	// Renovation Ремонт - без ремонта, косметический, евроремонт или дизайнерский
+	Renovation *Renovation `json:"renovation,omitempty"`
+
+	// Rooms Количество комнат в квартире
+	Rooms *Rooms `json:"rooms,omitempty"`
+
+	// Status Статус квартиры
+	Status *Status `json:"status,omitempty"`
+
+	// TotalArea Площадь в м², до двух знаков после запятой
+	TotalArea *Area `json:"total_area,omitempty"`
+}
+
+// PostFlatUpdateParams defines parameters for PostFlatUpdate.
+type PostFlatUpdateParams struct {
+	// IfMatch ETag квартиры, например "3", или * для обновления любой версии. Без заголовка возвращается 428
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// PostFlatIdPhotosMultipartBody defines parameters for PostFlatIdPhotos.
+type PostFlatIdPhotosMultipartBody struct {
+	File openapi_types.File `json:"file"`
+
+	// Kind Фотография или планировка
+	Kind *PhotoKind `json:"kind,omitempty"`
+}
+
+// PostFlatIdPhotosOrderJSONBody defines parameters for PostFlatIdPhotosOrder.
+type PostFlatIdPhotosOrderJSONBody struct {
+	PhotoIds []PhotoId `json:"photo_ids"`
+}
+
+// GetFlatsSearchParams defines parameters for GetFlatsSearch.
+type GetFlatsSearchParams struct {
+	// PriceMin Минимальная цена
+	PriceMin *int `form:"price_min,omitempty" json:"price_min,omitempty"`
+
+	// PriceMax Максимальная цена
+	PriceMax *int `form:"price_max,omitempty" json:"price_max,omitempty"`
+
+	// RoomsMin Минимальное количество комнат
+	RoomsMin *int `form:"rooms_min,omitempty" json:"rooms_min,omitempty"`
+
+	// RoomsMax Максимальное количество комнат
+	RoomsMax *int `form:"rooms_max,omitempty" json:"rooms_max,omitempty"`
+
+	// YearMin Минимальный год постройки дома
+	YearMin *int `form:"year_min,omitempty" json:"year_min,omitempty"`
+
+	// YearMax Максимальный год постройки дома
+	YearMax *int `form:"year_max,omitempty" json:"year_max,omitempty"`
+
+	// FloorMin Минимальный этаж
+	FloorMin *int `form:"floor_min,omitempty" json:"floor_min,omitempty"`
+
+	// FloorMax Максимальный этаж
+	FloorMax *int `form:"floor_max,omitempty" json:"floor_max,omitempty"`
+
+	// AreaMin Минимальная общая площадь, м²
+	AreaMin *float32 `form:"area_min,omitempty" json:"area_min,omitempty"`
+
+	// AreaMax Максимальная общая площадь, м²
+	AreaMax *float32 `form:"area_max,omitempty" json:"area_max,omitempty"`
+
+	// HasBalcony true - только с балконом или лоджией, false - только без них
+	HasBalcony *bool `form:"has_balcony,omitempty" json:"has_balcony,omitempty"`
+
+	// Renovation Тип ремонта, любой из перечисленных (renovation=euro&renovation=designer)
+	Renovation *[]Renovation `form:"renovation,omitempty" json:"renovation,omitempty"`
+
+	// Amenities Удобства, которые должны быть все (amenities=internet&amenities=furniture)
+	Amenities *[]Amenity `form:"amenities,omitempty" json:"amenities,omitempty"`
+
+	// Developer Застройщик, без учета регистра
+	Developer *string `form:"developer,omitempty" json:"developer,omitempty"`
+
+	// Q Полнотекстовый поиск по адресу дома (русская морфология, поддерживаются "фразы в кавычках", OR и -исключение)
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Sort Поле сортировки, минус - по убыванию. При равенстве - по id
+	Sort   *GetFlatsSearchParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+	Limit  *int                      `form:"limit,omitempty" json:"limit,omitempty"`
+	Cursor *string                   `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetFlatsSearchParamsSort defines parameters for GetFlatsSearch.
+type GetFlatsSearchParamsSort string
+
+// GetHouseBboxParams defines parameters for GetHouseBbox.
+type GetHouseBboxParams struct {
+	// MinLat Южная граница
+	MinLat Latitude `form:"min_lat" json:"min_lat"`
+
+	// MinLon Западная граница
+	MinLon Longitude `form:"min_lon" json:"min_lon"`
+
+	// MaxLat Северная граница
+	MaxLat Latitude `form:"max_lat" json:"max_lat"`
+
+	// MaxLon Восточная граница
+	MaxLon Longitude `form:"max_lon" json:"max_lon"`
+	Limit  *int      `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostHouseCreateJSONBody defines parameters for PostHouseCreate.
+type PostHouseCreateJSONBody struct {
+	// Address Адрес дома
+	Address Address `json:"address"`
+
+	// CompletionQuarter Ожидаемый квартал сдачи строящегося дома
+	CompletionQuarter *Quarter `json:"completion_quarter,omitempty"`
+
+	// ConstructionStatus Дом построен или строится
+	ConstructionStatus *ConstructionStatus `json:"construction_status,omitempty"`
+
+	// Developer Застройщик
+	Developer *Developer `json:"developer"`
+
+	// DeveloperId Идентификатор застройщика
+	DeveloperId *DeveloperId `json:"developer_id,omitempty"`
+
+	// Elevators Количество лифтов в подъезде
+	Elevators *Elevators `json:"elevators,omitempty"`
+
+	// Floors Количество этажей в доме
+	Floors *Floors `json:"floors,omitempty"`
+
+	// Id Идентификатор дома
+	Id HouseId `json:"id"`
+
+	// Lat Широта в градусах
+	Lat *Latitude `json:"lat,omitempty"`
+
+	// Lon Долгота в градусах
+	Lon *Longitude `json:"lon,omitempty"`
+
+	// Material Материал стен
+	Material *Material `json:"material,omitempty"`
+
+	// Parking Парковка - нет, открытая, крытая или подземная
+	Parking *Parking `json:"parking,omitempty"`
+
+	// Year Год постройки дома
+	Year Year `json:"year"`
+}
+
+// PostHouseCreateParams defines parameters for PostHouseCreate.
+type PostHouseCreateParams struct {
+	// IdempotencyKey Ключ, общий для всех попыток одной операции. Повтор запроса с тем же ключом и телом в течение суток возвращает сохраненный ответ первой попытки (с заголовком Idempotent-Replayed) вместо повторного выполнения.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetHouseNearbyParams defines parameters for GetHouseNearby.
+type GetHouseNearbyParams struct {
+	// Lat Широта центра
+	Lat Latitude `form:"lat" json:"lat"`
+
+	// Lon Долгота центра
+	Lon Longitude `form:"lon" json:"lon"`
+
+	// Radius Радиус поиска в метрах
+	Radius float32 `form:"radius" json:"radius"`
+	Limit  *int    `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostHouseUpdateJSONBody defines parameters for PostHouseUpdate.
+type PostHouseUpdateJSONBody struct {
+	// CompletionQuarter Ожидаемый квартал сдачи строящегося дома
+	CompletionQuarter *Quarter `json:"completion_quarter,omitempty"`
+
+	// ConstructionStatus Дом построен или строится
+	ConstructionStatus *ConstructionStatus `json:"construction_status,omitempty"`
+
+	// DeveloperId Идентификатор застройщика
+	DeveloperId *DeveloperId `json:"developer_id,omitempty"`
+
+	// Elevators Количество лифтов в подъезде
+	Elevators *Elevators `json:"elevators,omitempty"`
+
+	// Floors Количество этажей в доме
+	Floors *Floors `json:"floors,omitempty"`
+
+	// Id Идентификатор дома
+	Id HouseId `json:"id"`
+
+	// Material Материал стен
+	Material *Material `json:"material,omitempty"`
+
+	// Parking Парковка - нет, открытая, крытая или подземная
+	Parking *Parking `json:"parking,omitempty"`
+
+	// Year Год постройки дома
+	Year *Year `json:"year,omitempty"`
+}
+
+// PostHouseUpdateParams defines parameters for PostHouseUpdate.
+type PostHouseUpdateParams struct {
+	// IfMatch ETag дома, например "3", или * для обновления любой версии. Без заголовка возвращается 428
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// GetHouseIdParams defines parameters for GetHouseId.
+type GetHouseIdParams struct {
+	// IfNoneMatch ETag из предыдущего ответа. Если список не изменился, возвращается 304
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+}
+
+// PostHouseIdSubscribeJSONBody defines parameters for PostHouseIdSubscribe.
+type PostHouseIdSubscribeJSONBody struct {
+	// Email Email пользователя
+	Email Email `json:"email"`
+}
+
+// GetHousesSearchParams defines parameters for GetHousesSearch.
+type GetHousesSearchParams struct {
+	// Q Адрес или его часть
+	Q     string `form:"q" json:"q"`
+	Limit *int   `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostLoginJSONBody defines parameters for PostLogin.
+type PostLoginJSONBody struct {
+	// Email Email пользователя
+	Email *Email `json:"email,omitempty"`
+
+	// Id Идентификатор пользователя
+	Id *UserId `json:"id,omitempty"`
+
+	// Password Пароль пользователя
+	Password *Password `json:"password,omitempty"`
+}
+
+// PostPasswordResetJSONBody defines parameters for PostPasswordReset.
+type PostPasswordResetJSONBody struct {
+	// Password Пароль пользователя
+	Password Password `json:"password"`
+
+	// Token Одноразовый токен сброса пароля
+	Token ResetToken `json:"token"`
+}
+
+// PostRegisterJSONBody defines parameters for PostRegister.
+type PostRegisterJSONBody struct {
+	// Email Email пользователя
+	Email *Email `json:"email,omitempty"`
+
+	// Password Пароль пользователя
+	Password *Password `json:"password,omitempty"`
+
+	// UserType Тип пользователя
+	UserType *UserType `json:"user_type,omitempty"`
+}
+
+// PutAdminLogLevelJSONRequestBody defines body for PutAdminLogLevel for application/json ContentType.
+type PutAdminLogLevelJSONRequestBody = LogLevel
+
+// PostAdminUsersIdRoleJSONRequestBody defines body for PostAdminUsersIdRole for application/json ContentType.
+type PostAdminUsersIdRoleJSONRequestBody PostAdminUsersIdRoleJSONBody
+
+// PostDeveloperCreateJSONRequestBody defines body for PostDeveloperCreate for application/json ContentType.
+type PostDeveloperCreateJSONRequestBody PostDeveloperCreateJSONBody
+
+// PostFlatCreateJSONRequestBody defines body for PostFlatCreate for application/json ContentType.
+type PostFlatCreateJSONRequestBody PostFlatCreateJSONBody
+
+// PostFlatUpdateJSONRequestBody defines body for PostFlatUpdate for application/json ContentType.
+type PostFlatUpdateJSONRequestBody PostFlatUpdateJSONBody
+
+// PostFlatIdPhotosMultipartRequestBody defines body for PostFlatIdPhotos for multipart/form-data ContentType.
+type PostFlatIdPhotosMultipartRequestBody PostFlatIdPhotosMultipartBody
+
+// PostFlatIdPhotosOrderJSONRequestBody defines body for PostFlatIdPhotosOrder for application/json ContentType.
+type PostFlatIdPhotosOrderJSONRequestBody PostFlatIdPhotosOrderJSONBody
+
+// PostHouseCreateJSONRequestBody defines body for PostHouseCreate for application/json ContentType.
+type PostHouseCreateJSONRequestBody PostHouseCreateJSONBody
+
+// PostHouseUpdateJSONRequestBody defines body for PostHouseUpdate for application/json ContentType.
+type PostHouseUpdateJSONRequestBody PostHouseUpdateJSONBody
+
+// PostHouseIdSubscribeJSONRequestBody defines body for PostHouseIdSubscribe for application/json ContentType.
+type PostHouseIdSubscribeJSONRequestBody PostHouseIdSubscribeJSONBody
+
+// PostLoginJSONRequestBody defines body for PostLogin for application/json ContentType.
+type PostLoginJSONRequestBody PostLoginJSONBody
+
+// PostPasswordResetJSONRequestBody defines body for PostPasswordReset for application/json ContentType.
+type PostPasswordResetJSONRequestBody PostPasswordResetJSONBody
+
+// PostRegisterJSONRequestBody defines body for PostRegister for application/json ContentType.
+type PostRegisterJSONRequestBody PostRegisterJSONBody
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+
+	// (GET /admin/log-level)
+	GetAdminLogLevel(w http.ResponseWriter, r *http.Request)
+
+	// (PUT /admin/log-level)
+	PutAdminLogLevel(w http.ResponseWriter, r *http.Request)
+
+	// (GET /admin/users)
+	GetAdminUsers(w http.ResponseWriter, r *http.Request, params GetAdminUsersParams)
+
+	// (GET /admin/users/{id}/flats)
+	GetAdminUsersIdFlats(w http.ResponseWriter, r *http.Request, id UserIdPath)
+
+	// (POST /admin/users/{id}/reactivate)
+	PostAdminUsersIdReactivate(w http.ResponseWriter, r *http.Request, id UserIdPath)
+
+	// (POST /admin/users/{id}/reset-password)
+	PostAdminUsersIdResetPassword(w http.ResponseWriter, r *http.Request, id UserIdPath)
+
+	// (POST /admin/users/{id}/role)
+	PostAdminUsersIdRole(w http.ResponseWriter, r *http.Request, id UserIdPath)
+
+	// (GET /admin/users/{id}/sessions)
+	GetAdminUsersIdSessions(w http.ResponseWriter, r *http.Request, id UserIdPath)
+
+	// (POST /admin/users/{id}/suspend)
+	PostAdminUsersIdSuspend(w http.ResponseWriter, r *http.Request, id UserIdPath)
+
+	// (POST /developer/create)
+	PostDeveloperCreate(w http.ResponseWriter, r *http.Request)
+
+	// (GET /developer/{id})
+	GetDeveloperId(w http.ResponseWriter, r *http.Request, id DeveloperId)
+
+	// (GET /dummyLogin)
+	GetDummyLogin(w http.ResponseWriter, r *http.Request, params GetDummyLoginParams)
+
+	// (POST /flat/create)
+	PostFlatCreate(w http.ResponseWriter, r *http.Request, params PostFlatCreateParams)
+
+	// (POST /flat/update)
+	PostFlatUpdate(w http.ResponseWriter, r *http.Request, params PostFlatUpdateParams)
+
+	// (GET /flat/{id}/photos)
+	GetFlatIdPhotos(w http.ResponseWriter, r *http.Request, id FlatId)
+
+	// (POST /flat/{id}/photos)
+	PostFlatIdPhotos(w http.ResponseWriter, r *http.Request, id FlatId)
+
+	// (POST /flat/{id}/photos/order)
+	PostFlatIdPhotosOrder(w http.ResponseWriter, r *http.Request, id FlatId)
+
+	// (DELETE /flat/{id}/photos/{photo_id})
+	DeleteFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request, id FlatId, photoId PhotoId)
+
+	// (GET /flat/{id}/photos/{photo_id})
+	GetFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request, id FlatId, photoId PhotoId)
+
+	// (GET /flat/{id}/photos/{photo_id}/thumbnail)
+	GetFlatIdPhotosPhotoIdThumbnail(w http.ResponseWriter, r *http.Request, id FlatId, photoId PhotoId)
+
+	// (GET /flat/{id}/prices)
+	GetFlatIdPrices(w http.ResponseWriter, r *http.Request, id FlatId)
+
+	// (GET /flats/search)
+	GetFlatsSearch(w http.ResponseWriter, r *http.Request, params GetFlatsSearchParams)
+
+	// (GET /healthz)
+	GetHealthz(w http.ResponseWriter, r *http.Request)
+
+	// (GET /house/bbox)
+	GetHouseBbox(w http.ResponseWriter, r *http.Request, params GetHouseBboxParams)
+
+	// (POST /house/create)
+	PostHouseCreate(w http.ResponseWriter, r *http.Request, params PostHouseCreateParams)
+
+	// (GET /house/nearby)
+	GetHouseNearby(w http.ResponseWriter, r *http.Request, params GetHouseNearbyParams)
+
+	// (POST /house/update)
+	PostHouseUpdate(w http.ResponseWriter, r *http.Request, params PostHouseUpdateParams)
+
+	// (GET /house/{id})
+	GetHouseId(w http.ResponseWriter, r *http.Request, id HouseId, params GetHouseIdParams)
+
+	// (POST /house/{id}/subscribe)
+	PostHouseIdSubscribe(w http.ResponseWriter, r *http.Request, id HouseId)
+
+	// (GET /houses/search)
+	GetHousesSearch(w http.ResponseWriter, r *http.Request, params GetHousesSearchParams)
+
+	// (POST /login)
+	PostLogin(w http.ResponseWriter, r *http.Request)
+
+	// (POST /me/erase)
+	PostMeErase(w http.ResponseWriter, r *http.Request)
+
+	// (GET /me/export)
+	GetMeExport(w http.ResponseWriter, r *http.Request)
+
+	// (GET /metrics)
+	GetMetrics(w http.ResponseWriter, r *http.Request)
+
+	// (POST /password/reset)
+	PostPasswordReset(w http.ResponseWriter, r *http.Request)
+
+	// (GET /readyz)
+	GetReadyz(w http.ResponseWriter, r *http.Request)
+
+	// (POST /register)
+	PostRegister(w http.ResponseWriter, r *http.Request)
+}
+
+// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+
+type Unimplemented struct{}
+
+// (GET /admin/log-level)
+func (_ Unimplemented) GetAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (PUT /admin/log-level)
+func (_ Unimplemented) PutAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /admin/users)
+func (_ Unimplemented) GetAdminUsers(w http.ResponseWriter, r *http.Request, params GetAdminUsersParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /admin/users/{id}/flats)
+func (_ Unimplemented) GetAdminUsersIdFlats(w http.ResponseWriter, r *http.Request, id UserIdPath) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /admin/users/{id}/reactivate)
+func (_ Unimplemented) PostAdminUsersIdReactivate(w http.ResponseWriter, r *http.Request, id UserIdPath) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /admin/users/{id}/reset-password)
+func (_ Unimplemented) PostAdminUsersIdResetPassword(w http.ResponseWriter, r *http.Request, id UserIdPath) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /admin/users/{id}/role)
+func (_ Unimplemented) PostAdminUsersIdRole(w http.ResponseWriter, r *http.Request, id UserIdPath) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /admin/users/{id}/sessions)
+func (_ Unimplemented) GetAdminUsersIdSessions(w http.ResponseWriter, r *http.Request, id UserIdPath) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /admin/users/{id}/suspend)
+func (_ Unimplemented) PostAdminUsersIdSuspend(w http.ResponseWriter, r *http.Request, id UserIdPath) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /developer/create)
+func (_ Unimplemented) PostDeveloperCreate(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /developer/{id})
+func (_ Unimplemented) GetDeveloperId(w http.ResponseWriter, r *http.Request, id DeveloperId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /dummyLogin)
+func (_ Unimplemented) GetDummyLogin(w http.ResponseWriter, r *http.Request, params GetDummyLoginParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /flat/create)
+func (_ Unimplemented) PostFlatCreate(w http.ResponseWriter, r *http.Request, params PostFlatCreateParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /flat/update)
+func (_ Unimplemented) PostFlatUpdate(w http.ResponseWriter, r *http.Request, params PostFlatUpdateParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /flat/{id}/photos)
+func (_ Unimplemented) GetFlatIdPhotos(w http.ResponseWriter, r *http.Request, id FlatId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /flat/{id}/photos)
+func (_ Unimplemented) PostFlatIdPhotos(w http.ResponseWriter, r *http.Request, id FlatId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /flat/{id}/photos/order)
+func (_ Unimplemented) PostFlatIdPhotosOrder(w http.ResponseWriter, r *http.Request, id FlatId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (DELETE /flat/{id}/photos/{photo_id})
+func (_ Unimplemented) DeleteFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request, id FlatId, photoId PhotoId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /flat/{id}/photos/{photo_id})
+func (_ Unimplemented) GetFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request, id FlatId, photoId PhotoId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /flat/{id}/photos/{photo_id}/thumbnail)
+func (_ Unimplemented) GetFlatIdPhotosPhotoIdThumbnail(w http.ResponseWriter, r *http.Request, id FlatId, photoId PhotoId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /flat/{id}/prices)
+func (_ Unimplemented) GetFlatIdPrices(w http.ResponseWriter, r *http.Request, id FlatId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /flats/search)
+func (_ Unimplemented) GetFlatsSearch(w http.ResponseWriter, r *http.Request, params GetFlatsSearchParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /healthz)
+func (_ Unimplemented) GetHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /house/bbox)
+func (_ Unimplemented) GetHouseBbox(w http.ResponseWriter, r *http.Request, params GetHouseBboxParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /house/create)
+func (_ Unimplemented) PostHouseCreate(w http.ResponseWriter, r *http.Request, params PostHouseCreateParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /house/nearby)
+func (_ Unimplemented) GetHouseNearby(w http.ResponseWriter, r *http.Request, params GetHouseNearbyParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /house/update)
+func (_ Unimplemented) PostHouseUpdate(w http.ResponseWriter, r *http.Request, params PostHouseUpdateParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /house/{id})
+func (_ Unimplemented) GetHouseId(w http.ResponseWriter, r *http.Request, id HouseId, params GetHouseIdParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /house/{id}/subscribe)
+func (_ Unimplemented) PostHouseIdSubscribe(w http.ResponseWriter, r *http.Request, id HouseId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /houses/search)
+func (_ Unimplemented) GetHousesSearch(w http.ResponseWriter, r *http.Request, params GetHousesSearchParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /login)
+func (_ Unimplemented) PostLogin(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /me/erase)
+func (_ Unimplemented) PostMeErase(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /me/export)
+func (_ Unimplemented) GetMeExport(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /metrics)
+func (_ Unimplemented) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /password/reset)
+func (_ Unimplemented) PostPasswordReset(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (GET /readyz)
+func (_ Unimplemented) GetReadyz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// (POST /register)
+func (_ Unimplemented) PostRegister(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// GetAdminLogLevel operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAdminLogLevel(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PutAdminLogLevel operation middleware
+func (siw *ServerInterfaceWrapper) PutAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutAdminLogLevel(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetAdminUsers operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminUsers(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAdminUsersParams
+
+	// ------------- Optional query parameter "email" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "email", r.URL.Query(), &params.Email)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "email", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAdminUsers(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetAdminUsersIdFlats operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminUsersIdFlats(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id UserIdPath
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAdminUsersIdFlats(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostAdminUsersIdReactivate operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminUsersIdReactivate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id UserIdPath
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminUsersIdReactivate(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostAdminUsersIdResetPassword operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminUsersIdResetPassword(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id UserIdPath
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminUsersIdResetPassword(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostAdminUsersIdRole operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminUsersIdRole(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id UserIdPath
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminUsersIdRole(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetAdminUsersIdSessions operation middleware
+func (siw *ServerInterfaceWrapper) GetAdminUsersIdSessions(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id UserIdPath
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAdminUsersIdSessions(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostAdminUsersIdSuspend operation middleware
+func (siw *ServerInterfaceWrapper) PostAdminUsersIdSuspend(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id UserIdPath
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostAdminUsersIdSuspend(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostDeveloperCreate operation middleware
+func (siw *ServerInterfaceWrapper) PostDeveloperCreate(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostDeveloperCreate(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDeveloperId operation middleware
+func (siw *ServerInterfaceWrapper) GetDeveloperId(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id DeveloperId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDeveloperId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetDummyLogin operation middleware
+func (siw *ServerInterfaceWrapper) GetDummyLogin(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetDummyLoginParams
+
+	// ------------- Required query parameter "user_type" -------------
+
+	if paramValue := r.URL.Query().Get("user_type"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_type"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_type", r.URL.Query(), &params.UserType)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_type", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetDummyLogin(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostFlatCreate operation middleware
+func (siw *ServerInterfaceWrapper) PostFlatCreate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostFlatCreateParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostFlatCreate(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostFlatUpdate operation middleware
+func (siw *ServerInterfaceWrapper) PostFlatUpdate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostFlatUpdateParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostFlatUpdate(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFlatIdPhotos operation middleware
+func (siw *ServerInterfaceWrapper) GetFlatIdPhotos(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFlatIdPhotos(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostFlatIdPhotos operation middleware
+func (siw *ServerInterfaceWrapper) PostFlatIdPhotos(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostFlatIdPhotos(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostFlatIdPhotosOrder operation middleware
+func (siw *ServerInterfaceWrapper) PostFlatIdPhotosOrder(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostFlatIdPhotosOrder(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteFlatIdPhotosPhotoId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "photo_id" -------------
+	var photoId PhotoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "photo_id", chi.URLParam(r, "photo_id"), &photoId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "photo_id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteFlatIdPhotosPhotoId(w, r, id, photoId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFlatIdPhotosPhotoId operation middleware
+func (siw *ServerInterfaceWrapper) GetFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "photo_id" -------------
+	var photoId PhotoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "photo_id", chi.URLParam(r, "photo_id"), &photoId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "photo_id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFlatIdPhotosPhotoId(w, r, id, photoId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFlatIdPhotosPhotoIdThumbnail operation middleware
+func (siw *ServerInterfaceWrapper) GetFlatIdPhotosPhotoIdThumbnail(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "photo_id" -------------
+	var photoId PhotoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "photo_id", chi.URLParam(r, "photo_id"), &photoId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "photo_id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFlatIdPhotosPhotoIdThumbnail(w, r, id, photoId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFlatIdPrices operation middleware
+func (siw *ServerInterfaceWrapper) GetFlatIdPrices(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id FlatId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFlatIdPrices(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetFlatsSearch operation middleware
+func (siw *ServerInterfaceWrapper) GetFlatsSearch(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFlatsSearchParams
+
+	// ------------- Optional query parameter "price_min" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "price_min", r.URL.Query(), &params.PriceMin)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "price_min", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "price_max" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "price_max", r.URL.Query(), &params.PriceMax)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "price_max", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "rooms_min" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rooms_min", r.URL.Query(), &params.RoomsMin)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rooms_min", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "rooms_max" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rooms_max", r.URL.Query(), &params.RoomsMax)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "rooms_max", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "year_min" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "year_min", r.URL.Query(), &params.YearMin)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "year_min", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "year_max" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "year_max", r.URL.Query(), &params.YearMax)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "year_max", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "floor_min" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "floor_min", r.URL.Query(), &params.FloorMin)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "floor_min", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "floor_max" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "floor_max", r.URL.Query(), &params.FloorMax)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "floor_max", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "area_min" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "area_min", r.URL.Query(), &params.AreaMin)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "area_min", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "area_max" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "area_max", r.URL.Query(), &params.AreaMax)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "area_max", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "has_balcony" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "has_balcony", r.URL.Query(), &params.HasBalcony)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "has_balcony", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "renovation" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "renovation", r.URL.Query(), &params.Renovation)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "renovation", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "amenities" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "amenities", r.URL.Query(), &params.Amenities)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "amenities", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "developer" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "developer", r.URL.Query(), &params.Developer)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "developer", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetFlatsSearch(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetHealthz operation middleware
+func (siw *ServerInterfaceWrapper) GetHealthz(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetHealthz(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetHouseBbox operation middleware
+func (siw *ServerInterfaceWrapper) GetHouseBbox(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetHouseBboxParams
+
+	// ------------- Required query parameter "min_lat" -------------
+
+	if paramValue := r.URL.Query().Get("min_lat"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "min_lat"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "min_lat", r.URL.Query(), &params.MinLat)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "min_lat", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "min_lon" -------------
+
+	if paramValue := r.URL.Query().Get("min_lon"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "min_lon"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "min_lon", r.URL.Query(), &params.MinLon)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "min_lon", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "max_lat" -------------
+
+	if paramValue := r.URL.Query().Get("max_lat"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "max_lat"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "max_lat", r.URL.Query(), &params.MaxLat)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "max_lat", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "max_lon" -------------
+
+	if paramValue := r.URL.Query().Get("max_lon"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "max_lon"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "max_lon", r.URL.Query(), &params.MaxLon)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "max_lon", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetHouseBbox(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostHouseCreate operation middleware
+func (siw *ServerInterfaceWrapper) PostHouseCreate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostHouseCreateParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostHouseCreate(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetHouseNearby operation middleware
+func (siw *ServerInterfaceWrapper) GetHouseNearby(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetHouseNearbyParams
+
+	// ------------- Required query parameter "lat" -------------
+
+	if paramValue := r.URL.Query().Get("lat"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "lat"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "lat", r.URL.Query(), &params.Lat)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "lat", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "lon" -------------
+
+	if paramValue := r.URL.Query().Get("lon"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "lon"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "lon", r.URL.Query(), &params.Lon)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "lon", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "radius" -------------
+
+	if paramValue := r.URL.Query().Get("radius"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "radius"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "radius", r.URL.Query(), &params.Radius)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "radius", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetHouseNearby(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostHouseUpdate operation middleware
+func (siw *ServerInterfaceWrapper) PostHouseUpdate(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostHouseUpdateParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostHouseUpdate(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetHouseId operation middleware
+func (siw *ServerInterfaceWrapper) GetHouseId(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id HouseId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetHouseIdParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-None-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-None-Match", valueList[0], &IfNoneMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-None-Match", Err: err})
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetHouseId(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostHouseIdSubscribe operation middleware
+func (siw *ServerInterfaceWrapper) PostHouseIdSubscribe(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id HouseId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostHouseIdSubscribe(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetHousesSearch operation middleware
+func (siw *ServerInterfaceWrapper) GetHousesSearch(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetHousesSearchParams
+
+	// ------------- Required query parameter "q" -------------
+
+	if paramValue := r.URL.Query().Get("q"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "q"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetHousesSearch(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostLogin operation middleware
+func (siw *ServerInterfaceWrapper) PostLogin(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostLogin(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostMeErase operation middleware
+func (siw *ServerInterfaceWrapper) PostMeErase(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostMeErase(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetMeExport operation middleware
+func (siw *ServerInterfaceWrapper) GetMeExport(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetMeExport(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetMetrics operation middleware
+func (siw *ServerInterfaceWrapper) GetMetrics(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetMetrics(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPasswordReset operation middleware
+func (siw *ServerInterfaceWrapper) PostPasswordReset(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPasswordReset(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetReadyz operation middleware
+func (siw *ServerInterfaceWrapper) GetReadyz(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetReadyz(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostRegister operation middleware
+func (siw *ServerInterfaceWrapper) PostRegister(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostRegister(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{})
+}
+
+type ChiServerOptions struct {
+	BaseURL          string
+	BaseRouter       chi.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = chi.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/log-level", wrapper.GetAdminLogLevel)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/admin/log-level", wrapper.PutAdminLogLevel)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/users", wrapper.GetAdminUsers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/users/{id}/flats", wrapper.GetAdminUsersIdFlats)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/users/{id}/reactivate", wrapper.PostAdminUsersIdReactivate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/users/{id}/reset-password", wrapper.PostAdminUsersIdResetPassword)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/users/{id}/role", wrapper.PostAdminUsersIdRole)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/admin/users/{id}/sessions", wrapper.GetAdminUsersIdSessions)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/admin/users/{id}/suspend", wrapper.PostAdminUsersIdSuspend)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/developer/create", wrapper.PostDeveloperCreate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/developer/{id}", wrapper.GetDeveloperId)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/dummyLogin", wrapper.GetDummyLogin)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/flat/create", wrapper.PostFlatCreate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/flat/update", wrapper.PostFlatUpdate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/flat/{id}/photos", wrapper.GetFlatIdPhotos)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/flat/{id}/photos", wrapper.PostFlatIdPhotos)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/flat/{id}/photos/order", wrapper.PostFlatIdPhotosOrder)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/flat/{id}/photos/{photo_id}", wrapper.DeleteFlatIdPhotosPhotoId)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/flat/{id}/photos/{photo_id}", wrapper.GetFlatIdPhotosPhotoId)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/flat/{id}/photos/{photo_id}/thumbnail", wrapper.GetFlatIdPhotosPhotoIdThumbnail)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/flat/{id}/prices", wrapper.GetFlatIdPrices)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/flats/search", wrapper.GetFlatsSearch)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/healthz", wrapper.GetHealthz)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/house/bbox", wrapper.GetHouseBbox)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/house/create", wrapper.PostHouseCreate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/house/nearby", wrapper.GetHouseNearby)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/house/update", wrapper.PostHouseUpdate)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/house/{id}", wrapper.GetHouseId)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/house/{id}/subscribe", wrapper.PostHouseIdSubscribe)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/houses/search", wrapper.GetHousesSearch)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/login", wrapper.PostLogin)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/me/erase", wrapper.PostMeErase)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/me/export", wrapper.GetMeExport)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/metrics", wrapper.GetMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/password/reset", wrapper.PostPasswordReset)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/readyz", wrapper.GetReadyz)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/register", wrapper.PostRegister)
+	})
+
+	return r
+}
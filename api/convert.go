@@ -0,0 +1,340 @@
+package api
+
+import (
+	"avito_tech/internal/entity"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Constructors below map storage entities onto the response models of the
+// spec, so handlers never serialise entity structs directly.
+
+func NewFlat(f entity.Flat) Flat {
+	flat := Flat{
+		Id:      int(f.ID),
+		HouseId: int(f.HouseID),
+		Price:   int(f.Price),
+		Rooms:   int(f.Rooms),
+		Status:  Status(f.Status),
+		Version: int(f.Version),
+	}
+
+	if f.Number != 0 {
+		number := int(f.Number)
+		flat.Number = &number
+	}
+
+	if f.UserID != uuid.Nil {
+		userID := f.UserID
+		flat.UserId = &userID
+	}
+
+	if f.Floor != nil {
+		floor := int(*f.Floor)
+		flat.Floor = &floor
+	}
+
+	flat.TotalArea = f.TotalArea
+	flat.LivingArea = f.LivingArea
+	flat.CeilingHeight = f.CeilingHeight
+
+	if f.Balcony != "" {
+		balcony := Balcony(f.Balcony)
+		flat.Balcony = &balcony
+	}
+
+	if f.Renovation != "" {
+		renovation := Renovation(f.Renovation)
+		flat.Renovation = &renovation
+	}
+
+	if len(f.Amenities) > 0 {
+		amenities := make(Amenities, 0, len(f.Amenities))
+		for _, a := range f.Amenities {
+			amenities = append(amenities, Amenity(a))
+		}
+		flat.Amenities = &amenities
+	}
+
+	if price, ok := f.PricePerSqm(); ok {
+		pricePerSqm := int(price)
+		flat.PricePerSqm = &pricePerSqm
+	}
+
+	if f.Status == "approved" && len(f.Photos) > 0 {
+		photos := NewPhotos(f.Photos)
+		flat.Photos = &photos
+	}
+
+	return flat
+}
+
+func NewFlats(flats []entity.Flat) []Flat {
+	res := make([]Flat, 0, len(flats))
+	for _, f := range flats {
+		res = append(res, NewFlat(f))
+	}
+
+	return res
+}
+
+func NewPhoto(p entity.Photo) Photo {
+	url := fmt.Sprintf("/flat/%d/photos/%d", p.FlatID, p.ID)
+
+	return Photo{
+		Id:           int(p.ID),
+		Kind:         PhotoKind(p.Kind),
+		Position:     p.Position,
+		Url:          url,
+		ThumbnailUrl: url + "/thumbnail",
+		Width:        p.Width,
+		Height:       p.Height,
+	}
+}
+
+func NewPhotos(photos []entity.Photo) []Photo {
+	res := make([]Photo, 0, len(photos))
+	for _, p := range photos {
+		res = append(res, NewPhoto(p))
+	}
+
+	return res
+}
+
+func NewPricePoints(prices []entity.PricePoint) []PricePoint {
+	res := make([]PricePoint, 0, len(prices))
+	for _, p := range prices {
+		res = append(res, PricePoint{Price: int(p.Price), ChangedAt: p.ChangedAt})
+	}
+
+	return res
+}
+
+func NewHouse(h entity.House) House {
+	house := House{
+		Id:        int(h.ID),
+		Address:   h.Address,
+		Year:      int(h.Year),
+		CreatedAt: timePtr(h.CreatedFl),
+		UpdateAt:  timePtr(h.UpdateFl),
+		Version:   int(h.Version),
+	}
+
+	if h.Developer != "" {
+		developer := h.Developer
+		house.Developer = &developer
+	}
+
+	if h.Postcode != "" {
+		postcode := h.Postcode
+		house.Postcode = &postcode
+	}
+
+	if h.Lat != nil && h.Lon != nil {
+		lat, lon := *h.Lat, *h.Lon
+		house.Lat, house.Lon = &lat, &lon
+	}
+
+	if h.DeveloperID != nil {
+		developerID := int(*h.DeveloperID)
+		house.DeveloperId = &developerID
+	}
+
+	if h.Floors != nil {
+		floors := int(*h.Floors)
+		house.Floors = &floors
+	}
+
+	if h.Material != "" {
+		material := Material(h.Material)
+		house.Material = &material
+	}
+
+	if h.Parking != "" {
+		parking := Parking(h.Parking)
+		house.Parking = &parking
+	}
+
+	if h.Elevators != nil {
+		elevators := int(*h.Elevators)
+		house.Elevators = &elevators
+	}
+
+	if h.ConstructionStatus != "" {
+		status := ConstructionStatus(h.ConstructionStatus)
+		house.ConstructionStatus = &status
+	}
+
+	if h.CompletionQuarter != "" {
+		quarter := h.CompletionQuarter
+		house.CompletionQuarter = &quarter
+	}
+
+	return house
+}
+
+func NewHouses(houses []entity.House) []House {
+	res := make([]House, 0, len(houses))
+	for _, h := range houses {
+		res = append(res, NewHouse(h))
+	}
+
+	return res
+}
+
+func NewHouseCreated(h entity.House, duplicates []entity.House) HouseCreated {
+	house := NewHouse(h)
+
+	created := HouseCreated{
+		Id:                 house.Id,
+		Address:            house.Address,
+		Postcode:           house.Postcode,
+		Year:               house.Year,
+		Developer:          house.Developer,
+		Lat:                house.Lat,
+		Lon:                house.Lon,
+		DeveloperId:        house.DeveloperId,
+		Floors:             house.Floors,
+		Material:           house.Material,
+		Parking:            house.Parking,
+		Elevators:          house.Elevators,
+		ConstructionStatus: house.ConstructionStatus,
+		CompletionQuarter:  house.CompletionQuarter,
+		CreatedAt:          house.CreatedAt,
+		UpdateAt:           house.UpdateAt,
+		Version:            house.Version,
+	}
+
+	if len(duplicates) > 0 {
+		possible := NewHouses(duplicates)
+		created.PossibleDuplicates = &possible
+	}
+
+	return created
+}
+
+func NewNearbyHouses(houses []entity.NearbyHouse) []NearbyHouse {
+	res := make([]NearbyHouse, 0, len(houses))
+	for _, h := range houses {
+		house := NewHouse(h.House)
+
+		res = append(res, NearbyHouse{
+			Id:                 house.Id,
+			Address:            house.Address,
+			Postcode:           house.Postcode,
+			Year:               house.Year,
+			Developer:          house.Developer,
+			Lat:                house.Lat,
+			Lon:                house.Lon,
+			DeveloperId:        house.DeveloperId,
+			Floors:             house.Floors,
+			Material:           house.Material,
+			Parking:            house.Parking,
+			Elevators:          house.Elevators,
+			ConstructionStatus: house.ConstructionStatus,
+			CompletionQuarter:  house.CompletionQuarter,
+			CreatedAt:          house.CreatedAt,
+			UpdateAt:           house.UpdateAt,
+			Version:            house.Version,
+			Distance:           h.Distance,
+		})
+	}
+
+	return res
+}
+
+func NewUser(u entity.User) User {
+	resetRequired := u.ResetRequired
+
+	return User{
+		Id:            u.ID,
+		Email:         Email(u.Email),
+		UserType:      Role(u.UserType),
+		Status:        UserStatus(u.Status),
+		ResetRequired: &resetRequired,
+		CreatedAt:     timePtr(u.CreatedAt),
+	}
+}
+
+func NewUsers(users []entity.User) []User {
+	res := make([]User, 0, len(users))
+	for _, u := range users {
+		res = append(res, NewUser(u))
+	}
+
+	return res
+}
+
+func NewSession(s entity.Session) Session {
+	return Session{
+		Id:        s.ID,
+		UserId:    s.UserID,
+		CreatedAt: s.CreatedAt,
+		ExpiresAt: s.ExpiresAt,
+		RevokedAt: s.RevokedAt,
+	}
+}
+
+func NewSessions(sessions []entity.Session) []Session {
+	res := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, NewSession(s))
+	}
+
+	return res
+}
+
+func NewUserExport(e entity.UserExport) UserExport {
+	export := UserExport{
+		User:          NewUser(e.User),
+		Flats:         NewFlats(e.Flats),
+		Subscriptions: make([]Subscription, 0, len(e.Subscriptions)),
+		Notifications: make([]Notification, 0, len(e.Notifications)),
+		ExportedAt:    e.ExportedAt,
+	}
+
+	for _, s := range e.Subscriptions {
+		export.Subscriptions = append(export.Subscriptions, Subscription{
+			HouseId:   s.HouseID,
+			Email:     Email(s.Email),
+			CreatedAt: timePtr(s.CreatedAt),
+		})
+	}
+
+	for _, n := range e.Notifications {
+		export.Notifications = append(export.Notifications, Notification{
+			Id:        int(n.ID),
+			HouseId:   int(n.HouseID),
+			Email:     n.Email,
+			Message:   n.Message,
+			Status:    NotificationStatus(n.Status),
+			CreatedAt: timePtr(n.CreatedAt),
+		})
+	}
+
+	return export
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func NewDeveloper(d entity.Developer) DeveloperCompany {
+	developer := DeveloperCompany{
+		Id:        int(d.ID),
+		Name:      d.Name,
+		CreatedAt: timePtr(d.CreatedAt),
+	}
+
+	if d.Website != "" {
+		website := d.Website
+		developer.Website = &website
+	}
+
+	return developer
+}
@@ -1,19 +1,44 @@
 package main
 
 import (
+	"avito_tech/api"
 	"avito_tech/internal/config"
-	"avito_tech/internal/http_server/handlers/auth"
-	"avito_tech/internal/http_server/handlers/flat"
-	"avito_tech/internal/http_server/handlers/house"
-	mdr "avito_tech/internal/http_server/middleware/auth"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/handlers/health"
+	"avito_tech/internal/http_server/handlers/photo"
+	"avito_tech/internal/http_server/middleware/logger"
+	"avito_tech/internal/http_server/middleware/metrics"
+	ratelimitmdr "avito_tech/internal/http_server/middleware/ratelimit"
+	"avito_tech/internal/http_server/middleware/recoverer"
+	tracemdr "avito_tech/internal/http_server/middleware/tracing"
+	"avito_tech/internal/http_server/middleware/validate"
 	send "avito_tech/internal/http_server/sender"
+	"avito_tech/internal/http_server/server"
+	"avito_tech/internal/jobs/erasure"
+	"avito_tech/internal/jobs/idempotency"
+	"avito_tech/internal/jobs/notify"
+	"avito_tech/internal/lib/blob"
 	"avito_tech/internal/lib/logger/slg"
+	"avito_tech/internal/lib/ratelimit"
+	"avito_tech/internal/lib/tracing"
+	"avito_tech/internal/lib/worker"
+	stg "avito_tech/internal/storage"
+	"avito_tech/internal/storage/instrumented"
 	"avito_tech/internal/storage/postgres"
+	"context"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/bcrypt"
+	stdlog "log"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 )
 
 func main() {
@@ -22,30 +47,157 @@ func main() {
 	//os.Setenv("CONFIG_PATH", "../../config/local.yaml")
 	cfg := config.MustLoad()
 
-	log := slg.SetupLogger(cfg.Env)
+	log, level, err := slg.SetupLogger(cfg.Env, slg.Options{
+		Level:            cfg.Log.Level,
+		Format:           cfg.Log.Format,
+		Output:           cfg.Log.Output,
+		MaxSizeMB:        cfg.Log.MaxSizeMB,
+		MaxBackups:       cfg.Log.MaxBackups,
+		MaxAgeDays:       cfg.Log.MaxAgeDays,
+		SampleFirst:      cfg.Log.SampleFirst,
+		SampleThereafter: cfg.Log.SampleThereafter,
+		SampleTick:       cfg.Log.SampleTick,
+	})
+	if err != nil {
+		stdlog.Fatalf("failed to init logger: %s", err)
+	}
+	slog.SetDefault(log)
 
-	storage, err := postgres.New(cfg.StoragePath)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.ServiceName, cfg.Exporter, cfg.OTLPEndpoint, cfg.SampleRatio)
+	if err != nil {
+		log.Error("failed to init tracing", slg.Err(err))
+		os.Exit(1)
+	}
+
+	pg, err := postgres.New(cfg.DSN(), cfg.QueryTimeout, cfg.ConnectTimeout)
 	if err != nil {
 		log.Error("failed to init storage", slg.Err(err))
 		os.Exit(1)
 	}
 
-	sender := send.New()
+	prometheus.MustRegister(instrumented.NewPoolCollector(pg.Stat))
+	storage := instrumented.New(pg)
+
+	if cfg.Admin.Email != "" {
+		if err := bootstrapAdmin(context.Background(), log, storage, cfg.Admin); err != nil {
+			log.Error("failed to create admin", slg.Err(err))
+			os.Exit(1)
+		}
+	}
+
+	doc, err := api.Load()
+	if err != nil {
+		log.Error("failed to load api spec", slg.Err(err))
+		os.Exit(1)
+	}
+
+	validator, err := validate.New(log, doc)
+	if err != nil {
+		log.Error("failed to init request validator", slg.Err(err))
+		os.Exit(1)
+	}
+
+	sender := send.New(log)
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
-	router.Use(middleware.Recoverer)
+	router.Use(tracemdr.New())
+	router.Use(logger.New(log))
+	router.Use(metrics.New())
+	router.Use(recoverer.New(log))
+
+	workers := worker.New()
+
+	var (
+		limiter ratelimit.Limiter = ratelimit.NewMemory()
+		shared  *ratelimit.Shared
+	)
+	if cfg.RateLimit.Backend == "postgres" {
+		shared = ratelimit.NewShared(storage)
+		limiter = shared
+	}
+
+	anonymousPolicy := ratelimit.NewPolicy(rateRule(cfg.Anonymous))
+	readPolicy := ratelimit.NewPolicy(rateRule(cfg.Read))
+	writePolicy := ratelimit.NewPolicy(rateRule(cfg.Write))
+
+	blobs, err := newBlobStore(cfg.Blob)
+	if err != nil {
+		log.Error("failed to init blob store", slg.Err(err))
+		os.Exit(1)
+	}
+
+	server.New(log, storage, blobs, sender, workers, level, server.Limits{
+		Anonymous: ratelimitmdr.New(log, limiter, anonymousPolicy, "anonymous"),
+		Read:      ratelimitmdr.New(log, limiter, readPolicy, "read"),
+		Write:     ratelimitmdr.New(log, limiter, writePolicy, "write"),
+	}, server.Settings{
+		IdempotencyTTL:   cfg.IdempotencyTTL,
+		IdempotencyLease: cfg.IdempotencyLease,
+		PriceDropPercent: cfg.PriceDropPercent,
+		Photos: photo.Settings{
+			MaxSize:       int64(cfg.MaxUploadMB) << 20,
+			MaxPerFlat:    cfg.MaxPerFlat,
+			MaxPixels:     cfg.MaxMegapixels * 1_000_000,
+			ThumbnailSize: cfg.ThumbnailSize,
+		},
+		ReadyTimeout: cfg.ReadyTimeout,
+		ReadyChecks: []health.Check{
+			{Name: "postgres", Fn: storage.Ping},
+			{Name: "migrations", Fn: storage.CheckMigrations},
+		},
+		Validate: validator,
+	}).Handler(router)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	router.Get("/dummyLogin", auth.DummyLogin(log, storage))
-	router.Post("/login", auth.Login(log, storage))
-	router.Post("/register", auth.Register(log, storage))
+	reloader := config.NewReloader(log, os.Getenv("CONFIG_PATH"), cfg)
+	reloader.OnReload(func(_, cfg *config.Config) {
+		anonymousPolicy.Set(rateRule(cfg.Anonymous))
+		readPolicy.Set(rateRule(cfg.Read))
+		writePolicy.Set(rateRule(cfg.Write))
+	})
+	// A level set through PUT /admin/log-level stays until log.level itself
+	// is changed in the config, not on every reload.
+	reloader.OnReload(func(prev, cfg *config.Config) {
+		if cfg.Log.Level == "" || strings.EqualFold(cfg.Log.Level, prev.Log.Level) {
+			return
+		}
 
-	router.Post("/house/create", mdr.JWTAuth(log, mdr.RequireModerator(log, house.Create(log, storage))))
-	router.Get("/house/{id}", mdr.JWTAuth(log, house.GetAllFlats(log, storage)))
-	router.Post("/house/{id}/subscribe", house.Subscribe(log, storage))
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(cfg.Log.Level)); err == nil {
+			level.Set(l)
+		}
+	})
 
-	router.Post("/flat/create", mdr.JWTAuth(log, flat.Create(log, storage, sender)))
-	router.Post("/flat/update", mdr.JWTAuth(log, mdr.RequireModerator(log, flat.Update(log, storage))))
+	var jobs sync.WaitGroup
+	jobs.Add(4)
+	if shared != nil {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			shared.Run(ctx, func(err error) {
+				log.Error("failed to purge rate limits", slg.Err(err))
+			})
+		}()
+	}
+	go func() {
+		defer jobs.Done()
+		reloader.Run(ctx)
+	}()
+	go func() {
+		defer jobs.Done()
+		erasure.New(log, storage, cfg.ErasureInterval).Run(ctx)
+	}()
+	go func() {
+		defer jobs.Done()
+		notify.New(log, storage, sender, cfg.NotifyInterval, cfg.NotifyClaimTimeout).Run(ctx)
+	}()
+	go func() {
+		defer jobs.Done()
+		idempotency.New(log, storage, cfg.IdempotencyTTL, cfg.IdempotencyInterval).Run(ctx)
+	}()
 
 	log.Info("starting server", slog.String("address", cfg.Address))
 
@@ -57,9 +209,73 @@ func main() {
 		IdleTimeout:  cfg.IdleTimeout,
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server", slg.Err(err))
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+
+	log.Info("shutting down", slog.Duration("timeout", cfg.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Order matters: stop taking requests first, then let the notifications
+	// they spawned finish or persist, and only then close the pool.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to drain requests", slg.Err(err))
+	}
+
+	if err := workers.Shutdown(shutdownCtx); err != nil {
+		log.Error("background tasks cancelled, unsent notifications left pending", slg.Err(err))
+	}
+
+	jobs.Wait()
+	pg.Close()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("failed to flush spans", slg.Err(err))
+	}
+
+	log.Info("server stopped")
+}
+
+func rateRule(rule config.RateRule) ratelimit.Rule {
+	return ratelimit.Rule{Rate: rule.Rate, Burst: rule.Burst}
+}
+
+// bootstrapAdmin creates the configured admin account, admins cannot
+// register themselves. A user already holding the email is kept, but one
+// that is not an admin is a config mistake worth a warning.
+func bootstrapAdmin(ctx context.Context, log *slog.Logger, storage *instrumented.Storage, cfg config.Admin) error {
+	password, err := bcrypt.GenerateFromPassword([]byte(cfg.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	created, err := storage.EnsureAdmin(ctx, entity.User{Email: cfg.Email, Password: string(password)})
+	if errors.Is(err, stg.ErrConflict) {
+		log.Warn("admin.email belongs to a user that is not an admin", slog.String("email", cfg.Email))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if created {
+		log.Info("admin created", slog.String("email", cfg.Email))
+	}
+
+	return nil
+}
+
+func newBlobStore(cfg config.Blob) (blob.Store, error) {
+	if cfg.Backend == "s3local" {
+		return blob.NewS3(blob.NewLocalS3(), cfg.Bucket), nil
 	}
 
-	log.Error("server stopped")
+	return blob.NewFS(cfg.Dir)
 }
@@ -1,22 +1,138 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
+// Every field can be overridden by the environment variable in its env tag,
+// prefixed with the env-prefix of its section: http_server.address is
+// HTTP_SERVER_ADDRESS. Secrets can also be read from a file named by the
+// same variable with a _FILE suffix, e.g. STORAGE_PASSWORD_FILE.
 type Config struct {
-	Env         string `yaml:"env" env-required:"true"`
-	StoragePath string `yaml:"storage_path" env-required:"true"`
-	HTTPServer  `yaml:"http_server"`
+	Env         string `yaml:"env" env:"ENV" env-required:"true"`
+	StoragePath string `yaml:"storage_path" env:"STORAGE_PATH" env-required:"true"`
+	Storage     `yaml:"storage" env-prefix:"STORAGE_"`
+	HTTPServer  `yaml:"http_server" env-prefix:"HTTP_SERVER_"`
+	Jobs        `yaml:"jobs" env-prefix:"JOBS_"`
+	Notify      `yaml:"notify" env-prefix:"NOTIFY_"`
+	Tracing     `yaml:"tracing" env-prefix:"TRACING_"`
+	Log         `yaml:"log" env-prefix:"LOG_"`
+	RateLimit   `yaml:"rate_limit" env-prefix:"RATE_LIMIT_"`
+	Blob        `yaml:"blob" env-prefix:"BLOB_"`
+	Photos      `yaml:"photos" env-prefix:"PHOTOS_"`
+	// Admin is not embedded: its Password would clash with Storage's.
+	Admin Admin `yaml:"admin" env-prefix:"ADMIN_"`
+}
+
+type Storage struct {
+	// Password, if set, replaces the password of StoragePath, so that the
+	// DSN in the config file does not have to hold it.
+	Password       string        `yaml:"password" env:"PASSWORD"`
+	QueryTimeout   time.Duration `yaml:"query_timeout" env:"QUERY_TIMEOUT" env-default:"3s"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"CONNECT_TIMEOUT" env-default:"30s"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" default:"60s"`
+	Address     string        `yaml:"address" env:"ADDRESS" env-default:"localhost:8080"`
+	Timeout     time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"4s"`
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"60s"`
+	// ShutdownTimeout bounds how long in-flight requests and background
+	// notifications get to finish after SIGTERM.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"10s"`
+	// ReadyTimeout bounds the dependency checks of /readyz.
+	ReadyTimeout time.Duration `yaml:"ready_timeout" env:"READY_TIMEOUT" env-default:"2s"`
+	// IdempotencyTTL is how long responses to an Idempotency-Key are
+	// replayed.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	// IdempotencyLease is how long a request holds its Idempotency-Key
+	// before a retry may take it over. It has to outlast Timeout.
+	IdempotencyLease time.Duration `yaml:"idempotency_lease" env:"IDEMPOTENCY_LEASE" env-default:"1m"`
+}
+
+type Jobs struct {
+	ErasureInterval time.Duration `yaml:"erasure_interval" env:"ERASURE_INTERVAL" env-default:"1m"`
+	NotifyInterval  time.Duration `yaml:"notify_interval" env:"NOTIFY_INTERVAL" env-default:"1m"`
+	// NotifyClaimTimeout is how long a replica has to send a claimed
+	// notification before another one takes it over.
+	NotifyClaimTimeout time.Duration `yaml:"notify_claim_timeout" env:"NOTIFY_CLAIM_TIMEOUT" env-default:"5m"`
+	// IdempotencyInterval is how often expired idempotency keys are purged.
+	IdempotencyInterval time.Duration `yaml:"idempotency_interval" env:"IDEMPOTENCY_INTERVAL" env-default:"1h"`
+}
+
+type Notify struct {
+	// PriceDropPercent is how much, in percent, the price of an approved
+	// flat has to drop for the subscribers of its house to be notified.
+	PriceDropPercent float64 `yaml:"price_drop_percent" env:"PRICE_DROP_PERCENT" env-default:"5"`
+}
+
+type Tracing struct {
+	// Exporter is none, stdout (spans printed to the console) or otlp.
+	Exporter     string  `yaml:"exporter" env:"EXPORTER" env-default:"none"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTLP_ENDPOINT" env-default:"localhost:4318"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"`
+	ServiceName  string  `yaml:"service_name" env:"SERVICE_NAME" env-default:"avito_tech"`
+}
+
+// Log overrides the logger defaults implied by Env, see slg.Options.
+type Log struct {
+	Level            string        `yaml:"level" env:"LEVEL" reload:"true"`
+	Format           string        `yaml:"format" env:"FORMAT"`
+	Output           string        `yaml:"output" env:"OUTPUT" env-default:"stdout"`
+	MaxSizeMB        int           `yaml:"max_size_mb" env:"MAX_SIZE_MB" env-default:"100"`
+	MaxBackups       int           `yaml:"max_backups" env:"MAX_BACKUPS" env-default:"3"`
+	MaxAgeDays       int           `yaml:"max_age_days" env:"MAX_AGE_DAYS" env-default:"7"`
+	SampleFirst      int           `yaml:"sample_first" env:"SAMPLE_FIRST" env-default:"0"`
+	SampleThereafter int           `yaml:"sample_thereafter" env:"SAMPLE_THEREAFTER" env-default:"0"`
+	SampleTick       time.Duration `yaml:"sample_tick" env:"SAMPLE_TICK" env-default:"1s"`
+}
+
+type RateLimit struct {
+	// Backend is memory (each replica limits on its own) or postgres
+	// (buckets shared by all replicas).
+	Backend   string   `yaml:"backend" env:"BACKEND" env-default:"memory"`
+	Anonymous RateRule `yaml:"anonymous" env-prefix:"ANONYMOUS_"`
+	Read      RateRule `yaml:"read" env-prefix:"READ_"`
+	Write     RateRule `yaml:"write" env-prefix:"WRITE_"`
+}
+
+// RateRule is a token bucket: Rate requests per second on average with
+// bursts of up to Burst. A zero Rate disables the limit.
+type RateRule struct {
+	Rate  float64 `yaml:"rate" env:"RATE" reload:"true"`
+	Burst int     `yaml:"burst" env:"BURST" reload:"true"`
+}
+
+// Blob is where photos are stored.
+type Blob struct {
+	// Backend is fs (files under Dir) or s3local, an in-memory stand-in for
+	// an S3-compatible storage that loses its objects on restart.
+	Backend string `yaml:"backend" env:"BACKEND" env-default:"fs"`
+	Dir     string `yaml:"dir" env:"DIR" env-default:"data/blobs"`
+	Bucket  string `yaml:"bucket" env:"BUCKET" env-default:"avito-tech"`
+}
+
+type Photos struct {
+	MaxUploadMB   int `yaml:"max_upload_mb" env:"MAX_UPLOAD_MB" env-default:"10"`
+	MaxPerFlat    int `yaml:"max_per_flat" env:"MAX_PER_FLAT" env-default:"30"`
+	MaxMegapixels int `yaml:"max_megapixels" env:"MAX_MEGAPIXELS" env-default:"40"`
+	// ThumbnailSize is the longest side of a thumbnail in pixels.
+	ThumbnailSize int `yaml:"thumbnail_size" env:"THUMBNAIL_SIZE" env-default:"320"`
+}
+
+// Admin is the account created at startup if no user has Email yet, the
+// only way to get the first admin. Password is its initial password, an
+// existing account is left as it is.
+type Admin struct {
+	Email    string `yaml:"email" env:"EMAIL"`
+	Password string `yaml:"password" env:"PASSWORD"`
 }
 
 func MustLoad() *Config {
@@ -25,14 +141,149 @@ func MustLoad() *Config {
 		log.Fatal("CONFIG_PATH is not set")
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exists: %s", configPath)
+	cfg, err := Load(configPath)
+	if err != nil {
+		log.Fatalf("cannot read config: %s", err)
+	}
+
+	return cfg
+}
+
+// Load reads the file at path, applies environment overrides and validates
+// the result.
+func Load(path string) (*Config, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("config file does not exists: %s", path)
+	}
+
+	if err := readSecretFiles(&Config{}); err != nil {
+		return nil, err
 	}
 
 	var cfg Config
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		log.Fatalf("cannot read config: %s", err)
+	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate reports every semantic problem of the config at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	positive := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"storage.connect_timeout", c.ConnectTimeout},
+		{"http_server.timeout", c.Timeout},
+		{"http_server.idle_timeout", c.IdleTimeout},
+		{"http_server.shutdown_timeout", c.ShutdownTimeout},
+		{"http_server.ready_timeout", c.ReadyTimeout},
+		{"http_server.idempotency_ttl", c.IdempotencyTTL},
+		{"http_server.idempotency_lease", c.IdempotencyLease},
+		{"jobs.erasure_interval", c.ErasureInterval},
+		{"jobs.notify_interval", c.NotifyInterval},
+		{"jobs.notify_claim_timeout", c.NotifyClaimTimeout},
+		{"jobs.idempotency_interval", c.IdempotencyInterval},
+	}
+	for _, p := range positive {
+		check(p.value > 0, "%s: must be positive, got %s", p.name, p.value)
+	}
+
+	check(c.QueryTimeout >= 0, "storage.query_timeout: must not be negative, got %s", c.QueryTimeout)
+	check(c.IdempotencyLease > c.Timeout, "http_server.idempotency_lease: must be longer than http_server.timeout, got %s", c.IdempotencyLease)
+
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		errs = append(errs, fmt.Errorf("http_server.address: %w", err))
+	}
+
+	if strings.Contains(c.StoragePath, "://") {
+		_, err := url.Parse(c.StoragePath)
+		check(err == nil, "storage_path: invalid url")
+	}
+
+	check(c.PriceDropPercent >= 0 && c.PriceDropPercent < 100, "notify.price_drop_percent: must be in [0, 100), got %v", c.PriceDropPercent)
+
+	check(oneOf(c.Exporter, "none", "stdout", "otlp"), "tracing.exporter: must be none, stdout or otlp, got %q", c.Exporter)
+	check(c.SampleRatio >= 0 && c.SampleRatio <= 1, "tracing.sample_ratio: must be in [0, 1], got %v", c.SampleRatio)
+
+	check(c.Log.Level == "" || oneOf(strings.ToLower(c.Log.Level), "debug", "info", "warn", "error"),
+		"log.level: must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Format == "" || oneOf(c.Format, "text", "json"), "log.format: must be text or json, got %q", c.Format)
+	check(c.MaxSizeMB > 0, "log.max_size_mb: must be positive, got %d", c.MaxSizeMB)
+	check(c.SampleFirst >= 0, "log.sample_first: must not be negative, got %d", c.SampleFirst)
+	check(c.SampleThereafter >= 0, "log.sample_thereafter: must not be negative, got %d", c.SampleThereafter)
+
+	check(oneOf(c.RateLimit.Backend, "memory", "postgres"), "rate_limit.backend: must be memory or postgres, got %q", c.RateLimit.Backend)
+	rules := []struct {
+		name string
+		rule RateRule
+	}{
+		{"anonymous", c.Anonymous},
+		{"read", c.Read},
+		{"write", c.Write},
+	}
+	for _, r := range rules {
+		check(r.rule.Rate >= 0, "rate_limit.%s.rate: must not be negative, got %v", r.name, r.rule.Rate)
+		check(r.rule.Rate == 0 || r.rule.Burst >= 1, "rate_limit.%s.burst: must be at least 1, got %d", r.name, r.rule.Burst)
+	}
+
+	check(oneOf(c.Blob.Backend, "fs", "s3local"), "blob.backend: must be fs or s3local, got %q", c.Blob.Backend)
+	check(c.Blob.Backend != "fs" || c.Dir != "", "blob.dir: must be set for the fs backend")
+	check(c.Blob.Backend != "s3local" || c.Bucket != "", "blob.bucket: must be set for the s3local backend")
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"photos.max_upload_mb", c.MaxUploadMB},
+		{"photos.max_per_flat", c.MaxPerFlat},
+		{"photos.max_megapixels", c.MaxMegapixels},
+		{"photos.thumbnail_size", c.ThumbnailSize},
+	}
+	for _, n := range counts {
+		check(n.value > 0, "%s: must be positive, got %d", n.name, n.value)
+	}
+
+	check(c.Admin.Email == "" || c.Admin.Password != "", "admin.password: must be set with admin.email")
+
+	return errors.Join(errs...)
+}
+
+// DSN returns StoragePath with Storage.Password applied.
+func (c *Config) DSN() string {
+	if c.Password == "" {
+		return c.StoragePath
+	}
+
+	if u, err := url.Parse(c.StoragePath); err == nil && u.Scheme != "" {
+		u.User = url.UserPassword(u.User.Username(), c.Password)
+		return u.String()
+	}
+
+	// Later keywords win in the key=value format.
+	password := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(c.Password)
+	return c.StoragePath + " password='" + password + "'"
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
 	}
 
-	return &cfg
+	return false
 }
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const minimal = `
+env: prod
+storage_path: "user=user host=db dbname=avito_tech"
+http_server:
+  address: "0.0.0.0:8082"
+log:
+  level: info
+`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := Load(writeConfig(t, minimal))
+		require.NoError(t, err)
+
+		require.Equal(t, time.Second*3, cfg.QueryTimeout)
+		require.Equal(t, time.Second*10, cfg.ShutdownTimeout)
+		require.Equal(t, "none", cfg.Exporter)
+		require.Equal(t, "fs", cfg.Blob.Backend)
+		require.Equal(t, 320, cfg.ThumbnailSize)
+		require.Equal(t, time.Minute, cfg.IdempotencyLease)
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		t.Setenv("HTTP_SERVER_ADDRESS", "localhost:9090")
+		t.Setenv("STORAGE_QUERY_TIMEOUT", "5s")
+		t.Setenv("LOG_LEVEL", "debug")
+
+		cfg, err := Load(writeConfig(t, minimal))
+		require.NoError(t, err)
+
+		require.Equal(t, "localhost:9090", cfg.Address)
+		require.Equal(t, time.Second*5, cfg.QueryTimeout)
+		require.Equal(t, "debug", cfg.Log.Level)
+	})
+
+	t.Run("secret from file", func(t *testing.T) {
+		secret := filepath.Join(t.TempDir(), "db_password")
+		require.NoError(t, os.WriteFile(secret, []byte("s3cr'et\n"), 0o600))
+
+		t.Setenv("STORAGE_PASSWORD_FILE", secret)
+		defer os.Unsetenv("STORAGE_PASSWORD")
+
+		cfg, err := Load(writeConfig(t, minimal))
+		require.NoError(t, err)
+
+		require.Equal(t, "s3cr'et", cfg.Password)
+		require.Equal(t, `user=user host=db dbname=avito_tech password='s3cr\'et'`, cfg.DSN())
+	})
+
+	t.Run("secret twice", func(t *testing.T) {
+		t.Setenv("STORAGE_PASSWORD", "password")
+		t.Setenv("STORAGE_PASSWORD_FILE", "/run/secrets/db_password")
+
+		_, err := Load(writeConfig(t, minimal))
+		require.ErrorContains(t, err, "both STORAGE_PASSWORD and STORAGE_PASSWORD_FILE are set")
+	})
+
+	t.Run("all errors at once", func(t *testing.T) {
+		t.Setenv("HTTP_SERVER_ADDRESS", "8082")
+		t.Setenv("TRACING_EXPORTER", "jaeger")
+		t.Setenv("JOBS_NOTIFY_INTERVAL", "0s")
+		t.Setenv("BLOB_BACKEND", "gcs")
+		t.Setenv("PHOTOS_MAX_PER_FLAT", "0")
+		t.Setenv("ADMIN_EMAIL", "admin@example.com")
+		t.Setenv("HTTP_SERVER_IDEMPOTENCY_LEASE", "2s")
+
+		_, err := Load(writeConfig(t, minimal))
+		require.ErrorContains(t, err, "http_server.address")
+		require.ErrorContains(t, err, "tracing.exporter")
+		require.ErrorContains(t, err, "jobs.notify_interval")
+		require.ErrorContains(t, err, "blob.backend")
+		require.ErrorContains(t, err, "photos.max_per_flat")
+		require.ErrorContains(t, err, "admin.password")
+		require.ErrorContains(t, err, "http_server.idempotency_lease")
+	})
+}
+
+func TestDSN(t *testing.T) {
+	cfg := Config{StoragePath: "postgres://user:old@db:5432/avito_tech", Storage: Storage{Password: "new"}}
+	require.Equal(t, "postgres://user:new@db:5432/avito_tech", cfg.DSN())
+
+	cfg.Password = ""
+	require.Equal(t, cfg.StoragePath, cfg.DSN())
+}
+
+func TestRestartRequired(t *testing.T) {
+	old, err := Load(writeConfig(t, minimal))
+	require.NoError(t, err)
+
+	changed := *old
+	changed.Log.Level = "error"
+	require.Empty(t, restartRequired(old, &changed))
+
+	changed.Address = "0.0.0.0:9090"
+	changed.QueryTimeout = time.Second
+	require.Equal(t, []string{"storage.query_timeout", "http_server.address"}, restartRequired(old, &changed))
+}
+
+func TestReload(t *testing.T) {
+	path := writeConfig(t, minimal)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	reloader := NewReloader(nilLogger(), path, cfg)
+
+	var levels []string
+	reloader.OnReload(func(prev, cfg *Config) {
+		levels = append(levels, prev.Log.Level+"->"+cfg.Log.Level)
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte(minimal+"  format: xml\n"), 0o600))
+	reloader.Reload()
+	require.Empty(t, levels, "invalid config must be ignored")
+
+	require.NoError(t, os.WriteFile(path, []byte(minimal[:len(minimal)-len("info\n")]+"warn\n"), 0o600))
+	reloader.Reload()
+	require.Equal(t, []string{"info->warn"}, levels)
+
+	reloader.Reload()
+	require.Equal(t, []string{"info->warn", "warn->warn"}, levels, "the previous config is the last applied one")
+}
+
+func nilLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
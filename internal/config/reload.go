@@ -0,0 +1,146 @@
+package config
+
+import (
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// debounce merges the bursts of events editors produce on a single save.
+const debounce = time.Millisecond * 200
+
+// Reloader re-reads the config on SIGHUP and when the file changes. Only
+// fields tagged reload:"true" take effect; changes to other fields are
+// logged as needing a restart.
+type Reloader struct {
+	path string
+	log  *slog.Logger
+
+	mu       sync.Mutex
+	current  *Config
+	handlers []func(prev, cfg *Config)
+}
+
+func NewReloader(log *slog.Logger, path string, current *Config) *Reloader {
+	return &Reloader{
+		path:    path,
+		log:     log.With(slog.String("fn", "config.Reloader")),
+		current: current,
+	}
+}
+
+// OnReload registers fn to be called with the config in effect so far and
+// every new valid config. fn must only apply the fields that are safe to
+// change at runtime; comparing with prev lets it leave alone a setting that
+// was changed at runtime by other means and not in the file.
+func (r *Reloader) OnReload(fn func(prev, cfg *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers = append(r.handlers, fn)
+}
+
+// Run watches for SIGHUP and file changes until ctx is done.
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.log.Error("failed to watch config, reload on SIGHUP only", slg.Err(err))
+	} else {
+		defer watcher.Close()
+
+		// The directory is watched, since editors and k8s configmaps replace
+		// the file rather than write to it.
+		if err = watcher.Add(filepath.Dir(r.path)); err != nil {
+			r.log.Error("failed to watch config, reload on SIGHUP only", slg.Err(err))
+		} else {
+			events = watcher.Events
+		}
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.Reload()
+		case event := <-events:
+			if filepath.Clean(event.Name) == filepath.Clean(r.path) {
+				timer.Reset(debounce)
+			}
+		case <-timer.C:
+			r.Reload()
+		}
+	}
+}
+
+// Reload reads the config and applies it. An invalid config is logged and
+// the current one is kept.
+func (r *Reloader) Reload() {
+	cfg, err := Load(r.path)
+	if err != nil {
+		r.log.Error("config not reloaded", slg.Err(err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fields := restartRequired(r.current, cfg); len(fields) > 0 {
+		r.log.Warn("config fields changed that need a restart", slog.Any("fields", fields))
+	}
+
+	for _, fn := range r.handlers {
+		fn(r.current, cfg)
+	}
+
+	r.current = cfg
+	r.log.Info("config reloaded")
+}
+
+// restartRequired lists the yaml paths of fields that differ between old
+// and new and are not tagged reload:"true".
+func restartRequired(old, new *Config) []string {
+	return diff(reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), "")
+}
+
+func diff(old, new reflect.Value, prefix string) []string {
+	var fields []string
+
+	t := old.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := prefix + field.Tag.Get("yaml")
+
+		if field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == t.PkgPath() {
+			fields = append(fields, diff(old.Field(i), new.Field(i), name+".")...)
+			continue
+		}
+
+		if field.Tag.Get("reload") == "true" {
+			continue
+		}
+
+		if !reflect.DeepEqual(old.Field(i).Interface(), new.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const fileSuffix = "_FILE"
+
+var (
+	mu sync.Mutex
+	// fromFile are the values readSecretFiles set, which it may replace on
+	// reload.
+	fromFile = map[string]string{}
+)
+
+// readSecretFiles sets every variable of cfg whose <NAME>_FILE variable is
+// set to the contents of that file, for secrets mounted by docker or k8s.
+func readSecretFiles(cfg any) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, name := range envNames(reflect.TypeOf(cfg).Elem(), "") {
+		path := os.Getenv(name + fileSuffix)
+		if path == "" {
+			continue
+		}
+
+		if value, set := os.LookupEnv(name); set && value != fromFile[name] {
+			return fmt.Errorf("both %s and %s%s are set", name, name, fileSuffix)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", name, fileSuffix, err)
+		}
+
+		value := strings.TrimRight(string(data), "\r\n")
+		if err = os.Setenv(name, value); err != nil {
+			return err
+		}
+		fromFile[name] = value
+	}
+
+	return nil
+}
+
+// envNames lists the variables cleanenv reads for t, following env-prefix
+// of nested structs.
+func envNames(t reflect.Type, prefix string) []string {
+	var names []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == t.PkgPath() {
+			names = append(names, envNames(field.Type, prefix+field.Tag.Get("env-prefix"))...)
+			continue
+		}
+
+		if env := field.Tag.Get("env"); env != "" {
+			names = append(names, prefix+env)
+		}
+	}
+
+	return names
+}
@@ -2,16 +2,67 @@ package entity
 
 import (
 	"github.com/google/uuid"
+	"math"
 	"time"
 )
 
 type House struct {
+	ID        int64  `json:"id"`
+	Address   string `json:"address"`
+	Postcode  string `json:"postcode"`
+	Year      int64  `json:"year"`
+	Developer string `json:"developer"`
+	// Lat and Lon are set together or not at all.
+	Lat *float64 `json:"lat"`
+	Lon *float64 `json:"lon"`
+	// DeveloperID links the house to a developer, Developer is then the
+	// developer's name.
+	DeveloperID *int64 `json:"developer_id"`
+	Floors      *int64 `json:"floors"`
+	Material    string `json:"material"`
+	Parking     string `json:"parking"`
+	Elevators   *int64 `json:"elevators"`
+	// ConstructionStatus is built or under_construction, CompletionQuarter
+	// (like 2026-Q3) is set only for a house under construction.
+	ConstructionStatus string    `json:"construction_status"`
+	CompletionQuarter  string    `json:"completion_quarter"`
+	Version            int64     `json:"version"`
+	CreatedFl          time.Time `json:"created_at"`
+	UpdateFl           time.Time `json:"update_at"`
+}
+
+// HousePatch is a partial update of a house: nil fields are left as they
+// are, an empty CompletionQuarter clears it.
+type HousePatch struct {
+	ID                 int64
+	Version            int64
+	Year               *int64
+	DeveloperID        *int64
+	Floors             *int64
+	Material           *string
+	Parking            *string
+	Elevators          *int64
+	ConstructionStatus *string
+	CompletionQuarter  *string
+}
+
+func (p HousePatch) Empty() bool {
+	return p.Year == nil && p.DeveloperID == nil && p.Floors == nil && p.Material == nil && p.Parking == nil &&
+		p.Elevators == nil && p.ConstructionStatus == nil && p.CompletionQuarter == nil
+}
+
+type Developer struct {
 	ID        int64     `json:"id"`
-	Address   string    `json:"address"`
-	Year      int64     `json:"year"`
-	Developer string    `json:"developer"`
-	CreatedFl time.Time `json:"created_at"`
-	UpdateFl  time.Time `json:"update_at"`
+	Name      string    `json:"name"`
+	Website   string    `json:"website"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NearbyHouse is a house found around a point and its distance from the
+// point in meters.
+type NearbyHouse struct {
+	House    House
+	Distance float64
 }
 
 type Flat struct {
@@ -22,16 +73,173 @@ type Flat struct {
 	Price   int64     `json:"price"`
 	Rooms   int64     `json:"rooms"`
 	Status  string    `json:"status"`
+	Version int64     `json:"version"`
+	// Floor, areas (in m²) and the ceiling height (in m) are nil until the
+	// owner sets them, Balcony and Renovation are empty.
+	Floor         *int64   `json:"floor"`
+	TotalArea     *float64 `json:"total_area"`
+	LivingArea    *float64 `json:"living_area"`
+	CeilingHeight *float64 `json:"ceiling_height"`
+	Balcony       string   `json:"balcony"`
+	Renovation    string   `json:"renovation"`
+	Amenities     []string `json:"amenities"`
+	// Photos are loaded only for approved flats, in display order.
+	Photos []Photo `json:"photos"`
+}
+
+// PricePerSqm is the price of a square meter of total area rounded to a
+// whole number, ok is false while the area is unknown.
+func (f Flat) PricePerSqm() (price int64, ok bool) {
+	if f.TotalArea == nil || *f.TotalArea <= 0 {
+		return 0, false
+	}
+
+	return int64(math.Round(float64(f.Price) / *f.TotalArea)), true
+}
+
+// Photo is an image attached to a flat: a photo or a floor plan. The image
+// and its thumbnail are kept in a blob store under Key and ThumbnailKey.
+type Photo struct {
+	ID           int64     `json:"id"`
+	FlatID       int64     `json:"flat_id"`
+	Kind         string    `json:"kind"`
+	Position     int       `json:"position"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Key          string    `json:"key"`
+	ThumbnailKey string    `json:"thumbnail_key"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PricePoint is a price of a flat and when it was set.
+type PricePoint struct {
+	Price     int64     `json:"price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// FlatFilter selects approved flats for a search. Nil bounds, empty
+// strings and empty lists do not filter.
+type FlatFilter struct {
+	PriceMin *int64
+	PriceMax *int64
+	RoomsMin *int64
+	RoomsMax *int64
+	YearMin  *int64
+	YearMax  *int64
+	FloorMin *int64
+	FloorMax *int64
+	AreaMin  *float64
+	AreaMax  *float64
+	// HasBalcony selects flats with a balcony or loggia if true, without
+	// both if false.
+	HasBalcony *bool
+	// Renovations match any of them, Amenities all of them.
+	Renovations []string
+	Amenities   []string
+	Developer   string
+	// Address is matched by full-text search.
+	Address string
+	// Sort is price, rooms, year (of the house) or id; ties are broken by id.
+	Sort  string
+	Desc  bool
+	Limit int
+	// After continues the search behind the last flat of a previous page.
+	After *FlatCursor
+}
+
+// FlatCursor is the position of a flat in a sorted search: the value of the
+// sort column and the id.
+type FlatCursor struct {
+	Value int64
+	ID    int64
+}
+
+type FlatPage struct {
+	Flats []Flat
+	// Total counts every flat matching the filter, not only this page.
+	Total int64
+	// Next is the cursor of the next page, nil on the last one.
+	Next *FlatCursor
+}
+
+// FlatPatch is a partial update of a flat: nil fields are left as they are.
+type FlatPatch struct {
+	ID      int64
+	Version int64
+	HouseID *int64
+	Number  *int64
+	Price   *int64
+	Rooms   *int64
+	Status  *string
+
+	Floor         *int64
+	TotalArea     *float64
+	LivingArea    *float64
+	CeilingHeight *float64
+	Balcony       *string
+	Renovation    *string
+	// Amenities replaces the whole set.
+	Amenities *[]string
+}
+
+func (p FlatPatch) Empty() bool {
+	return p.HouseID == nil && p.Number == nil && p.Price == nil && p.Rooms == nil && p.Status == nil &&
+		p.Floor == nil && p.TotalArea == nil && p.LivingArea == nil && p.CeilingHeight == nil &&
+		p.Balcony == nil && p.Renovation == nil && p.Amenities == nil
 }
 
 type User struct {
-	ID       uuid.UUID `json:"id"`
-	Email    string    `json:"email"`
-	Password string    `json:"password"`
-	UserType string    `json:"user_type"`
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	Password      string    `json:"password,omitempty"`
+	UserType      string    `json:"user_type"`
+	Status        string    `json:"status"`
+	ResetRequired bool      `json:"reset_required"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Subscription struct {
-	HouseID int    `json:"house_id"`
-	Email   string `json:"email"`
+	HouseID   int       `json:"house_id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Notification struct {
+	ID        int64     `json:"id"`
+	HouseID   int64     `json:"house_id"`
+	Email     string    `json:"email"`
+	Message   string    `json:"message"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Session struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type UserExport struct {
+	User          User           `json:"user"`
+	Flats         []Flat         `json:"flats"`
+	Subscriptions []Subscription `json:"subscriptions"`
+	Notifications []Notification `json:"notifications"`
+	ExportedAt    time.Time      `json:"exported_at"`
+}
+
+// IdempotentResponse is the response stored for an Idempotency-Key. Status
+// is zero while the first request with the key is still in flight.
+type IdempotentResponse struct {
+	Fingerprint string
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+	// CreatedAt is when the key was reserved. It identifies the
+	// reservation, which may be taken over once its lease expires.
+	CreatedAt time.Time
 }
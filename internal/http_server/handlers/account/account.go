@@ -0,0 +1,67 @@
+package account
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AccountStorage
+type AccountStorage interface {
+	ExportUser(ctx context.Context, userID uuid.UUID) (entity.UserExport, error)
+	RequestErasure(ctx context.Context, userID uuid.UUID) error
+}
+
+func Export(log *slog.Logger, storage AccountStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.account.Export"
+		username := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		export, err := storage.ExportUser(r.Context(), username)
+		if err != nil {
+			message := "failed to export user data"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("user data exported")
+
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%s.json"`, username))
+		render.JSON(w, r, api.NewUserExport(export))
+	}
+}
+
+func Erase(log *slog.Logger, storage AccountStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.account.Erase"
+		reqID := middleware.GetReqID(r.Context())
+		username := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		err := storage.RequestErasure(r.Context(), username)
+		if err != nil {
+			message := "failed to request erasure"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		message := "erasure scheduled"
+		log.Info(message)
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, map[string]string{"message": message, "request_id": reqID})
+	}
+}
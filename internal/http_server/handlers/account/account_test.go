@@ -0,0 +1,148 @@
+package account_test
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/handlers/account"
+	"avito_tech/internal/http_server/handlers/account/mocks"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name            string
+		expectedStatus  int
+		expectedMessage string
+		expectedError   error
+	}{
+		{
+			name:           "export",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "user not found",
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "user not found",
+			expectedError:   storage.ErrUserNotFound,
+		},
+		{
+			name:            "failed export",
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to export user data",
+			expectedError:   fmt.Errorf("mock error"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			storageMock := mocks.NewAccountStorage(t)
+
+			// The storage must get the request context, so that a client
+			// disconnect cancels the query.
+			requestCtx := mock.MatchedBy(func(ctx context.Context) bool {
+				return ctx.Value("username") == userID
+			})
+
+			storageMock.On("ExportUser", requestCtx, userID).
+				Return(entity.UserExport{User: entity.User{ID: userID, Email: "user@example.com"}}, tt.expectedError).Once()
+
+			handler := account.Export(nil, storageMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/me/export", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), "username", userID)
+			req = req.WithContext(ctx)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				err = json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedMessage, response.Message)
+				return
+			}
+
+			require.Contains(t, rr.Header().Get("Content-Disposition"), userID.String())
+
+			var export api.UserExport
+			err = json.Unmarshal(rr.Body.Bytes(), &export)
+			require.NoError(t, err)
+			require.Equal(t, userID, export.User.Id)
+		})
+	}
+}
+
+func TestErase(t *testing.T) {
+	tests := []struct {
+		name            string
+		expectedStatus  int
+		expectedMessage string
+		expectedError   error
+	}{
+		{
+			name:            "erase",
+			expectedStatus:  http.StatusAccepted,
+			expectedMessage: "erasure scheduled",
+		},
+		{
+			name:            "failed erase",
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to request erasure",
+			expectedError:   fmt.Errorf("mock error"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			storageMock := mocks.NewAccountStorage(t)
+
+			storageMock.On("RequestErasure", mock.Anything, userID).
+				Return(tt.expectedError).Once()
+
+			handler := account.Erase(nil, storageMock)
+
+			req, err := http.NewRequest(http.MethodPost, "/me/erase", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), "username", userID)
+			req = req.WithContext(ctx)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			var response httperr.Response
+			err = json.Unmarshal(rr.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedMessage, response.Message)
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package admin
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type ResponseResetPassword struct {
+	Message    string    `json:"message"`
+	ResetToken uuid.UUID `json:"reset_token"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=AdminStorage
+type AdminStorage interface {
+	SearchUsers(ctx context.Context, actorID uuid.UUID, email string) ([]entity.User, error)
+	GetUserFlats(ctx context.Context, actorID, userID uuid.UUID) ([]entity.Flat, error)
+	GetUserSessions(ctx context.Context, actorID, userID uuid.UUID) ([]entity.Session, error)
+	SetUserType(ctx context.Context, actorID, userID uuid.UUID, userType string) error
+	SetUserStatus(ctx context.Context, actorID, userID uuid.UUID, status string) error
+	ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (uuid.UUID, error)
+}
+
+var userTypes = map[string]bool{
+	"client":    true,
+	"moderator": true,
+	"admin":     true,
+}
+
+func SearchUsers(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.admin.SearchUsers"
+		actorID := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		users, err := storage.SearchUsers(r.Context(), actorID, r.URL.Query().Get("email"))
+		if err != nil {
+			message := "failed to search users"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("users found", slog.Int("count", len(users)))
+
+		render.JSON(w, r, map[string][]api.User{"users": api.NewUsers(users)})
+	}
+}
+
+func UserFlats(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.admin.UserFlats"
+		actorID := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		userID, ok := parseUserID(log, w, r)
+		if !ok {
+			return
+		}
+
+		flats, err := storage.GetUserFlats(r.Context(), actorID, userID)
+		if err != nil {
+			renderStorageError(log, w, r, "failed to get flats", err)
+			return
+		}
+
+		log.Info("got user flats")
+
+		render.JSON(w, r, map[string][]api.Flat{"flats": api.NewFlats(flats)})
+	}
+}
+
+func UserSessions(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.admin.UserSessions"
+		actorID := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		userID, ok := parseUserID(log, w, r)
+		if !ok {
+			return
+		}
+
+		sessions, err := storage.GetUserSessions(r.Context(), actorID, userID)
+		if err != nil {
+			renderStorageError(log, w, r, "failed to get sessions", err)
+			return
+		}
+
+		log.Info("got user sessions")
+
+		render.JSON(w, r, map[string][]api.Session{"sessions": api.NewSessions(sessions)})
+	}
+}
+
+func ChangeRole(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.admin.ChangeRole"
+		reqID := middleware.GetReqID(r.Context())
+		actorID := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		userID, ok := parseUserID(log, w, r)
+		if !ok {
+			return
+		}
+
+		var req api.PostAdminUsersIdRoleJSONRequestBody
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			message := "failed to decode request body"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		if !userTypes[string(req.UserType)] {
+			message := "invalid user_type"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		err = storage.SetUserType(r.Context(), actorID, userID, string(req.UserType))
+		if err != nil {
+			renderStorageError(log, w, r, "failed to change role", err)
+			return
+		}
+
+		log.Info("user role changed", slog.String("user_type", string(req.UserType)))
+
+		render.JSON(w, r, map[string]string{"message": "role changed", "request_id": reqID})
+	}
+}
+
+func Suspend(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return setStatus(log, storage, "handlers.admin.Suspend", "suspended")
+}
+
+func Reactivate(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return setStatus(log, storage, "handlers.admin.Reactivate", "active")
+}
+
+func setStatus(log *slog.Logger, storage AdminStorage, fn, status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqID := middleware.GetReqID(r.Context())
+		actorID := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		userID, ok := parseUserID(log, w, r)
+		if !ok {
+			return
+		}
+
+		err := storage.SetUserStatus(r.Context(), actorID, userID, status)
+		if err != nil {
+			renderStorageError(log, w, r, "failed to change status", err)
+			return
+		}
+
+		log.Info("user status changed", slog.String("status", status))
+
+		render.JSON(w, r, map[string]string{"message": "user " + status, "request_id": reqID})
+	}
+}
+
+func ResetPassword(log *slog.Logger, storage AdminStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.admin.ResetPassword"
+		actorID := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		userID, ok := parseUserID(log, w, r)
+		if !ok {
+			return
+		}
+
+		token, err := storage.ForcePasswordReset(r.Context(), actorID, userID)
+		if err != nil {
+			renderStorageError(log, w, r, "failed to reset password", err)
+			return
+		}
+
+		message := "password reset required"
+		log.Info(message)
+
+		render.JSON(w, r, ResponseResetPassword{
+			Message:    message,
+			ResetToken: token,
+		})
+	}
+}
+
+func parseUserID(log *slog.Logger, w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		message := "invalid user id"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+		return uuid.UUID{}, false
+	}
+
+	return userID, true
+}
+
+func renderStorageError(log *slog.Logger, w http.ResponseWriter, r *http.Request, message string, err error) {
+	log.Error(message, slg.Err(err))
+	httperr.Render(w, r, httperr.FromStorage(err, message))
+}
+
+func GetLogLevel(log *slog.Logger, level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, api.LogLevel{Level: api.LogLevelLevel(strings.ToLower(level.Level().String()))})
+	}
+}
+
+// SetLogLevel changes the level of the service logger until restart.
+func SetLogLevel(log *slog.Logger, level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.admin.SetLogLevel"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		var req api.PutAdminLogLevelJSONRequestBody
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			message := "failed to decode request body"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		var newLevel slog.Level
+		if err = newLevel.UnmarshalText([]byte(req.Level)); err != nil {
+			message := "invalid level"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		previous := level.Level()
+		level.Set(newLevel)
+
+		log.Warn("log level changed", slog.String("from", previous.String()), slog.String("to", newLevel.String()))
+
+		render.JSON(w, r, req)
+	}
+}
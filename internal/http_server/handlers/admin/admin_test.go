@@ -0,0 +1,398 @@
+package admin_test
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/handlers/admin"
+	"avito_tech/internal/http_server/handlers/admin/mocks"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSearchUsers(t *testing.T) {
+	tests := []struct {
+		name            string
+		expectedStatus  int
+		expectedMessage string
+		expectedError   error
+	}{
+		{
+			name:           "search users",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "failed search",
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to search users",
+			expectedError:   fmt.Errorf("mock error"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			actorID := uuid.New()
+			storageMock := mocks.NewAdminStorage(t)
+
+			storageMock.On("SearchUsers", mock.Anything, actorID, "yandex").
+				Return([]entity.User{{ID: uuid.New(), Email: "a@yandex.ru", Password: "hash"}}, tt.expectedError).Once()
+
+			handler := admin.SearchUsers(nil, storageMock)
+
+			req, err := http.NewRequest(http.MethodGet, "/admin/users?email=yandex", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), "username", actorID)
+			req = req.WithContext(ctx)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			require.NotContains(t, rr.Body.String(), "hash")
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				err = json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestUserFlatsAndSessions(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		id              string
+		expectedStatus  int
+		expectedMessage string
+		expectedError   error
+		modeCreateFunc  int
+	}{
+		{
+			name:           "get flats",
+			path:           "flats",
+			id:             uuid.NewString(),
+			expectedStatus: http.StatusOK,
+			modeCreateFunc: 1,
+		},
+		{
+			name:            "flats of unknown user",
+			path:            "flats",
+			id:              uuid.NewString(),
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "user not found",
+			expectedError:   storage.ErrUserNotFound,
+			modeCreateFunc:  1,
+		},
+		{
+			name:           "get sessions",
+			path:           "sessions",
+			id:             uuid.NewString(),
+			expectedStatus: http.StatusOK,
+			modeCreateFunc: 2,
+		},
+		{
+			name:            "failed sessions",
+			path:            "sessions",
+			id:              uuid.NewString(),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to get sessions",
+			expectedError:   fmt.Errorf("mock error"),
+			modeCreateFunc:  2,
+		},
+		{
+			name:            "invalid id",
+			path:            "flats",
+			id:              "42",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid user id",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewAdminStorage(t)
+
+			switch tt.modeCreateFunc {
+			case 1:
+				storageMock.On("GetUserFlats", mock.Anything, mock.Anything, uuid.MustParse(tt.id)).
+					Return(nil, tt.expectedError).Once()
+			case 2:
+				storageMock.On("GetUserSessions", mock.Anything, mock.Anything, uuid.MustParse(tt.id)).
+					Return(nil, tt.expectedError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/admin/users/{id}/flats", admin.UserFlats(nil, storageMock))
+			r.Get("/admin/users/{id}/sessions", admin.UserSessions(nil, storageMock))
+
+			req, err := http.NewRequest(http.MethodGet, "/admin/users/"+tt.id+"/"+tt.path, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), "username", uuid.New())
+			req = req.WithContext(ctx)
+
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				err = json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestChangeRole(t *testing.T) {
+	tests := []struct {
+		name            string
+		expectedStatus  int
+		expectedMessage string
+		expectedError   error
+		requestBody     interface{}
+		modeCreateFunc  int
+	}{
+		{
+			name:           "change role",
+			expectedStatus: http.StatusOK,
+			requestBody:    api.PostAdminUsersIdRoleJSONRequestBody{UserType: "moderator"},
+			modeCreateFunc: 1,
+		},
+		{
+			name:            "unknown role",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid user_type",
+			requestBody:     api.PostAdminUsersIdRoleJSONRequestBody{UserType: "hacker"},
+		},
+		{
+			name:            "failed decode",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "failed to decode request body",
+			requestBody:     []string{},
+		},
+		{
+			name:            "failed change",
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to change role",
+			expectedError:   fmt.Errorf("mock error"),
+			requestBody:     api.PostAdminUsersIdRoleJSONRequestBody{UserType: "admin"},
+			modeCreateFunc:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewAdminStorage(t)
+
+			if tt.modeCreateFunc == 1 {
+				storageMock.On("SetUserType", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(tt.expectedError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Post("/admin/users/{id}/role", admin.ChangeRole(nil, storageMock))
+
+			input, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/users/"+uuid.NewString()+"/role", bytes.NewReader(input))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), "username", uuid.New())
+			req = req.WithContext(ctx)
+
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				err = json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestStatusAndReset(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		expectedStatus  int
+		expectedMessage string
+		expectedError   error
+		modeCreateFunc  int
+	}{
+		{
+			name:            "suspend",
+			path:            "suspend",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "user suspended",
+			modeCreateFunc:  1,
+		},
+		{
+			name:            "reactivate",
+			path:            "reactivate",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "user active",
+			modeCreateFunc:  2,
+		},
+		{
+			name:            "suspend unknown user",
+			path:            "suspend",
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "user not found",
+			expectedError:   storage.ErrUserNotFound,
+			modeCreateFunc:  1,
+		},
+		{
+			name:            "reset password",
+			path:            "reset-password",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "password reset required",
+			modeCreateFunc:  3,
+		},
+		{
+			name:            "failed reset password",
+			path:            "reset-password",
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to reset password",
+			expectedError:   fmt.Errorf("mock error"),
+			modeCreateFunc:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewAdminStorage(t)
+
+			switch tt.modeCreateFunc {
+			case 1:
+				storageMock.On("SetUserStatus", mock.Anything, mock.Anything, mock.Anything, "suspended").
+					Return(tt.expectedError).Once()
+			case 2:
+				storageMock.On("SetUserStatus", mock.Anything, mock.Anything, mock.Anything, "active").
+					Return(tt.expectedError).Once()
+			case 3:
+				storageMock.On("ForcePasswordReset", mock.Anything, mock.Anything, mock.Anything).
+					Return(uuid.New(), tt.expectedError).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Post("/admin/users/{id}/suspend", admin.Suspend(nil, storageMock))
+			r.Post("/admin/users/{id}/reactivate", admin.Reactivate(nil, storageMock))
+			r.Post("/admin/users/{id}/reset-password", admin.ResetPassword(nil, storageMock))
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/users/"+uuid.NewString()+"/"+tt.path, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			ctx := context.WithValue(req.Context(), "username", uuid.New())
+			req = req.WithContext(ctx)
+
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			var response httperr.Response
+			err = json.Unmarshal(rr.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedMessage, response.Message)
+		})
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		expectedLevel  slog.Level
+	}{
+		{
+			name:           "set warn",
+			requestBody:    api.LogLevel{Level: api.LogLevelLevelWarn},
+			expectedStatus: http.StatusOK,
+			expectedLevel:  slog.LevelWarn,
+		},
+		{
+			name:           "invalid level",
+			requestBody:    api.LogLevel{Level: "verbose"},
+			expectedStatus: http.StatusBadRequest,
+			expectedLevel:  slog.LevelInfo,
+		},
+		{
+			name:           "failed decode",
+			requestBody:    []string{},
+			expectedStatus: http.StatusBadRequest,
+			expectedLevel:  slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			level := &slog.LevelVar{}
+
+			input, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			admin.SetLogLevel(nil, level).ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/admin/log-level", bytes.NewReader(input)))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			require.Equal(t, tt.expectedLevel, level.Level())
+
+			rr = httptest.NewRecorder()
+			admin.GetLogLevel(nil, level).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/log-level", nil))
+
+			var resp api.LogLevel
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, strings.ToLower(tt.expectedLevel.String()), string(resp.Level))
+		})
+	}
+}
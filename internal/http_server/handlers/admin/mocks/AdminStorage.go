@@ -0,0 +1,187 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "avito_tech/internal/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// AdminStorage is an autogenerated mock type for the AdminStorage type
+type AdminStorage struct {
+	mock.Mock
+}
+
+// ForcePasswordReset provides a mock function with given fields: ctx, actorID, userID
+func (_m *AdminStorage) ForcePasswordReset(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) (uuid.UUID, error) {
+	ret := _m.Called(ctx, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForcePasswordReset")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) (uuid.UUID, error)); ok {
+		return rf(ctx, actorID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) uuid.UUID); ok {
+		r0 = rf(ctx, actorID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, actorID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserFlats provides a mock function with given fields: ctx, actorID, userID
+func (_m *AdminStorage) GetUserFlats(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) ([]entity.Flat, error) {
+	ret := _m.Called(ctx, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserFlats")
+	}
+
+	var r0 []entity.Flat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) ([]entity.Flat, error)); ok {
+		return rf(ctx, actorID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []entity.Flat); ok {
+		r0 = rf(ctx, actorID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Flat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, actorID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserSessions provides a mock function with given fields: ctx, actorID, userID
+func (_m *AdminStorage) GetUserSessions(ctx context.Context, actorID uuid.UUID, userID uuid.UUID) ([]entity.Session, error) {
+	ret := _m.Called(ctx, actorID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserSessions")
+	}
+
+	var r0 []entity.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) ([]entity.Session, error)); ok {
+		return rf(ctx, actorID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []entity.Session); ok {
+		r0 = rf(ctx, actorID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, actorID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchUsers provides a mock function with given fields: ctx, actorID, email
+func (_m *AdminStorage) SearchUsers(ctx context.Context, actorID uuid.UUID, email string) ([]entity.User, error) {
+	ret := _m.Called(ctx, actorID, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchUsers")
+	}
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) ([]entity.User, error)); ok {
+		return rf(ctx, actorID, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) []entity.User); ok {
+		r0 = rf(ctx, actorID, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, actorID, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUserStatus provides a mock function with given fields: ctx, actorID, userID, status
+func (_m *AdminStorage) SetUserStatus(ctx context.Context, actorID uuid.UUID, userID uuid.UUID, status string) error {
+	ret := _m.Called(ctx, actorID, userID, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, actorID, userID, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserType provides a mock function with given fields: ctx, actorID, userID, userType
+func (_m *AdminStorage) SetUserType(ctx context.Context, actorID uuid.UUID, userID uuid.UUID, userType string) error {
+	ret := _m.Called(ctx, actorID, userID, userType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserType")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, actorID, userID, userType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAdminStorage creates a new instance of AdminStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAdminStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AdminStorage {
+	mock := &AdminStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
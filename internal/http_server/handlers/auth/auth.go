@@ -1,9 +1,14 @@
 package auth
 
 import (
+	"avito_tech/api"
 	"avito_tech/internal/entity"
 	"avito_tech/internal/lib/auth"
+	"avito_tech/internal/lib/httperr"
 	"avito_tech/internal/lib/logger/slg"
+	stg "avito_tech/internal/storage"
+	"context"
+	"errors"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -17,9 +22,11 @@ import (
 
 //go:generate go run github.com/vektra/mockery/v2@latest --name=AuthStorage
 type AuthStorage interface {
-	CreateUser(user entity.User) (uuid.UUID, error)
-	Register(user entity.User) (string, error)
-	Login(email string) (entity.User, error)
+	CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error)
+	Register(ctx context.Context, user entity.User) (string, error)
+	Login(ctx context.Context, email string) (entity.User, error)
+	CreateSession(ctx context.Context, session entity.Session) (uuid.UUID, error)
+	ResetPassword(ctx context.Context, token uuid.UUID, password string) error
 }
 
 type ResponseDummyLogin struct {
@@ -30,6 +37,8 @@ type ResponseDummyLogin struct {
 	Token    string    `json:"token"`
 }
 
+const tokenTTL = time.Hour * 1
+
 var MySigningKey = []byte(os.Getenv("MY_SIGNING_KEY"))
 
 func DummyLogin(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
@@ -37,13 +46,19 @@ func DummyLogin(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 		const fn = "handlers.auth.DummyLogin"
 		reqID := middleware.GetReqID(r.Context())
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
 		userType := r.URL.Query().Get("user_type")
 		if userType == "" {
 			log.Error("user_type parameter is missing")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": "user_type parameter is required"})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, "user_type parameter is required"))
+			return
+		}
+
+		if userType == "admin" {
+			message := "admin accounts cannot be self-issued"
+			log.Error(message)
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeForbidden, message))
 			return
 		}
 
@@ -57,34 +72,42 @@ func DummyLogin(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 		if err != nil {
 			message := "failed to generate hash password"
 			log.Error("message", message, slg.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Internal(message))
 			return
 		}
 
 		user.Password = string(hashPassword)
 
-		id, err := storage.CreateUser(user)
+		id, err := storage.CreateUser(r.Context(), user)
 		if err != nil {
 			message := "failed added user"
 			log.Error("message", message, slg.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		expiresAt := time.Now().Add(tokenTTL)
+
+		sessionID, err := storage.CreateSession(r.Context(), entity.Session{UserID: id, ExpiresAt: expiresAt})
+		if err != nil {
+			message := "failed to create session"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 			"username": id.String(),
 			"role":     user.UserType,
-			"exp":      time.Now().Add(time.Hour * 1).Unix(),
+			"sid":      sessionID.String(),
+			"exp":      expiresAt.Unix(),
 		})
 
 		tokenString, err := token.SignedString(MySigningKey)
 		if err != nil {
 			message := "failed to signed token"
 			log.Error(message)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Internal(message))
 			return
 		}
 
@@ -103,42 +126,57 @@ func DummyLogin(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 func Register(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.auth.register"
-		reqID := middleware.GetReqID(r.Context())
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
-		var user entity.User
+		var req api.PostRegisterJSONRequestBody
 
-		err := render.DecodeJSON(r.Body, &user)
+		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			message := "failed to decode request body"
 			log.Error(message)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
 			return
 		}
 
 		log.Info("request body decoded")
 
+		user := entity.User{UserType: string(api.UserTypeClient)}
+		if req.Email != nil {
+			user.Email = string(*req.Email)
+		}
+		if req.Password != nil {
+			user.Password = *req.Password
+		}
+		if req.UserType != nil {
+			user.UserType = string(*req.UserType)
+		}
+
+		if user.UserType == "admin" {
+			message := "admin accounts cannot be self-registered"
+
+			log.Error(message)
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeForbidden, message))
+			return
+		}
+
 		hashPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 		if err != nil {
 			message := "failed to generate hash password"
 
 			log.Error("message", message, slg.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Internal(message))
 			return
 		}
 
 		user.Password = string(hashPassword)
 
-		userID, err := storage.Register(user)
+		userID, err := storage.Register(r.Context(), user)
 		if err != nil {
 			message := "failed to register user"
 
 			log.Error(message)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
@@ -159,56 +197,86 @@ func Login(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 		const fn = "handlers.auth.register"
 		reqID := middleware.GetReqID(r.Context())
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
-		var user entity.User
+		var req api.PostLoginJSONRequestBody
 
-		err := render.DecodeJSON(r.Body, &user)
+		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			message := "failed to decode request body"
 			log.Error(message)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
 			return
 		}
 
 		log.Info("request body decoded")
 
-		storageUser, err := storage.Login(user.Email)
+		var user entity.User
+		if req.Email != nil {
+			user.Email = string(*req.Email)
+		}
+		if req.Password != nil {
+			user.Password = *req.Password
+		}
+
+		storageUser, err := storage.Login(r.Context(), user.Email)
 		if err != nil {
-			if err.Error() == "user not found: storage.postgres.Login" {
+			if errors.Is(err, stg.ErrUserNotFound) {
 				message := "user not found"
 
 				log.Error(message)
-				render.Status(r, http.StatusNotFound)
-				render.JSON(w, r, map[string]string{"message": message})
+				httperr.Render(w, r, httperr.NotFound(httperr.CodeUserNotFound, message))
 				return
 			}
 
-			message := "failed to build query"
+			message := "failed to login"
 
-			log.Error(message)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
-		log.Info(user.Password)
-
 		err = bcrypt.CompareHashAndPassword([]byte(storageUser.Password), []byte(user.Password))
 		if err != nil {
 			message := "invalid password"
 
 			log.Error(message)
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Unauthorized(httperr.CodeInvalidCredentials, message))
+			return
+		}
+
+		if storageUser.Status == "suspended" {
+			message := "user is suspended"
+
+			log.Error(message)
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeUserInactive, message))
+			return
+		}
+
+		if storageUser.ResetRequired {
+			message := "password reset required"
+
+			log.Error(message)
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeResetRequired, message))
+			return
+		}
+
+		expiresAt := time.Now().Add(tokenTTL)
+
+		sessionID, err := storage.CreateSession(r.Context(), entity.Session{UserID: storageUser.ID, ExpiresAt: expiresAt})
+		if err != nil {
+			message := "failed to create session"
+
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 			"username": storageUser.ID.String(),
 			"role":     storageUser.UserType,
-			"exp":      time.Now().Add(time.Hour * 1).Unix(),
+			"sid":      sessionID.String(),
+			"exp":      expiresAt.Unix(),
 		})
 
 		tokenString, err := token.SignedString(MySigningKey)
@@ -216,8 +284,7 @@ func Login(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 			message := "failed to signed token"
 
 			log.Error(message)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Internal(message))
 			return
 		}
 
@@ -226,3 +293,59 @@ func Login(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
 		render.JSON(w, r, map[string]string{"token": tokenString})
 	}
 }
+
+func ResetPassword(log *slog.Logger, storage AuthStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.auth.ResetPassword"
+		reqID := middleware.GetReqID(r.Context())
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		var req api.PostPasswordResetJSONRequestBody
+
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			message := "failed to decode request body"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		if req.Token == uuid.Nil || req.Password == "" {
+			message := "token and password are required"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			message := "failed to generate hash password"
+
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.Internal(message))
+			return
+		}
+
+		err = storage.ResetPassword(r.Context(), req.Token, string(hashPassword))
+		if err != nil {
+			if errors.Is(err, stg.ErrUserNotFound) {
+				message := "invalid reset token"
+
+				log.Error(message)
+				httperr.Render(w, r, httperr.NotFound(httperr.CodeResetTokenInvalid, message))
+				return
+			}
+
+			message := "failed to reset password"
+
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("password reset", slog.Any("request", reqID))
+
+		render.JSON(w, r, map[string]string{"message": "Password changed"})
+	}
+}
@@ -1,9 +1,12 @@
 package auth_test
 
 import (
+	"avito_tech/api"
 	"avito_tech/internal/entity"
 	"avito_tech/internal/http_server/handlers/auth"
 	"avito_tech/internal/http_server/handlers/auth/mocks"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -71,6 +74,20 @@ func TestDummyLogin(t *testing.T) {
 			modeCreateMockFunc: 3,
 			mockError:          fmt.Errorf("mock error"),
 		},
+		{
+			name:               "failed session",
+			userType:           "client",
+			expectedMessage:    "failed to create session",
+			expectedStatus:     http.StatusInternalServerError,
+			modeCreateMockFunc: 4,
+			mockError:          fmt.Errorf("mock error"),
+		},
+		{
+			name:            "admin",
+			userType:        "admin",
+			expectedMessage: "admin accounts cannot be self-issued",
+			expectedStatus:  http.StatusForbidden,
+		},
 	}
 
 	for _, tt := range tests {
@@ -82,10 +99,13 @@ func TestDummyLogin(t *testing.T) {
 
 			switch tt.modeCreateMockFunc {
 			case 1:
-				storageMock.On("CreateUser", mock.Anything, mock.Anything).
+				storageMock.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).
+					Return(uuid.New(), nil).Once()
+
+				storageMock.On("CreateSession", mock.Anything, mock.Anything).
 					Return(uuid.New(), nil).Once()
 			case -1:
-				storageMock.On("CreateUser", mock.Anything, mock.Anything).
+				storageMock.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).
 					Return(uuid.Nil, tt.mockError).Once()
 			case 2:
 				patches = gomonkey.ApplyFunc(bcrypt.GenerateFromPassword, func(password []byte, cost int) ([]byte, error) {
@@ -106,8 +126,17 @@ func TestDummyLogin(t *testing.T) {
 				})
 				defer patches.Reset()
 
-				storageMock.On("CreateUser", mock.Anything, mock.Anything).
+				storageMock.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).
+					Return(uuid.New(), nil).Once()
+
+				storageMock.On("CreateSession", mock.Anything, mock.Anything).
 					Return(uuid.New(), nil).Once()
+			case 4:
+				storageMock.On("CreateUser", mock.Anything, mock.Anything, mock.Anything).
+					Return(uuid.New(), nil).Once()
+
+				storageMock.On("CreateSession", mock.Anything, mock.Anything).
+					Return(uuid.Nil, tt.mockError).Once()
 			}
 
 			handler := auth.DummyLogin(nil, storageMock)
@@ -129,10 +158,10 @@ func TestDummyLogin(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
@@ -151,20 +180,20 @@ func TestRegister(t *testing.T) {
 			name:               "register user",
 			expectedStatus:     http.StatusOK,
 			modeCreateMockFunc: 1,
-			requestBody:        entity.User{},
+			requestBody:        api.PostRegisterJSONRequestBody{},
 		},
 		{
 			name:            "failed to decode",
 			expectedStatus:  http.StatusBadRequest,
 			expectedMessage: "failed to decode request body",
-			requestBody:     entity.House{},
+			requestBody:     []string{},
 		},
 		{
 			name:               "generate hash password",
 			expectedStatus:     http.StatusInternalServerError,
 			expectedMessage:    "failed to generate hash password",
 			modeCreateMockFunc: 2,
-			requestBody:        entity.User{},
+			requestBody:        api.PostRegisterJSONRequestBody{},
 			mockError:          fmt.Errorf("mock error"),
 		},
 		{
@@ -172,9 +201,15 @@ func TestRegister(t *testing.T) {
 			expectedStatus:     http.StatusInternalServerError,
 			expectedMessage:    "failed to register user",
 			modeCreateMockFunc: -1,
-			requestBody:        entity.User{},
+			requestBody:        api.PostRegisterJSONRequestBody{},
 			mockError:          fmt.Errorf("mock error"),
 		},
+		{
+			name:            "register admin",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "admin accounts cannot be self-registered",
+			requestBody:     map[string]string{"user_type": "admin"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -186,7 +221,7 @@ func TestRegister(t *testing.T) {
 
 			switch tt.modeCreateMockFunc {
 			case 1:
-				storageMock.On("Register", mock.Anything).
+				storageMock.On("Register", mock.Anything, mock.Anything).
 					Return(uuid.New().String(), nil).Once()
 			case 2:
 				patches = gomonkey.ApplyFunc(bcrypt.GenerateFromPassword, func(password []byte, cost int) ([]byte, error) {
@@ -194,7 +229,7 @@ func TestRegister(t *testing.T) {
 				})
 				defer patches.Reset()
 			case -1:
-				storageMock.On("Register", mock.Anything).
+				storageMock.On("Register", mock.Anything, mock.Anything).
 					Return("", tt.mockError).Once()
 			}
 
@@ -213,10 +248,10 @@ func TestRegister(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
@@ -230,32 +265,33 @@ func TestLogin(t *testing.T) {
 		modeCreateMockFunc int
 		mockError          error
 		requestBody        interface{}
+		storageUser        entity.User
 	}{
 		{
 			name:               "success login user",
 			expectedStatus:     http.StatusOK,
 			modeCreateMockFunc: 1,
-			requestBody:        entity.User{},
+			requestBody:        api.PostLoginJSONRequestBody{},
 		},
 		{
 			name:            "failed decode",
 			expectedStatus:  http.StatusBadRequest,
 			expectedMessage: "failed to decode request body",
-			requestBody:     entity.House{},
+			requestBody:     []string{},
 		},
 		{
 			name:               "not found",
 			expectedStatus:     http.StatusNotFound,
 			expectedMessage:    "user not found",
-			requestBody:        entity.User{},
+			requestBody:        api.PostLoginJSONRequestBody{},
 			modeCreateMockFunc: 3,
-			mockError:          errors.New("user not found: storage.postgres.Login"),
+			mockError:          fmt.Errorf("storage.postgres.Login: %w", storage.ErrUserNotFound),
 		},
 		{
 			name:               "failed login",
 			expectedStatus:     http.StatusInternalServerError,
-			expectedMessage:    "failed to build query",
-			requestBody:        entity.User{},
+			expectedMessage:    "failed to login",
+			requestBody:        api.PostLoginJSONRequestBody{},
 			modeCreateMockFunc: 3,
 			mockError:          errors.New("mock error"),
 		},
@@ -263,7 +299,7 @@ func TestLogin(t *testing.T) {
 			name:               "unauthorized",
 			expectedStatus:     http.StatusUnauthorized,
 			expectedMessage:    "invalid password",
-			requestBody:        entity.User{},
+			requestBody:        api.PostLoginJSONRequestBody{},
 			modeCreateMockFunc: 4,
 			mockError:          fmt.Errorf("mock error"),
 		},
@@ -271,10 +307,26 @@ func TestLogin(t *testing.T) {
 			name:               "bad token",
 			expectedStatus:     http.StatusInternalServerError,
 			expectedMessage:    "failed to signed token",
-			requestBody:        entity.User{},
+			requestBody:        api.PostLoginJSONRequestBody{},
 			modeCreateMockFunc: 2,
 			mockError:          fmt.Errorf("mock error"),
 		},
+		{
+			name:               "suspended",
+			expectedStatus:     http.StatusForbidden,
+			expectedMessage:    "user is suspended",
+			requestBody:        api.PostLoginJSONRequestBody{},
+			modeCreateMockFunc: 5,
+			storageUser:        entity.User{Status: "suspended"},
+		},
+		{
+			name:               "reset required",
+			expectedStatus:     http.StatusForbidden,
+			expectedMessage:    "password reset required",
+			requestBody:        api.PostLoginJSONRequestBody{},
+			modeCreateMockFunc: 5,
+			storageUser:        entity.User{Status: "active", ResetRequired: true},
+		},
 	}
 
 	for _, tt := range tests {
@@ -288,18 +340,24 @@ func TestLogin(t *testing.T) {
 
 			switch tt.modeCreateMockFunc {
 			case 1:
-				storageMock.On("Login", mock.Anything).
+				storageMock.On("Login", mock.Anything, mock.Anything).
 					Return(entity.User{}, nil).Once()
 
+				storageMock.On("CreateSession", mock.Anything, mock.Anything).
+					Return(uuid.New(), nil).Once()
+
 				patches = gomonkey.ApplyFunc(bcrypt.CompareHashAndPassword, func(storagePassword []byte, password []byte) error {
 					return nil
 				})
 				defer patches.Reset()
 
 			case 2:
-				storageMock.On("Login", mock.Anything).
+				storageMock.On("Login", mock.Anything, mock.Anything).
 					Return(entity.User{}, nil).Once()
 
+				storageMock.On("CreateSession", mock.Anything, mock.Anything).
+					Return(uuid.New(), nil).Once()
+
 				patches = gomonkey.ApplyFunc(bcrypt.CompareHashAndPassword, func(storagePassword []byte, password []byte) error {
 					return nil
 				})
@@ -319,12 +377,21 @@ func TestLogin(t *testing.T) {
 				defer patches.Reset()
 
 			case 3:
-				storageMock.On("Login", mock.Anything).
+				storageMock.On("Login", mock.Anything, mock.Anything).
 					Return(entity.User{}, tt.mockError).Once()
 
 			case 4:
-				storageMock.On("Login", mock.Anything).
+				storageMock.On("Login", mock.Anything, mock.Anything).
 					Return(entity.User{}, nil).Once()
+
+			case 5:
+				storageMock.On("Login", mock.Anything, mock.Anything).
+					Return(tt.storageUser, nil).Once()
+
+				patches = gomonkey.ApplyFunc(bcrypt.CompareHashAndPassword, func(storagePassword []byte, password []byte) error {
+					return nil
+				})
+				defer patches.Reset()
 			}
 
 			handler := auth.Login(nil, storageMock)
@@ -342,10 +409,94 @@ func TestLogin(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
+				err = json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	tests := []struct {
+		name               string
+		expectedStatus     int
+		expectedMessage    string
+		modeCreateMockFunc int
+		mockError          error
+		requestBody        interface{}
+	}{
+		{
+			name:               "reset password",
+			expectedStatus:     http.StatusOK,
+			modeCreateMockFunc: 1,
+			requestBody:        api.PostPasswordResetJSONRequestBody{Token: uuid.New(), Password: "secret"},
+		},
+		{
+			name:            "failed decode",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "failed to decode request body",
+			requestBody:     []string{},
+		},
+		{
+			name:            "empty password",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "token and password are required",
+			requestBody:     api.PostPasswordResetJSONRequestBody{Token: uuid.New()},
+		},
+		{
+			name:               "unknown token",
+			expectedStatus:     http.StatusNotFound,
+			expectedMessage:    "invalid reset token",
+			modeCreateMockFunc: 2,
+			mockError:          storage.ErrUserNotFound,
+			requestBody:        api.PostPasswordResetJSONRequestBody{Token: uuid.New(), Password: "secret"},
+		},
+		{
+			name:               "failed reset",
+			expectedStatus:     http.StatusInternalServerError,
+			expectedMessage:    "failed to reset password",
+			modeCreateMockFunc: 2,
+			mockError:          errors.New("mock error"),
+			requestBody:        api.PostPasswordResetJSONRequestBody{Token: uuid.New(), Password: "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := mocks.NewAuthStorage(t)
+
+			switch tt.modeCreateMockFunc {
+			case 1:
+				storageMock.On("ResetPassword", mock.Anything, mock.Anything, mock.Anything).
+					Return(nil).Once()
+			case 2:
+				storageMock.On("ResetPassword", mock.Anything, mock.Anything, mock.Anything).
+					Return(tt.mockError).Once()
+			}
+
+			handler := auth.ResetPassword(nil, storageMock)
+
+			input, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/password/reset", bytes.NewReader(input))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
@@ -1,9 +1,10 @@
-// Code generated by mockery v2.44.2. DO NOT EDIT.
+// Code generated by mockery v2.53.5. DO NOT EDIT.
 
 package mocks
 
 import (
 	entity "avito_tech/internal/entity"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -15,9 +16,39 @@ type AuthStorage struct {
 	mock.Mock
 }
 
-// CreateUser provides a mock function with given fields: user
-func (_m *AuthStorage) CreateUser(user entity.User) (uuid.UUID, error) {
-	ret := _m.Called(user)
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *AuthStorage) CreateSession(ctx context.Context, session entity.Session) (uuid.UUID, error) {
+	ret := _m.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Session) (uuid.UUID, error)); ok {
+		return rf(ctx, session)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Session) uuid.UUID); ok {
+		r0 = rf(ctx, session)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Session) error); ok {
+		r1 = rf(ctx, session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUser provides a mock function with given fields: ctx, user
+func (_m *AuthStorage) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateUser")
@@ -25,19 +56,19 @@ func (_m *AuthStorage) CreateUser(user entity.User) (uuid.UUID, error) {
 
 	var r0 uuid.UUID
 	var r1 error
-	if rf, ok := ret.Get(0).(func(entity.User) (uuid.UUID, error)); ok {
-		return rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.User) (uuid.UUID, error)); ok {
+		return rf(ctx, user)
 	}
-	if rf, ok := ret.Get(0).(func(entity.User) uuid.UUID); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.User) uuid.UUID); ok {
+		r0 = rf(ctx, user)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(uuid.UUID)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(entity.User) error); ok {
-		r1 = rf(user)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.User) error); ok {
+		r1 = rf(ctx, user)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -45,9 +76,9 @@ func (_m *AuthStorage) CreateUser(user entity.User) (uuid.UUID, error) {
 	return r0, r1
 }
 
-// Login provides a mock function with given fields: email
-func (_m *AuthStorage) Login(email string) (entity.User, error) {
-	ret := _m.Called(email)
+// Login provides a mock function with given fields: ctx, email
+func (_m *AuthStorage) Login(ctx context.Context, email string) (entity.User, error) {
+	ret := _m.Called(ctx, email)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Login")
@@ -55,17 +86,17 @@ func (_m *AuthStorage) Login(email string) (entity.User, error) {
 
 	var r0 entity.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (entity.User, error)); ok {
-		return rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (entity.User, error)); ok {
+		return rf(ctx, email)
 	}
-	if rf, ok := ret.Get(0).(func(string) entity.User); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, string) entity.User); ok {
+		r0 = rf(ctx, email)
 	} else {
 		r0 = ret.Get(0).(entity.User)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -73,9 +104,9 @@ func (_m *AuthStorage) Login(email string) (entity.User, error) {
 	return r0, r1
 }
 
-// Register provides a mock function with given fields: user
-func (_m *AuthStorage) Register(user entity.User) (string, error) {
-	ret := _m.Called(user)
+// Register provides a mock function with given fields: ctx, user
+func (_m *AuthStorage) Register(ctx context.Context, user entity.User) (string, error) {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Register")
@@ -83,17 +114,17 @@ func (_m *AuthStorage) Register(user entity.User) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(entity.User) (string, error)); ok {
-		return rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.User) (string, error)); ok {
+		return rf(ctx, user)
 	}
-	if rf, ok := ret.Get(0).(func(entity.User) string); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.User) string); ok {
+		r0 = rf(ctx, user)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(entity.User) error); ok {
-		r1 = rf(user)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.User) error); ok {
+		r1 = rf(ctx, user)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -101,6 +132,24 @@ func (_m *AuthStorage) Register(user entity.User) (string, error) {
 	return r0, r1
 }
 
+// ResetPassword provides a mock function with given fields: ctx, token, password
+func (_m *AuthStorage) ResetPassword(ctx context.Context, token uuid.UUID, password string) error {
+	ret := _m.Called(ctx, token, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, token, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewAuthStorage creates a new instance of AuthStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuthStorage(t interface {
@@ -0,0 +1,94 @@
+package developer
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	stg "avito_tech/internal/storage"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=DeveloperStorage
+type DeveloperStorage interface {
+	CreateDeveloper(ctx context.Context, developer entity.Developer) (entity.Developer, error)
+	GetDeveloper(ctx context.Context, id int64) (entity.Developer, error)
+}
+
+func Create(log *slog.Logger, storage DeveloperStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.developer.Create"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		var req api.PostDeveloperCreateJSONRequestBody
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			message := "failed to decode request body"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		developer := entity.Developer{Name: strings.Join(strings.Fields(req.Name), " ")}
+		if req.Website != nil {
+			developer.Website = strings.TrimSpace(*req.Website)
+		}
+
+		if developer.Name == "" {
+			message := "name is required"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		developer, err := storage.CreateDeveloper(r.Context(), developer)
+		if errors.Is(err, stg.ErrConflict) {
+			httperr.Render(w, r, httperr.New(http.StatusConflict, httperr.CodeConflict, "developer with this name already exists"))
+			return
+		}
+		if err != nil {
+			message := "failed to add developer"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("developer added", slog.Int64("developer_id", developer.ID))
+
+		render.JSON(w, r, api.NewDeveloper(developer))
+	}
+}
+
+func Get(log *slog.Logger, storage DeveloperStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.developer.Get"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			message := "invalid id"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		developer, err := storage.GetDeveloper(r.Context(), id)
+		if err != nil {
+			message := "failed to get developer"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		render.JSON(w, r, api.NewDeveloper(developer))
+	}
+}
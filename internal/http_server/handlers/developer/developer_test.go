@@ -0,0 +1,165 @@
+package developer_test
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/handlers/developer"
+	"avito_tech/internal/http_server/handlers/developer/mocks"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedName    string
+		storageErr      error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "create developer",
+			body:           `{"name": "  Группа   ПИК ", "website": "https://pik.ru"}`,
+			expectedName:   "Группа ПИК",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "duplicate name",
+			body:            `{"name": "Группа ПИК"}`,
+			expectedName:    "Группа ПИК",
+			storageErr:      fmt.Errorf("storage.postgres.CreateDeveloper: %w", storage.ErrConflict),
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "developer with this name already exists",
+		},
+		{
+			name:            "blank name",
+			body:            `{"name": "   "}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "name is required",
+		},
+		{
+			name:            "storage error",
+			body:            `{"name": "Группа ПИК"}`,
+			expectedName:    "Группа ПИК",
+			storageErr:      fmt.Errorf("mock error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "failed to add developer",
+		},
+		{
+			name:            "failed decode",
+			body:            `{"name": 1}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "failed to decode request body",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewDeveloperStorage(t)
+			if tt.expectedName != "" {
+				storageMock.On("CreateDeveloper", mock.Anything, mock.MatchedBy(func(d entity.Developer) bool {
+					return d.Name == tt.expectedName
+				})).Return(func(_ context.Context, d entity.Developer) entity.Developer {
+					d.ID = 1
+					return d
+				}, tt.storageErr).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/developer/create", bytes.NewReader([]byte(tt.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			developer.Create(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response api.DeveloperCompany
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, 1, response.Id)
+				require.Equal(t, tt.expectedName, response.Name)
+			}
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name            string
+		id              string
+		storageErr      error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "get developer",
+			id:             "1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "not found",
+			id:              "1",
+			storageErr:      fmt.Errorf("storage.postgres.GetDeveloper: %w", storage.ErrNotFound),
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "not found",
+		},
+		{
+			name:            "invalid id",
+			id:              "abc",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid id",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewDeveloperStorage(t)
+			if tt.id == "1" {
+				storageMock.On("GetDeveloper", mock.Anything, int64(1)).
+					Return(entity.Developer{ID: 1, Name: "Группа ПИК"}, tt.storageErr).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/developer/"+tt.id, nil)
+			require.NoError(t, err)
+
+			r := chi.NewRouter()
+			r.Get("/developer/{id}", developer.Get(nil, storageMock))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
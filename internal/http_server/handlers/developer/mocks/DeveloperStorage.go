@@ -0,0 +1,86 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "avito_tech/internal/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeveloperStorage is an autogenerated mock type for the DeveloperStorage type
+type DeveloperStorage struct {
+	mock.Mock
+}
+
+// CreateDeveloper provides a mock function with given fields: ctx, _a1
+func (_m *DeveloperStorage) CreateDeveloper(ctx context.Context, _a1 entity.Developer) (entity.Developer, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDeveloper")
+	}
+
+	var r0 entity.Developer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Developer) (entity.Developer, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Developer) entity.Developer); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Get(0).(entity.Developer)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Developer) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeveloper provides a mock function with given fields: ctx, id
+func (_m *DeveloperStorage) GetDeveloper(ctx context.Context, id int64) (entity.Developer, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeveloper")
+	}
+
+	var r0 entity.Developer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (entity.Developer, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) entity.Developer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entity.Developer)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDeveloperStorage creates a new instance of DeveloperStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeveloperStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeveloperStorage {
+	mock := &DeveloperStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
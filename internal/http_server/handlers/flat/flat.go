@@ -1,114 +1,628 @@
 package flat
 
 import (
+	"avito_tech/api"
 	"avito_tech/internal/entity"
 	"avito_tech/internal/http_server/sender"
+	"avito_tech/internal/lib/etag"
+	"avito_tech/internal/lib/httperr"
 	"avito_tech/internal/lib/logger/slg"
+	"avito_tech/internal/lib/worker"
+	stg "avito_tech/internal/storage"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@latest --name=FlatStorage
 type FlatStorage interface {
-	CreateF(flat entity.Flat) (int64, error)
-	Update(flat entity.Flat, idMod uuid.UUID) error
-	GetSubscribers(houseID int64) ([]string, error)
+	CreateF(ctx context.Context, flat entity.Flat) (int64, error)
+	GetFlat(ctx context.Context, id int64) (entity.Flat, error)
+	Update(ctx context.Context, patch entity.FlatPatch, idMod uuid.UUID) (entity.Flat, error)
+	GetSubscribers(ctx context.Context, houseID int64) ([]string, error)
+	SaveNotification(ctx context.Context, n entity.Notification) error
+	PriceHistory(ctx context.Context, flatID int64) ([]entity.PricePoint, error)
+	SearchFlats(ctx context.Context, filter entity.FlatFilter) (entity.FlatPage, error)
 }
 
-func Create(log *slog.Logger, storage FlatStorage, sender *sender.Sender) http.HandlerFunc {
+func Create(log *slog.Logger, storage FlatStorage, sender *sender.Sender, workers *worker.Group) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.flat.Create"
 		reqID := middleware.GetReqID(r.Context())
 		username := r.Context().Value("username").(uuid.UUID)
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
-		var flat entity.Flat
+		var req api.PostFlatCreateJSONRequestBody
 
-		err := render.DecodeJSON(r.Body, &flat)
+		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			message := "failed to decode request body"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
 			return
 		}
 
 		log.Info("request body decoded", slog.Any("request", reqID))
 
-		flat.UserID = username
+		flat := entity.Flat{
+			UserID:  username,
+			HouseID: int64(req.HouseId),
+			Price:   int64(req.Price),
+			Rooms:   int64(req.Rooms),
+		}
+
+		if req.Number != nil {
+			flat.Number = int64(*req.Number)
+		}
+
+		// The details are optional and mapped the same way as in an update.
+		details := flatPatch(api.PostFlatUpdateJSONRequestBody{
+			Floor:         req.Floor,
+			TotalArea:     req.TotalArea,
+			LivingArea:    req.LivingArea,
+			CeilingHeight: req.CeilingHeight,
+			Balcony:       req.Balcony,
+			Renovation:    req.Renovation,
+			Amenities:     req.Amenities,
+		})
+		flat.Floor = details.Floor
+		flat.TotalArea = details.TotalArea
+		flat.LivingArea = details.LivingArea
+		flat.CeilingHeight = details.CeilingHeight
+		if details.Balcony != nil {
+			flat.Balcony = *details.Balcony
+		}
+		if details.Renovation != nil {
+			flat.Renovation = *details.Renovation
+		}
+		if details.Amenities != nil {
+			flat.Amenities = *details.Amenities
+		}
+
+		if !areasValid(flat.TotalArea, flat.LivingArea) {
+			message := "living_area must not exceed total_area"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
 
-		id, err := storage.CreateF(flat)
+		id, err := storage.CreateF(r.Context(), flat)
 		if err != nil {
 			message := "failed to add flat"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
 		log.Info("flat added", slog.Any("request", reqID))
 
-		go func() {
-			subscribers, err := storage.GetSubscribers(flat.HouseID)
-			if err != nil {
-				log.Error("failed to get subscribers", slg.Err(err))
-				return
-			}
+		flat.ID = id
+		flat.Status = "created"
+		flat.Version = 1
+
+		message := fmt.Sprintf("New flat added in house %d: Number %d, Price %d, Rooms %d, Status %s", flat.HouseID, flat.Number, flat.Price, flat.Rooms, flat.Status)
+		notifySubscribers(r.Context(), log, storage, sender, workers, flat.HouseID, message)
+
+		w.Header().Set("ETag", etag.Version(flat.Version))
+		render.JSON(w, r, api.NewFlat(flat))
+	}
+}
 
-			message := fmt.Sprintf("New flat added in house %d: Number %d, Price %d, Rooms %d, Status %s", flat.HouseID, flat.Number, flat.Price, flat.Rooms, flat.Status)
+// notifySubscribers sends message to the subscribers of the house in the
+// background and stores how each delivery went.
+func notifySubscribers(ctx context.Context, log *slog.Logger, storage FlatStorage, sender *sender.Sender, workers *worker.Group, houseID int64, message string) {
+	// Notifications outlive the request, so they keep its values (request
+	// id) but not its cancellation. Sending stops when workers shut down,
+	// storage calls still finish so nothing is lost.
+	storeCtx := context.WithoutCancel(ctx)
 
-			log.Info(message)
+	workers.Go(func(ctx context.Context) {
+		// Sending stops with the workers but stays in the request trace.
+		sendCtx, cancel := context.WithCancel(storeCtx)
+		defer cancel()
+		defer context.AfterFunc(ctx, cancel)()
 
-			for _, email := range subscribers {
-				err := sender.SendEmail(r.Context(), email, message)
-				if err != nil {
-					log.Error("failed to send email", slg.Err(err))
-				}
+		subscribers, err := storage.GetSubscribers(storeCtx, houseID)
+		if err != nil {
+			log.Error("failed to get subscribers", slg.Err(err))
+			return
+		}
 
-				log.Info("message sent by email " + email)
+		log.Info(message)
+
+		for _, email := range subscribers {
+			status := notificationStatus(sendCtx, sender, email, message, log)
+
+			err = storage.SaveNotification(storeCtx, entity.Notification{
+				HouseID: houseID,
+				Email:   email,
+				Message: message,
+				Status:  status,
+			})
+			if err != nil {
+				log.Error("failed to save notification", slg.Err(err))
 			}
-		}()
+		}
+	})
+}
 
-		flat.ID = id
-		flat.Status = "created"
-		render.JSON(w, r, flat)
+// notificationStatus sends message and reports how it went. Once ctx is
+// cancelled the message is left pending for jobs/notify to resend.
+func notificationStatus(ctx context.Context, sender *sender.Sender, email, message string, log *slog.Logger) string {
+	if ctx.Err() != nil {
+		return "pending"
+	}
+
+	err := sender.SendEmail(ctx, email, message)
+	switch {
+	case err == nil:
+		log.Info("message sent by email " + email)
+		return "sent"
+	case ctx.Err() != nil:
+		log.Info("sending interrupted, message left pending", slog.String("email", email))
+		return "pending"
+	default:
+		log.Error("failed to send email", slg.Err(err))
+		return "failed"
 	}
 }
 
-func Update(log *slog.Logger, storage FlatStorage) http.HandlerFunc {
+// Update applies the supplied fields of the request to the flat. Moderators
+// change the status, the owner everything but the house and number, see
+// forbiddenField. Subscribers of the house are
+// notified when the price of an approved flat drops by more than
+// priceDropPercent.
+func Update(log *slog.Logger, storage FlatStorage, sender *sender.Sender, workers *worker.Group, priceDropPercent float64) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.flat.Update"
 		reqID := middleware.GetReqID(r.Context())
 		username := r.Context().Value("username").(uuid.UUID)
+		role, _ := r.Context().Value("role").(string)
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			message := "If-Match is required"
+			log.Error(message)
+			httperr.Render(w, r, httperr.New(http.StatusPreconditionRequired, httperr.CodePreconditionRequired, message))
+			return
+		}
+
+		version, err := etag.ParseIfMatch(ifMatch)
+		if err != nil {
+			message := "invalid If-Match"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
 
-		var flat entity.Flat
-		err := render.DecodeJSON(r.Body, &flat)
+		var req api.PostFlatUpdateJSONRequestBody
+		err = render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			message := "failed to decode request body"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		patch := flatPatch(req)
+		patch.Version = version
+
+		if patch.Empty() {
+			message := "nothing to update"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		current, err := storage.GetFlat(r.Context(), patch.ID)
+		if err != nil {
+			message := "failed to get flat"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		if field := forbiddenField(patch, current, role == "moderator", current.UserID == username); field != "" {
+			message := "not allowed to change " + field
+			log.Error(message)
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeForbidden, message))
+			return
+		}
+
+		total, living := current.TotalArea, current.LivingArea
+		if patch.TotalArea != nil {
+			total = patch.TotalArea
+		}
+		if patch.LivingArea != nil {
+			living = patch.LivingArea
+		}
+		if !areasValid(total, living) {
+			message := "living_area must not exceed total_area"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
 			return
 		}
 
-		err = storage.Update(flat, username)
+		// The permissions were checked against this version, so even
+		// If-Match: * must not apply the patch to a later one.
+		if patch.Version == 0 {
+			patch.Version = current.Version
+		}
+
+		flat, err := storage.Update(r.Context(), patch, username)
+		if errors.Is(err, stg.ErrLocked) {
+			message := "flat is on moderation by another moderator"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.New(http.StatusConflict, httperr.CodeLocked, message))
+			return
+		}
 		if err != nil {
 			message := "failed to update flat"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
 		log.Info("flat update", slog.Any("request", reqID))
 
-		render.JSON(w, r, flat)
+		if priceDropped(current, flat, priceDropPercent) {
+			message := fmt.Sprintf("Price of flat %d in house %d dropped from %d to %d", flat.ID, flat.HouseID, current.Price, flat.Price)
+			notifySubscribers(r.Context(), log, storage, sender, workers, flat.HouseID, message)
+		}
+
+		w.Header().Set("ETag", etag.Version(flat.Version))
+		render.JSON(w, r, api.NewFlat(flat))
+	}
+}
+
+// priceDropped reports whether the price of a flat approved before and after
+// the update fell by more than percent.
+func priceDropped(before, after entity.Flat, percent float64) bool {
+	if before.Status != "approved" || after.Status != "approved" || before.Price <= 0 || after.Price >= before.Price {
+		return false
+	}
+
+	return float64(before.Price-after.Price)/float64(before.Price)*100 > percent
+}
+
+func flatPatch(req api.PostFlatUpdateJSONRequestBody) entity.FlatPatch {
+	patch := entity.FlatPatch{ID: int64(req.Id)}
+
+	optional := func(v *int) *int64 {
+		if v == nil {
+			return nil
+		}
+		i := int64(*v)
+		return &i
+	}
+
+	patch.HouseID = optional(req.HouseId)
+	patch.Number = optional(req.Number)
+	patch.Price = optional(req.Price)
+	patch.Rooms = optional(req.Rooms)
+	patch.Floor = optional(req.Floor)
+
+	patch.TotalArea = hundredths(req.TotalArea)
+	patch.LivingArea = hundredths(req.LivingArea)
+	patch.CeilingHeight = hundredths(req.CeilingHeight)
+
+	if req.Status != nil {
+		status := string(*req.Status)
+		patch.Status = &status
+	}
+	if req.Balcony != nil {
+		balcony := string(*req.Balcony)
+		patch.Balcony = &balcony
+	}
+	if req.Renovation != nil {
+		renovation := string(*req.Renovation)
+		patch.Renovation = &renovation
+	}
+
+	if req.Amenities != nil {
+		amenities := make([]string, 0, len(*req.Amenities))
+		for _, a := range *req.Amenities {
+			amenities = append(amenities, string(a))
+		}
+		patch.Amenities = &amenities
+	}
+
+	return patch
+}
+
+// hundredths rounds a measure to the two decimals it is stored with, so
+// that a response shows the stored value.
+func hundredths(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+
+	rounded := math.Round(*v*100) / 100
+	return &rounded
+}
+
+// areasValid tells whether the living area fits in the total area, an
+// unknown one fits anything.
+func areasValid(total, living *float64) bool {
+	return total == nil || living == nil || *living <= *total
+}
+
+// forbiddenField returns the first field of patch the user may not change,
+// or "" if the patch is allowed.
+//
+// The owner changes the price, rooms and descriptive fields but cannot move
+// the flat to another house or number: that would turn an approved listing
+// into a different one without moderation. Sending the current value is
+// not a change. Other owner edits keep the status, so that a price drop
+// reaches subscribers right away instead of after a new moderation.
+func forbiddenField(patch entity.FlatPatch, current entity.Flat, moderator, owner bool) string {
+	if patch.Status != nil && !moderator {
+		return "status"
+	}
+
+	if owner {
+		switch {
+		case patch.HouseID != nil && *patch.HouseID != current.HouseID:
+			return "house_id"
+		case patch.Number != nil && *patch.Number != current.Number:
+			return "number"
+		}
+
+		return ""
+	}
+
+	switch {
+	case patch.HouseID != nil:
+		return "house_id"
+	case patch.Number != nil:
+		return "number"
+	case patch.Price != nil:
+		return "price"
+	case patch.Rooms != nil:
+		return "rooms"
+	case patch.Floor != nil:
+		return "floor"
+	case patch.TotalArea != nil:
+		return "total_area"
+	case patch.LivingArea != nil:
+		return "living_area"
+	case patch.CeilingHeight != nil:
+		return "ceiling_height"
+	case patch.Balcony != nil:
+		return "balcony"
+	case patch.Renovation != nil:
+		return "renovation"
+	case patch.Amenities != nil:
+		return "amenities"
 	}
+
+	return ""
+}
+
+type ResponsePrices struct {
+	FlatID int              `json:"flat_id"`
+	Prices []api.PricePoint `json:"prices"`
+}
+
+// Prices returns the price history of a flat visible to the user: any flat
+// for moderators, approved and own flats for everybody else.
+func Prices(log *slog.Logger, storage FlatStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.flat.Prices"
+		username := r.Context().Value("username").(uuid.UUID)
+		role, _ := r.Context().Value("role").(string)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			message := "invalid id"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		flat, err := storage.GetFlat(r.Context(), id)
+		if err != nil {
+			message := "failed to get flat"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		if role != "moderator" && flat.Status != "approved" && flat.UserID != username {
+			httperr.Render(w, r, httperr.NotFound(httperr.CodeNotFound, "not found"))
+			return
+		}
+
+		prices, err := storage.PriceHistory(r.Context(), id)
+		if err != nil {
+			message := "failed to get price history"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		render.JSON(w, r, ResponsePrices{
+			FlatID: int(id),
+			Prices: api.NewPricePoints(prices),
+		})
+	}
+}
+
+const defaultSearchLimit = 20
+
+// Search finds approved flats across all houses, see /flats/search.
+func Search(log *slog.Logger, storage FlatStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.flat.Search"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		filter, err := searchFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid search", slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, err.Error()))
+			return
+		}
+
+		page, err := storage.SearchFlats(r.Context(), filter)
+		if err != nil {
+			message := "failed to search flats"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("flats found", slog.Int("count", len(page.Flats)), slog.Int64("total", page.Total))
+
+		res := api.FlatSearchResult{
+			Flats: api.NewFlats(page.Flats),
+			Total: int(page.Total),
+		}
+
+		if page.Next != nil {
+			next := encodeCursor(r.URL.Query().Get("sort"), *page.Next)
+			res.NextCursor = &next
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+func searchFilter(query url.Values) (entity.FlatFilter, error) {
+	filter := entity.FlatFilter{
+		Renovations: query["renovation"],
+		Amenities:   query["amenities"],
+		Developer:   query.Get("developer"),
+		Address:     strings.TrimSpace(query.Get("q")),
+		Sort:        strings.TrimPrefix(query.Get("sort"), "-"),
+		Desc:        strings.HasPrefix(query.Get("sort"), "-"),
+		Limit:       defaultSearchLimit,
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = "id"
+	}
+
+	bounds := []struct {
+		name  string
+		value **int64
+	}{
+		{"price_min", &filter.PriceMin},
+		{"price_max", &filter.PriceMax},
+		{"rooms_min", &filter.RoomsMin},
+		{"rooms_max", &filter.RoomsMax},
+		{"year_min", &filter.YearMin},
+		{"year_max", &filter.YearMax},
+		{"floor_min", &filter.FloorMin},
+		{"floor_max", &filter.FloorMax},
+	}
+
+	for _, b := range bounds {
+		if query.Get(b.name) == "" {
+			continue
+		}
+
+		v, err := strconv.ParseInt(query.Get(b.name), 10, 64)
+		if err != nil {
+			return entity.FlatFilter{}, fmt.Errorf("invalid %s", b.name)
+		}
+		*b.value = &v
+	}
+
+	areas := []struct {
+		name  string
+		value **float64
+	}{
+		{"area_min", &filter.AreaMin},
+		{"area_max", &filter.AreaMax},
+	}
+
+	for _, a := range areas {
+		if query.Get(a.name) == "" {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(query.Get(a.name), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return entity.FlatFilter{}, fmt.Errorf("invalid %s", a.name)
+		}
+		*a.value = &v
+	}
+
+	if hasBalcony := query.Get("has_balcony"); hasBalcony != "" {
+		v, err := strconv.ParseBool(hasBalcony)
+		if err != nil {
+			return entity.FlatFilter{}, errors.New("invalid has_balcony")
+		}
+		filter.HasBalcony = &v
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		v, err := strconv.Atoi(limit)
+		if err != nil || v < 1 {
+			return entity.FlatFilter{}, errors.New("invalid limit")
+		}
+		filter.Limit = v
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := decodeCursor(query.Get("sort"), cursor)
+		if err != nil {
+			return entity.FlatFilter{}, err
+		}
+		filter.After = &after
+	}
+
+	return filter, nil
+}
+
+// A cursor is opaque to clients: the sort it was issued for and the
+// position of the last flat, base64-encoded.
+func encodeCursor(sort string, c entity.FlatCursor) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%s|%d|%d", sort, c.Value, c.ID))
+}
+
+func decodeCursor(sort, cursor string) (entity.FlatCursor, error) {
+	errInvalid := errors.New("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return entity.FlatCursor{}, errInvalid
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return entity.FlatCursor{}, errInvalid
+	}
+
+	if parts[0] != sort {
+		return entity.FlatCursor{}, errors.New("cursor was issued for another sort")
+	}
+
+	var c entity.FlatCursor
+
+	if c.Value, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return entity.FlatCursor{}, errInvalid
+	}
+	if c.ID, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return entity.FlatCursor{}, errInvalid
+	}
+
+	return c, nil
 }
@@ -5,12 +5,16 @@ import (
 	"avito_tech/internal/http_server/handlers/flat"
 	"avito_tech/internal/http_server/handlers/flat/mocks"
 	"avito_tech/internal/http_server/sender"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/worker"
+	"avito_tech/internal/storage"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -44,7 +48,7 @@ func TestCreate(t *testing.T) {
 			expectedStatus:  http.StatusBadRequest,
 			expectedError:   fmt.Errorf("mock error"),
 			userID:          uuid.New(),
-			requestBody:     entity.User{},
+			requestBody:     []string{},
 		},
 		{
 			name:           "failed get subscribers",
@@ -70,6 +74,13 @@ func TestCreate(t *testing.T) {
 			requestBody:     entity.Flat{},
 			modeCreateFunc:  4,
 		},
+		{
+			name:           "shutting down",
+			expectedStatus: http.StatusOK,
+			userID:         uuid.New(),
+			requestBody:    entity.Flat{},
+			modeCreateFunc: 5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,42 +89,62 @@ func TestCreate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			storageMock := mocks.NewFlatStorage(t)
+			workers := worker.New()
 
 			var patches *gomonkey.Patches
 
 			switch tt.modeCreateFunc {
 			case 1:
-				storageMock.On("CreateF", entity.Flat{UserID: tt.userID}).
+				storageMock.On("CreateF", mock.Anything, entity.Flat{UserID: tt.userID}).
 					Return(int64(3), nil).Once()
 
-				storageMock.On("GetSubscribers", mock.Anything).
+				storageMock.On("GetSubscribers", mock.Anything, mock.Anything).
 					Return([]string{"subscriber1@example.com", "subscriber2@example.com"}, nil).Once()
 
+				storageMock.On("SaveNotification", mock.Anything, mock.Anything).
+					Return(nil).Maybe()
+
 			case 2:
-				storageMock.On("CreateF", entity.Flat{UserID: tt.userID}).
+				storageMock.On("CreateF", mock.Anything, entity.Flat{UserID: tt.userID}).
 					Return(int64(3), nil).Once()
 
-				storageMock.On("GetSubscribers", mock.Anything).
+				storageMock.On("GetSubscribers", mock.Anything, mock.Anything).
 					Return(nil, tt.expectedError).Once()
 
 			case 3:
-				storageMock.On("CreateF", entity.Flat{UserID: tt.userID}).
+				storageMock.On("CreateF", mock.Anything, entity.Flat{UserID: tt.userID}).
 					Return(int64(3), nil).Once()
 
-				storageMock.On("GetSubscribers", mock.Anything).
+				storageMock.On("GetSubscribers", mock.Anything, mock.Anything).
 					Return([]string{"subscriber1@example.com", "subscriber2@example.com"}, nil).Once()
 
+				storageMock.On("SaveNotification", mock.Anything, mock.Anything).
+					Return(nil).Maybe()
+
 				patches = gomonkey.ApplyMethod(reflect.TypeOf((*sender.Sender)(nil)), "SendEmail", func(s *sender.Sender, ctx context.Context, email, message string) error {
 					return errors.New("mock error")
 				})
 				defer patches.Reset()
 
 			case 4:
-				storageMock.On("CreateF", mock.Anything).
+				storageMock.On("CreateF", mock.Anything, mock.Anything).
 					Return(int64(-1), tt.expectedError).Once()
+
+			case 5:
+				storageMock.On("CreateF", mock.Anything, entity.Flat{UserID: tt.userID}).
+					Return(int64(3), nil).Once()
+
+				storageMock.On("GetSubscribers", mock.Anything, mock.Anything).
+					Return([]string{"subscriber1@example.com"}, nil).Once()
+
+				storageMock.On("SaveNotification", mock.Anything, mock.MatchedBy(func(n entity.Notification) bool {
+					return n.Status == "pending"
+				})).Return(nil).Once()
+
+				require.NoError(t, workers.Shutdown(context.Background()))
 			}
 
-			handler := flat.Create(nil, storageMock, nil)
+			handler := flat.Create(nil, storageMock, nil, workers)
 
 			input, err := json.Marshal(tt.requestBody)
 			require.NoError(t, err)
@@ -130,34 +161,189 @@ func TestCreate(t *testing.T) {
 
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
-			time.Sleep(1 * time.Second)
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_ = workers.Shutdown(shutdownCtx)
 
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
 }
 
 func TestUpdate(t *testing.T) {
+	owner := uuid.New()
+	stored := entity.Flat{ID: 1, UserID: owner, HouseID: 7, Number: 12, Price: 100, Rooms: 2, Status: "created", Version: 3}
+
 	tests := []struct {
 		name            string
 		expectedStatus  int
 		expectedMessage string
 		expectedError   error
 		userID          uuid.UUID
+		role            string
+		ifMatch         string
 		requestBody     interface{}
-		modeCreateFunc  int
+		getFlat         bool
+		update          bool
 	}{
 		{
-			name:           "Update flat",
+			name:           "moderator changes status",
 			expectedStatus: http.StatusOK,
 			userID:         uuid.New(),
-			requestBody:    entity.Flat{},
-			modeCreateFunc: 1,
+			role:           "moderator",
+			ifMatch:        `"3"`,
+			requestBody:    map[string]any{"id": 1, "status": "approved"},
+			getFlat:        true,
+			update:         true,
+		},
+		{
+			name:           "owner changes price",
+			expectedStatus: http.StatusOK,
+			userID:         owner,
+			role:           "client",
+			ifMatch:        `"3"`,
+			requestBody:    map[string]any{"id": 1, "price": 200},
+			getFlat:        true,
+			update:         true,
+		},
+		{
+			name:           "If-Match any pins the checked version",
+			expectedStatus: http.StatusOK,
+			userID:         owner,
+			role:           "client",
+			ifMatch:        "*",
+			requestBody:    map[string]any{"id": 1, "rooms": 3},
+			getFlat:        true,
+			update:         true,
+		},
+		{
+			name:           "owner sets details",
+			expectedStatus: http.StatusOK,
+			userID:         owner,
+			role:           "client",
+			ifMatch:        `"3"`,
+			requestBody:    map[string]any{"id": 1, "floor": 5, "total_area": 54.5, "amenities": []string{"internet"}},
+			getFlat:        true,
+			update:         true,
+		},
+		{
+			name:            "moderator changes amenities",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "not allowed to change amenities",
+			userID:          uuid.New(),
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "amenities": []string{}},
+			getFlat:         true,
+		},
+		{
+			name:            "owner moves flat to another house",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "not allowed to change house_id",
+			userID:          owner,
+			role:            "client",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "house_id": 8, "price": 200},
+			getFlat:         true,
+		},
+		{
+			name:            "owner changes number",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "not allowed to change number",
+			userID:          owner,
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "number": 13},
+			getFlat:         true,
+		},
+		{
+			name:           "owner sends the current house and number",
+			expectedStatus: http.StatusOK,
+			userID:         owner,
+			role:           "client",
+			ifMatch:        `"3"`,
+			requestBody:    map[string]any{"id": 1, "house_id": 7, "number": 12, "price": 200},
+			getFlat:        true,
+			update:         true,
+		},
+		{
+			name:            "living area above total",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "living_area must not exceed total_area",
+			userID:          owner,
+			role:            "client",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "total_area": 40, "living_area": 45},
+			getFlat:         true,
+		},
+		{
+			name:            "client changes status",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "not allowed to change status",
+			userID:          owner,
+			role:            "client",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
+			getFlat:         true,
+		},
+		{
+			name:            "moderator changes price of another's flat",
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "not allowed to change price",
+			userID:          uuid.New(),
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "price": 200},
+			getFlat:         true,
+		},
+		{
+			name:            "nothing to update",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "nothing to update",
+			userID:          owner,
+			role:            "client",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1},
+		},
+		{
+			name:            "flat not found",
+			expectedMessage: "not found",
+			expectedStatus:  http.StatusNotFound,
+			expectedError:   fmt.Errorf("storage.postgres.GetFlat: %w", storage.ErrNotFound),
+			userID:          uuid.New(),
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
+			getFlat:         true,
+		},
+		{
+			name:            "flat changed since If-Match",
+			expectedMessage: "version mismatch",
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedError:   fmt.Errorf("storage.postgres.Update: %w", storage.ErrVersionMismatch),
+			userID:          uuid.New(),
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
+			getFlat:         true,
+			update:          true,
+		},
+		{
+			name:            "flat on moderation",
+			expectedMessage: "flat is on moderation by another moderator",
+			expectedStatus:  http.StatusConflict,
+			expectedError:   fmt.Errorf("storage.postgres.Update: %w", storage.ErrLocked),
+			userID:          uuid.New(),
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
+			getFlat:         true,
+			update:          true,
 		},
 		{
 			name:            "Error update",
@@ -165,15 +351,36 @@ func TestUpdate(t *testing.T) {
 			expectedStatus:  http.StatusInternalServerError,
 			expectedError:   errors.New("mock error"),
 			userID:          uuid.New(),
-			requestBody:     entity.Flat{},
-			modeCreateFunc:  2,
+			role:            "moderator",
+			ifMatch:         `"3"`,
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
+			getFlat:         true,
+			update:          true,
+		},
+		{
+			name:            "no If-Match",
+			expectedMessage: "If-Match is required",
+			expectedStatus:  http.StatusPreconditionRequired,
+			userID:          uuid.New(),
+			role:            "moderator",
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
+		},
+		{
+			name:            "invalid If-Match",
+			expectedMessage: "invalid If-Match",
+			expectedStatus:  http.StatusBadRequest,
+			userID:          uuid.New(),
+			role:            "moderator",
+			ifMatch:         "3",
+			requestBody:     map[string]any{"id": 1, "status": "approved"},
 		},
 		{
 			name:           "failed decode",
-			expectedError:  fmt.Errorf("mock error"),
 			expectedStatus: http.StatusBadRequest,
 			userID:         uuid.New(),
-			requestBody:    entity.User{},
+			role:           "moderator",
+			ifMatch:        "*",
+			requestBody:    []string{},
 		},
 	}
 
@@ -181,19 +388,31 @@ func TestUpdate(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-
 			storageMock := mocks.NewFlatStorage(t)
 
-			switch tt.modeCreateFunc {
-			case 1:
-				storageMock.On("Update", mock.Anything, tt.userID).
-					Return(nil).Once()
-			case 2:
-				storageMock.On("Update", mock.Anything, mock.Anything).
-					Return(tt.expectedError).Once()
+			if tt.getFlat {
+				getErr := tt.expectedError
+				if tt.update {
+					getErr = nil
+				}
+				storageMock.On("GetFlat", mock.Anything, int64(1)).
+					Return(stored, getErr).Once()
+			}
+
+			if tt.update {
+				updated := stored
+				updated.Version++
+				if tt.expectedError != nil {
+					updated = entity.Flat{}
+				}
+
+				storageMock.On("Update", mock.Anything, mock.MatchedBy(func(p entity.FlatPatch) bool {
+					return p.ID == 1 && p.Version == stored.Version
+				}), tt.userID).
+					Return(updated, tt.expectedError).Once()
 			}
 
-			handler := flat.Update(nil, storageMock)
+			handler := flat.Update(nil, storageMock, nil, worker.New(), 10)
 
 			input, err := json.Marshal(tt.requestBody)
 			require.NoError(t, err)
@@ -201,21 +420,358 @@ func TestUpdate(t *testing.T) {
 			req, err := http.NewRequest(http.MethodPost, "/flat/update", bytes.NewReader(input))
 			require.NoError(t, err)
 
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
 			rr := httptest.NewRecorder()
 
 			ctx := context.WithValue(req.Context(), "username", tt.userID)
+			ctx = context.WithValue(ctx, "role", tt.role)
 			req = req.WithContext(ctx)
 
 			handler.ServeHTTP(rr, req)
 
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
+			if tt.expectedStatus == http.StatusOK {
+				require.Equal(t, `"4"`, rr.Header().Get("ETag"))
+
+				var response map[string]any
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.EqualValues(t, 12, response["number"], "the stored flat is returned")
+				require.EqualValues(t, 4, response["version"])
+			}
+
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestCreateDetails(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name            string
+		requestBody     map[string]any
+		expectedFlat    *entity.Flat
+		expectedStatus  int
+		expectedMessage string
+		pricePerSqm     any
+	}{
+		{
+			name: "details",
+			requestBody: map[string]any{
+				"house_id": 7, "price": 10000000, "rooms": 2,
+				"floor": 5, "total_area": 54.456, "living_area": 30, "ceiling_height": 2.7,
+				"balcony": "loggia", "renovation": "euro", "amenities": []string{"internet", "fridge"},
+			},
+			expectedFlat: &entity.Flat{
+				UserID: userID, HouseID: 7, Price: 10000000, Rooms: 2,
+				Floor: ptr(int64(5)), TotalArea: ptr(54.46), LivingArea: ptr(30.0), CeilingHeight: ptr(2.7),
+				Balcony: "loggia", Renovation: "euro", Amenities: []string{"internet", "fridge"},
+			},
+			expectedStatus: http.StatusOK,
+			pricePerSqm:    float64(183621),
+		},
+		{
+			name:           "without area",
+			requestBody:    map[string]any{"house_id": 7, "price": 100, "rooms": 1},
+			expectedFlat:   &entity.Flat{UserID: userID, HouseID: 7, Price: 100, Rooms: 1},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "living area above total",
+			requestBody:     map[string]any{"house_id": 7, "price": 100, "rooms": 1, "total_area": 40, "living_area": 45},
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "living_area must not exceed total_area",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := mocks.NewFlatStorage(t)
+			workers := worker.New()
+
+			// Stopped workers leave notifications pending without sending.
+			require.NoError(t, workers.Shutdown(context.Background()))
+
+			if tt.expectedFlat != nil {
+				storageMock.On("CreateF", mock.Anything, *tt.expectedFlat).Return(int64(3), nil).Once()
+				storageMock.On("GetSubscribers", mock.Anything, int64(7)).Return(nil, nil).Maybe()
+			}
+
+			input, err := json.Marshal(tt.requestBody)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/flat/create", bytes.NewReader(input))
+			require.NoError(t, err)
+
+			ctx := context.WithValue(req.Context(), "username", userID)
+
+			rr := httptest.NewRecorder()
+			flat.Create(nil, storageMock, nil, workers).ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			var response map[string]any
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+			if tt.expectedMessage != "" {
 				require.Equal(t, tt.expectedMessage, response["message"])
+				return
+			}
+
+			require.Equal(t, tt.pricePerSqm, response["price_per_sqm"])
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUpdatePriceDrop(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name     string
+		status   string
+		price    int64
+		notified bool
+	}{
+		{name: "drop above threshold", status: "approved", price: 80, notified: true},
+		{name: "drop below threshold", status: "approved", price: 95},
+		{name: "raise", status: "approved", price: 120},
+		{name: "not approved", status: "created", price: 50},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := mocks.NewFlatStorage(t)
+			workers := worker.New()
+
+			// Stopped workers leave notifications pending without sending.
+			require.NoError(t, workers.Shutdown(context.Background()))
+
+			stored := entity.Flat{ID: 1, UserID: owner, HouseID: 7, Number: 12, Price: 100, Rooms: 2, Status: tt.status, Version: 1}
+			updated := stored
+			updated.Price = tt.price
+			updated.Version++
+
+			storageMock.On("GetFlat", mock.Anything, int64(1)).Return(stored, nil).Once()
+			storageMock.On("Update", mock.Anything, mock.Anything, owner).Return(updated, nil).Once()
+
+			if tt.notified {
+				storageMock.On("GetSubscribers", mock.Anything, int64(7)).
+					Return([]string{"subscriber@example.com"}, nil).Once()
+				storageMock.On("SaveNotification", mock.Anything, mock.MatchedBy(func(n entity.Notification) bool {
+					return n.HouseID == 7 && n.Message == "Price of flat 1 in house 7 dropped from 100 to 80"
+				})).Return(nil).Once()
 			}
+
+			input, err := json.Marshal(map[string]any{"id": 1, "price": tt.price})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/flat/update", bytes.NewReader(input))
+			require.NoError(t, err)
+			req.Header.Set("If-Match", `"1"`)
+
+			ctx := context.WithValue(req.Context(), "username", owner)
+			ctx = context.WithValue(ctx, "role", "client")
+
+			rr := httptest.NewRecorder()
+			flat.Update(nil, storageMock, nil, workers, 10).ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_ = workers.Shutdown(shutdownCtx)
+		})
+	}
+}
+
+func TestPrices(t *testing.T) {
+	owner := uuid.New()
+	history := []entity.PricePoint{
+		{Price: 100, ChangedAt: time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)},
+		{Price: 80, ChangedAt: time.Date(2024, 8, 2, 12, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name           string
+		expectedStatus int
+		userID         uuid.UUID
+		role           string
+		flatStatus     string
+		getFlatErr     error
+		history        bool
+	}{
+		{name: "approved flat", expectedStatus: http.StatusOK, userID: uuid.New(), role: "client", flatStatus: "approved", history: true},
+		{name: "own flat", expectedStatus: http.StatusOK, userID: owner, role: "client", flatStatus: "created", history: true},
+		{name: "moderator", expectedStatus: http.StatusOK, userID: uuid.New(), role: "moderator", flatStatus: "declined", history: true},
+		{name: "another's flat not approved", expectedStatus: http.StatusNotFound, userID: uuid.New(), role: "client", flatStatus: "created"},
+		{
+			name:           "flat not found",
+			expectedStatus: http.StatusNotFound,
+			userID:         uuid.New(),
+			role:           "client",
+			getFlatErr:     fmt.Errorf("storage.postgres.GetFlat: %w", storage.ErrNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := mocks.NewFlatStorage(t)
+
+			storageMock.On("GetFlat", mock.Anything, int64(1)).
+				Return(entity.Flat{ID: 1, UserID: owner, Status: tt.flatStatus}, tt.getFlatErr).Once()
+
+			if tt.history {
+				storageMock.On("PriceHistory", mock.Anything, int64(1)).Return(history, nil).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/flat/{id}/prices", flat.Prices(nil, storageMock))
+
+			req, err := http.NewRequest(http.MethodGet, "/flat/1/prices", nil)
+			require.NoError(t, err)
+
+			ctx := context.WithValue(req.Context(), "username", tt.userID)
+			ctx = context.WithValue(ctx, "role", tt.role)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req.WithContext(ctx))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.history {
+				var response flat.ResponsePrices
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, 1, response.FlatID)
+				require.Len(t, response.Prices, 2)
+				require.Equal(t, 80, response.Prices[1].Price)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	price := int64(5000000)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedFilter *entity.FlatFilter
+		page           entity.FlatPage
+		nextCursor     bool
+	}{
+		{
+			name:           "defaults",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedFilter: &entity.FlatFilter{Sort: "id", Limit: 20},
+			page:           entity.FlatPage{Flats: []entity.Flat{{ID: 1}}, Total: 1},
+		},
+		{
+			name:           "filters and next page",
+			query:          "?price_max=5000000&developer=ПИК&q=лесная&sort=-price&limit=1",
+			expectedStatus: http.StatusOK,
+			expectedFilter: &entity.FlatFilter{PriceMax: &price, Developer: "ПИК", Address: "лесная", Sort: "price", Desc: true, Limit: 1},
+			page:           entity.FlatPage{Flats: []entity.Flat{{ID: 1}}, Total: 2, Next: &entity.FlatCursor{Value: 100, ID: 1}},
+			nextCursor:     true,
+		},
+		{
+			name:           "flat details",
+			query:          "?floor_min=2&area_min=40.5&has_balcony=true&renovation=euro&renovation=designer&amenities=internet",
+			expectedStatus: http.StatusOK,
+			expectedFilter: &entity.FlatFilter{
+				FloorMin: ptr(int64(2)), AreaMin: ptr(40.5), HasBalcony: ptr(true),
+				Renovations: []string{"euro", "designer"}, Amenities: []string{"internet"},
+				Sort: "id", Limit: 20,
+			},
+			page: entity.FlatPage{Flats: []entity.Flat{{ID: 1, Price: 5000000, TotalArea: ptr(50.0)}}, Total: 1},
+		},
+		{
+			name:           "invalid area",
+			query:          "?area_max=NaN",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid cursor",
+			query:          "?cursor=!",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := mocks.NewFlatStorage(t)
+
+			if tt.expectedFilter != nil {
+				storageMock.On("SearchFlats", mock.Anything, *tt.expectedFilter).Return(tt.page, nil).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/flats/search"+tt.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			flat.Search(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Flats      []map[string]any `json:"flats"`
+				Total      int64            `json:"total"`
+				NextCursor string           `json:"next_cursor"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Len(t, response.Flats, len(tt.page.Flats))
+			require.Equal(t, tt.page.Total, response.Total)
+			require.Equal(t, tt.nextCursor, response.NextCursor != "")
+
+			if !tt.nextCursor {
+				return
+			}
+
+			// The cursor continues the same search behind the last flat.
+			next := *tt.expectedFilter
+			next.After = tt.page.Next
+			storageMock.On("SearchFlats", mock.Anything, next).Return(entity.FlatPage{}, nil).Once()
+
+			req, err = http.NewRequest(http.MethodGet, "/flats/search"+tt.query+"&cursor="+response.NextCursor, nil)
+			require.NoError(t, err)
+
+			rr = httptest.NewRecorder()
+			flat.Search(nil, storageMock).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			// It is rejected for another sort.
+			req, err = http.NewRequest(http.MethodGet, "/flats/search?sort=price&cursor="+response.NextCursor, nil)
+			require.NoError(t, err)
+
+			rr = httptest.NewRecorder()
+			flat.Search(nil, storageMock).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
 		})
 	}
 }
@@ -1,9 +1,10 @@
-// Code generated by mockery v2.44.2. DO NOT EDIT.
+// Code generated by mockery v2.53.5. DO NOT EDIT.
 
 package mocks
 
 import (
 	entity "avito_tech/internal/entity"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -15,9 +16,9 @@ type FlatStorage struct {
 	mock.Mock
 }
 
-// CreateF provides a mock function with given fields: _a0
-func (_m *FlatStorage) CreateF(_a0 entity.Flat) (int64, error) {
-	ret := _m.Called(_a0)
+// CreateF provides a mock function with given fields: ctx, _a1
+func (_m *FlatStorage) CreateF(ctx context.Context, _a1 entity.Flat) (int64, error) {
+	ret := _m.Called(ctx, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateF")
@@ -25,17 +26,17 @@ func (_m *FlatStorage) CreateF(_a0 entity.Flat) (int64, error) {
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(entity.Flat) (int64, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Flat) (int64, error)); ok {
+		return rf(ctx, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(entity.Flat) int64); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Flat) int64); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(entity.Flat) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Flat) error); ok {
+		r1 = rf(ctx, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -43,9 +44,37 @@ func (_m *FlatStorage) CreateF(_a0 entity.Flat) (int64, error) {
 	return r0, r1
 }
 
-// GetSubscribers provides a mock function with given fields: houseID
-func (_m *FlatStorage) GetSubscribers(houseID int64) ([]string, error) {
-	ret := _m.Called(houseID)
+// GetFlat provides a mock function with given fields: ctx, id
+func (_m *FlatStorage) GetFlat(ctx context.Context, id int64) (entity.Flat, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFlat")
+	}
+
+	var r0 entity.Flat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (entity.Flat, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) entity.Flat); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entity.Flat)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscribers provides a mock function with given fields: ctx, houseID
+func (_m *FlatStorage) GetSubscribers(ctx context.Context, houseID int64) ([]string, error) {
+	ret := _m.Called(ctx, houseID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetSubscribers")
@@ -53,19 +82,19 @@ func (_m *FlatStorage) GetSubscribers(houseID int64) ([]string, error) {
 
 	var r0 []string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int64) ([]string, error)); ok {
-		return rf(houseID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]string, error)); ok {
+		return rf(ctx, houseID)
 	}
-	if rf, ok := ret.Get(0).(func(int64) []string); ok {
-		r0 = rf(houseID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = rf(ctx, houseID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(houseID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, houseID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -73,17 +102,47 @@ func (_m *FlatStorage) GetSubscribers(houseID int64) ([]string, error) {
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: _a0, idMod
-func (_m *FlatStorage) Update(_a0 entity.Flat, idMod uuid.UUID) error {
-	ret := _m.Called(_a0, idMod)
+// PriceHistory provides a mock function with given fields: ctx, flatID
+func (_m *FlatStorage) PriceHistory(ctx context.Context, flatID int64) ([]entity.PricePoint, error) {
+	ret := _m.Called(ctx, flatID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Update")
+		panic("no return value specified for PriceHistory")
+	}
+
+	var r0 []entity.PricePoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]entity.PricePoint, error)); ok {
+		return rf(ctx, flatID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []entity.PricePoint); ok {
+		r0 = rf(ctx, flatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.PricePoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, flatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveNotification provides a mock function with given fields: ctx, n
+func (_m *FlatStorage) SaveNotification(ctx context.Context, n entity.Notification) error {
+	ret := _m.Called(ctx, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveNotification")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(entity.Flat, uuid.UUID) error); ok {
-		r0 = rf(_a0, idMod)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Notification) error); ok {
+		r0 = rf(ctx, n)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -91,6 +150,62 @@ func (_m *FlatStorage) Update(_a0 entity.Flat, idMod uuid.UUID) error {
 	return r0
 }
 
+// SearchFlats provides a mock function with given fields: ctx, filter
+func (_m *FlatStorage) SearchFlats(ctx context.Context, filter entity.FlatFilter) (entity.FlatPage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchFlats")
+	}
+
+	var r0 entity.FlatPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.FlatFilter) (entity.FlatPage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.FlatFilter) entity.FlatPage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(entity.FlatPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.FlatFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, patch, idMod
+func (_m *FlatStorage) Update(ctx context.Context, patch entity.FlatPatch, idMod uuid.UUID) (entity.Flat, error) {
+	ret := _m.Called(ctx, patch, idMod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 entity.Flat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.FlatPatch, uuid.UUID) (entity.Flat, error)); ok {
+		return rf(ctx, patch, idMod)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.FlatPatch, uuid.UUID) entity.Flat); ok {
+		r0 = rf(ctx, patch, idMod)
+	} else {
+		r0 = ret.Get(0).(entity.Flat)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.FlatPatch, uuid.UUID) error); ok {
+		r1 = rf(ctx, patch, idMod)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewFlatStorage creates a new instance of FlatStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewFlatStorage(t interface {
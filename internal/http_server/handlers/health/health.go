@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+// Check is a single readiness dependency, e.g. the database.
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+type Response struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status     string  `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Live answers as long as the process serves HTTP. It never touches
+// dependencies, so a database outage does not get the container restarted.
+func Live() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, Response{Status: statusOK})
+	}
+}
+
+// Ready runs all checks concurrently, each bounded by timeout, and responds
+// 503 if any of them fails.
+func Ready(log *slog.Logger, timeout time.Duration, checks ...Check) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.health.Ready"
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		resp := Response{
+			Status: statusOK,
+			Checks: make(map[string]CheckResult, len(checks)),
+		}
+
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+
+		for _, check := range checks {
+			check := check
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				start := time.Now()
+				err := check.Fn(ctx)
+
+				result := CheckResult{
+					Status:     statusOK,
+					DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+				}
+				if err != nil {
+					result.Status = statusFail
+					result.Error = err.Error()
+				}
+
+				mu.Lock()
+				resp.Checks[check.Name] = result
+				mu.Unlock()
+			}()
+		}
+
+		wg.Wait()
+
+		for name, result := range resp.Checks {
+			if result.Status == statusFail {
+				resp.Status = statusFail
+				if log != nil {
+					log.Warn("readiness check failed", slog.String("fn", fn), slog.String("check", name), slog.String("error", result.Error))
+				}
+			}
+		}
+
+		if resp.Status == statusFail {
+			render.Status(r, http.StatusServiceUnavailable)
+		}
+
+		render.JSON(w, r, resp)
+	}
+}
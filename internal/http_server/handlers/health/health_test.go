@@ -0,0 +1,79 @@
+package health_test
+
+import (
+	"avito_tech/internal/http_server/handlers/health"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLive(t *testing.T) {
+	rr := httptest.NewRecorder()
+	health.Live().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestReady(t *testing.T) {
+	ok := health.Check{Name: "postgres", Fn: func(ctx context.Context) error { return nil }}
+	failed := health.Check{Name: "sender", Fn: func(ctx context.Context) error { return errors.New("mock error") }}
+	slow := health.Check{Name: "migrations", Fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	tests := []struct {
+		name           string
+		checks         []health.Check
+		expectedStatus int
+		expected       map[string]string
+	}{
+		{
+			name:           "all ok",
+			checks:         []health.Check{ok},
+			expectedStatus: http.StatusOK,
+			expected:       map[string]string{"postgres": "ok"},
+		},
+		{
+			name:           "one failed",
+			checks:         []health.Check{ok, failed},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected:       map[string]string{"postgres": "ok", "sender": "fail"},
+		},
+		{
+			name:           "timeout",
+			checks:         []health.Check{ok, slow},
+			expectedStatus: http.StatusServiceUnavailable,
+			expected:       map[string]string{"postgres": "ok", "migrations": "fail"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := health.Ready(nil, time.Millisecond*50, tt.checks...)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			var resp health.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+			actual := make(map[string]string, len(resp.Checks))
+			for name, result := range resp.Checks {
+				actual[name] = result.Status
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -1,88 +1,371 @@
 package house
 
 import (
+	"avito_tech/api"
 	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/address"
+	"avito_tech/internal/lib/etag"
+	"avito_tech/internal/lib/geo"
+	"avito_tech/internal/lib/httperr"
 	"avito_tech/internal/lib/logger/slg"
+	stg "avito_tech/internal/storage"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
-type ResponseCreateHouse struct {
-	Message   string       `json:"message"`
-	RequestID string       `json:"request_id"`
-	House     entity.House `json:"house"`
+type ResponseGetFlats struct {
+	Flats []api.Flat `json:"flats"`
 }
 
-type ResponseGetFlats struct {
-	Status string        `json:"status"`
-	Flat   []entity.Flat `json:"flat"`
+type ResponseNearby struct {
+	Houses []api.NearbyHouse `json:"houses"`
 }
 
+const (
+	defaultSearchLimit = 20
+	defaultBoxLimit    = 100
+	// maxRadius bounds /house/nearby in meters.
+	maxRadius = 50000
+)
+
 //go:generate go run github.com/vektra/mockery/v2@latest --name=HouseStorage
 type HouseStorage interface {
-	CreateH(house entity.House) (int64, error)
-	GetAllFlats(idHouse int64, role string) ([]entity.Flat, error)
-	Subscribe(sub entity.Subscription) error
+	CreateH(ctx context.Context, house entity.House) (int64, error)
+	GetAllFlats(ctx context.Context, idHouse int64, role string) ([]entity.Flat, error)
+	Subscribe(ctx context.Context, sub entity.Subscription) error
+	SimilarHouses(ctx context.Context, addr, postcode string) ([]entity.House, error)
+	SearchHouses(ctx context.Context, query string, limit int) ([]entity.House, error)
+	NearbyHouses(ctx context.Context, center geo.Point, radius float64, limit int) ([]entity.NearbyHouse, error)
+	HousesInBox(ctx context.Context, box geo.Box, limit int) ([]entity.House, error)
+	GetHouse(ctx context.Context, id int64) (entity.House, error)
+	UpdateHouse(ctx context.Context, patch entity.HousePatch) (entity.House, error)
+	GetDeveloper(ctx context.Context, id int64) (entity.Developer, error)
+	EnsureDeveloper(ctx context.Context, name string) (entity.Developer, error)
 }
 
 func Create(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.house.Create"
-		reqID := middleware.GetReqID(r.Context())
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
-		var req entity.House
+		var req api.PostHouseCreateJSONRequestBody
 
 		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			message := "failed to decode request body"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message, "request_id": reqID})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		house := entity.House{
+			ID:   int64(req.Id),
+			Year: int64(req.Year),
+		}
+
+		house.Address, house.Postcode = address.Normalize(req.Address)
+
+		// The developer is always one of developers, a name is resolved to
+		// it. Given both, which one is meant is unclear.
+		var developerName string
+		if req.Developer != nil {
+			developerName = strings.Join(strings.Fields(*req.Developer), " ")
+		}
+
+		if developerName != "" && req.DeveloperId != nil {
+			message := "developer and developer_id must not be set together"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		if (req.Lat == nil) != (req.Lon == nil) {
+			message := "lat and lon must be set together"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		if req.Lat != nil {
+			if !(geo.Point{Lat: *req.Lat, Lon: *req.Lon}).Valid() {
+				message := "invalid coordinates"
+				log.Error(message)
+				httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+				return
+			}
+
+			house.Lat, house.Lon = req.Lat, req.Lon
+		}
+
+		// The details are optional and mapped the same way as in an update.
+		house = applyPatch(house, housePatch(api.PostHouseUpdateJSONRequestBody{
+			DeveloperId:        req.DeveloperId,
+			Floors:             req.Floors,
+			Material:           req.Material,
+			Parking:            req.Parking,
+			Elevators:          req.Elevators,
+			ConstructionStatus: req.ConstructionStatus,
+			CompletionQuarter:  req.CompletionQuarter,
+		}))
+		if house.ConstructionStatus == "" {
+			house.ConstructionStatus = "built"
+		}
+
+		if !completionValid(house) {
+			log.Error(errCompletion)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, errCompletion))
 			return
 		}
 
-		id, err := storage.CreateH(req)
+		switch {
+		case house.DeveloperID != nil:
+			developer, ok := loadDeveloper(w, r, log, storage, *house.DeveloperID)
+			if !ok {
+				return
+			}
+			house.Developer = developer.Name
+		case developerName != "":
+			developer, err := storage.EnsureDeveloper(r.Context(), developerName)
+			if err != nil {
+				message := "failed to get developer"
+				log.Error(message, slg.Err(err))
+				httperr.Render(w, r, httperr.FromStorage(err, message))
+				return
+			}
+			house.DeveloperID, house.Developer = &developer.ID, developer.Name
+		}
+
+		// Duplicates are only reported, a moderator may still add a second
+		// house at the same address, e.g. another building of a complex.
+		var duplicates []entity.House
+		if house.Address != "" {
+			duplicates, err = storage.SimilarHouses(r.Context(), house.Address, house.Postcode)
+			if err != nil {
+				log.Warn("failed to look for duplicate houses", slg.Err(err))
+			}
+		}
+
+		id, err := storage.CreateH(r.Context(), house)
 		if err != nil {
 			message := "failed to add house"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message, "request_id": reqID})
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
-		req.ID = id
-		message := "house added"
-		log.Info(message)
+		house.ID = id
+		house.Version = 1
+		log.Info("house added", slog.Int("possible_duplicates", len(duplicates)))
 
-		render.JSON(w, r, ResponseCreateHouse{
-			Message:   message,
-			RequestID: reqID,
-			House:     req,
-		})
+		w.Header().Set("ETag", etag.Version(house.Version))
+		render.JSON(w, r, api.NewHouseCreated(house, duplicates))
+	}
+}
+
+// Update applies the supplied fields of the request to the house, see
+// /house/update.
+func Update(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.house.Update"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			message := "If-Match is required"
+			log.Error(message)
+			httperr.Render(w, r, httperr.New(http.StatusPreconditionRequired, httperr.CodePreconditionRequired, message))
+			return
+		}
+
+		version, err := etag.ParseIfMatch(ifMatch)
+		if err != nil {
+			message := "invalid If-Match"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		var req api.PostHouseUpdateJSONRequestBody
+		if err = render.DecodeJSON(r.Body, &req); err != nil {
+			message := "failed to decode request body"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		patch := housePatch(req)
+		patch.Version = version
+
+		if patch.Empty() {
+			message := "nothing to update"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		// A built house has no completion date left.
+		if patch.ConstructionStatus != nil && *patch.ConstructionStatus == "built" && patch.CompletionQuarter == nil {
+			cleared := ""
+			patch.CompletionQuarter = &cleared
+		}
+
+		current, err := storage.GetHouse(r.Context(), patch.ID)
+		if err != nil {
+			message := "failed to get house"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		if !completionValid(applyPatch(current, patch)) {
+			log.Error(errCompletion)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, errCompletion))
+			return
+		}
+
+		if patch.DeveloperID != nil {
+			if _, ok := loadDeveloper(w, r, log, storage, *patch.DeveloperID); !ok {
+				return
+			}
+		}
+
+		// The completion was checked against this version, so even
+		// If-Match: * must not apply the patch to a later one.
+		if patch.Version == 0 {
+			patch.Version = current.Version
+		}
+
+		house, err := storage.UpdateHouse(r.Context(), patch)
+		if err != nil {
+			message := "failed to update house"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("house updated", slog.Int64("house_id", house.ID))
+
+		w.Header().Set("ETag", etag.Version(house.Version))
+		render.JSON(w, r, api.NewHouse(house))
+	}
+}
+
+const errCompletion = "completion_quarter must be set for a house under construction and only for it"
+
+func housePatch(req api.PostHouseUpdateJSONRequestBody) entity.HousePatch {
+	patch := entity.HousePatch{ID: int64(req.Id)}
+
+	optional := func(v *int) *int64 {
+		if v == nil {
+			return nil
+		}
+		i := int64(*v)
+		return &i
+	}
+
+	patch.Year = optional(req.Year)
+	patch.DeveloperID = optional(req.DeveloperId)
+	patch.Floors = optional(req.Floors)
+	patch.Elevators = optional(req.Elevators)
+	patch.CompletionQuarter = req.CompletionQuarter
+
+	if req.Material != nil {
+		material := string(*req.Material)
+		patch.Material = &material
+	}
+	if req.Parking != nil {
+		parking := string(*req.Parking)
+		patch.Parking = &parking
+	}
+	if req.ConstructionStatus != nil {
+		status := string(*req.ConstructionStatus)
+		patch.ConstructionStatus = &status
+	}
+
+	return patch
+}
+
+// applyPatch returns house with the fields of patch set, the developer name
+// is left as it is.
+func applyPatch(house entity.House, patch entity.HousePatch) entity.House {
+	if patch.Year != nil {
+		house.Year = *patch.Year
+	}
+	if patch.DeveloperID != nil {
+		house.DeveloperID = patch.DeveloperID
+	}
+	if patch.Floors != nil {
+		house.Floors = patch.Floors
+	}
+	if patch.Material != nil {
+		house.Material = *patch.Material
+	}
+	if patch.Parking != nil {
+		house.Parking = *patch.Parking
+	}
+	if patch.Elevators != nil {
+		house.Elevators = patch.Elevators
+	}
+	if patch.ConstructionStatus != nil {
+		house.ConstructionStatus = *patch.ConstructionStatus
+	}
+	if patch.CompletionQuarter != nil {
+		house.CompletionQuarter = *patch.CompletionQuarter
+	}
+
+	return house
+}
+
+// completionValid tells whether a house has an expected completion quarter
+// exactly when it is under construction.
+func completionValid(house entity.House) bool {
+	return (house.ConstructionStatus == "under_construction") == (house.CompletionQuarter != "")
+}
+
+// loadDeveloper gets the developer a house is linked to. An unknown one is
+// a mistake in the request, not a missing resource.
+func loadDeveloper(w http.ResponseWriter, r *http.Request, log *slog.Logger, storage HouseStorage, id int64) (entity.Developer, bool) {
+	developer, err := storage.GetDeveloper(r.Context(), id)
+	if errors.Is(err, stg.ErrNotFound) {
+		message := "developer not found"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+		return entity.Developer{}, false
 	}
+	if err != nil {
+		message := "failed to get developer"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.FromStorage(err, message))
+		return entity.Developer{}, false
+	}
+
+	return developer, true
 }
 
 func GetAllFlats(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.house.Flats"
-		reqID := middleware.GetReqID(r.Context())
 		role := r.Context().Value("role").(string)
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
 		id := chi.URLParam(r, "id")
 		if id == "" {
 			message := "id is empty"
 			log.Error(message)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message, "request_id": reqID})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
 			return
 		}
 
@@ -91,71 +374,243 @@ func GetAllFlats(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
 		var resFlats []entity.Flat
 		var err error
 
-		resFlats, err = storage.GetAllFlats(int64(newID), role)
+		resFlats, err = storage.GetAllFlats(r.Context(), int64(newID), role)
 
 		if err != nil {
 			message := "failed to get flats"
-			log.Error(fn, slg.Err(errors.New(message)))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message, "request_id": reqID})
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
 		log.Info("got flats")
 
+		tag := listETag(resFlats)
+		w.Header().Set("ETag", tag)
+
+		if etag.NoneMatch(r.Header.Get("If-None-Match"), tag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
 		render.JSON(w, r, ResponseGetFlats{
-			Status: "Ok",
-			Flat:   resFlats,
+			Flats: api.NewFlats(resFlats),
+		})
+	}
+}
+
+func listETag(flats []entity.Flat) string {
+	ids := make([]int64, 0, len(flats))
+	versions := make([]int64, 0, len(flats))
+
+	for _, f := range flats {
+		ids = append(ids, f.ID)
+		versions = append(versions, f.Version)
+	}
+
+	return etag.List(ids, versions)
+}
+
+func Search(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.house.Search"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			message := "q is required"
+			log.Error(message)
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		limit, err := limitParam(r.URL.Query(), defaultSearchLimit)
+		if err != nil {
+			log.Error("invalid search", slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, err.Error()))
+			return
+		}
+
+		houses, err := storage.SearchHouses(r.Context(), query, limit)
+		if err != nil {
+			message := "failed to search houses"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("houses found", slog.Int("count", len(houses)))
+
+		render.JSON(w, r, api.HouseSearchResult{Houses: api.NewHouses(houses)})
+	}
+}
+
+func Nearby(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.house.Nearby"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		query := r.URL.Query()
+
+		var (
+			center geo.Point
+			radius float64
+		)
+
+		err := floatParams(query, []floatParam{{"lat", &center.Lat}, {"lon", &center.Lon}, {"radius", &radius}})
+		if err == nil && !center.Valid() {
+			err = errors.New("invalid coordinates")
+		}
+		if err == nil && (radius <= 0 || radius > maxRadius) {
+			err = fmt.Errorf("radius must be in (0, %d] meters", maxRadius)
+		}
+
+		limit, limitErr := limitParam(query, defaultSearchLimit)
+		if err == nil {
+			err = limitErr
+		}
+
+		if err != nil {
+			log.Error("invalid nearby search", slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, err.Error()))
+			return
+		}
+
+		houses, err := storage.NearbyHouses(r.Context(), center, radius, limit)
+		if err != nil {
+			message := "failed to find houses nearby"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("houses found", slog.Int("count", len(houses)))
+
+		render.JSON(w, r, ResponseNearby{Houses: api.NewNearbyHouses(houses)})
+	}
+}
+
+func Box(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.house.Box"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		query := r.URL.Query()
+
+		var box geo.Box
+
+		err := floatParams(query, []floatParam{
+			{"min_lat", &box.MinLat}, {"min_lon", &box.MinLon}, {"max_lat", &box.MaxLat}, {"max_lon", &box.MaxLon},
 		})
+		if err == nil && !box.Valid() {
+			err = errors.New("invalid bounding box")
+		}
+
+		limit, limitErr := limitParam(query, defaultBoxLimit)
+		if err == nil {
+			err = limitErr
+		}
+
+		if err != nil {
+			log.Error("invalid bounding box search", slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, err.Error()))
+			return
+		}
+
+		houses, err := storage.HousesInBox(r.Context(), box, limit)
+		if err != nil {
+			message := "failed to find houses in the box"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("houses found", slog.Int("count", len(houses)))
+
+		render.JSON(w, r, api.HouseSearchResult{Houses: api.NewHouses(houses)})
 	}
 }
 
+type floatParam struct {
+	name  string
+	value *float64
+}
+
+// floatParams parses required query parameters in order. NaN and
+// infinities are rejected.
+func floatParams(query url.Values, params []floatParam) error {
+	for _, p := range params {
+		v, err := strconv.ParseFloat(query.Get(p.name), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("invalid %s", p.name)
+		}
+		*p.value = v
+	}
+
+	return nil
+}
+
+func limitParam(query url.Values, def int) (int, error) {
+	v := query.Get("limit")
+	if v == "" {
+		return def, nil
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+
+	return limit, nil
+}
+
 func Subscribe(log *slog.Logger, storage HouseStorage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.house.Subscribe"
 		reqID := middleware.GetReqID(r.Context())
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
 		houseID := chi.URLParam(r, "id")
 		if houseID == "" {
 			message := "house_id is required"
 
 			log.Error(message)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
 			return
 		}
 
-		var sub entity.Subscription
+		var req api.PostHouseIdSubscribeJSONRequestBody
 
-		err := render.DecodeJSON(r.Body, &sub)
+		err := render.DecodeJSON(r.Body, &req)
 		if err != nil {
 			message := "failed to decode"
 
 			log.Error(message)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
 			return
 		}
 
+		sub := entity.Subscription{Email: string(req.Email)}
+
 		sub.HouseID, err = strconv.Atoi(houseID)
 		if err != nil {
 			message := "invalid house_id"
 
 			log.Error(message)
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
 			return
 		}
 
-		err = storage.Subscribe(sub)
+		err = storage.Subscribe(r.Context(), sub)
 		if err != nil {
 			message := "failed to subscribe"
 
-			log.Error(message)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
 			return
 		}
 
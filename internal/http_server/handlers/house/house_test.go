@@ -1,9 +1,14 @@
 package house_test
 
 import (
+	"avito_tech/api"
 	"avito_tech/internal/entity"
 	"avito_tech/internal/http_server/handlers/house"
 	"avito_tech/internal/http_server/handlers/house/mocks"
+	"avito_tech/internal/lib/etag"
+	"avito_tech/internal/lib/geo"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -43,6 +48,14 @@ func TestCreateH(t *testing.T) {
 			modeCreateFunc:  2,
 			requestBody:     entity.House{},
 		},
+		{
+			name:            "house exists",
+			expectedMessage: "already exists",
+			expectedStatus:  http.StatusConflict,
+			expectedError:   fmt.Errorf("storage.postgres.CreateHouse: %w", storage.ErrConflict),
+			modeCreateFunc:  2,
+			requestBody:     entity.House{},
+		},
 		{
 			name:            "failed decode",
 			expectedMessage: "failed to decode request body",
@@ -62,10 +75,10 @@ func TestCreateH(t *testing.T) {
 
 			switch tt.modeCreateFunc {
 			case 1:
-				storageMock.On("CreateH", entity.House{}).
+				storageMock.On("CreateH", mock.Anything, entity.House{ConstructionStatus: "built"}).
 					Return(int64(3), nil).Once()
 			case 2:
-				storageMock.On("CreateH", mock.Anything).
+				storageMock.On("CreateH", mock.Anything, mock.Anything).
 					Return(int64(-1), tt.expectedError).Once()
 			}
 
@@ -84,15 +97,578 @@ func TestCreateH(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestCreateHNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		duplicates []entity.House
+		similarErr error
+	}{
+		{
+			name: "no duplicates",
+		},
+		{
+			name:       "possible duplicate",
+			duplicates: []entity.House{{ID: 1, Address: "Лесная ул., 7, Москва", Postcode: "125196", Year: 2000, Version: 1}},
+		},
+		{
+			name:       "duplicate check failed",
+			similarErr: fmt.Errorf("mock error"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			normalized := entity.House{ID: 2, Address: "Лесная ул., 7, Москва", Postcode: "125196", Year: 2000, ConstructionStatus: "built"}
+
+			storageMock := mocks.NewHouseStorage(t)
+			storageMock.On("SimilarHouses", mock.Anything, normalized.Address, normalized.Postcode).
+				Return(tt.duplicates, tt.similarErr).Once()
+			storageMock.On("CreateH", mock.Anything, normalized).Return(int64(2), nil).Once()
+
+			input, err := json.Marshal(map[string]any{"id": 2, "address": " лесная  улица, 7 , Москва, 125196", "year": 2000})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/house/create", bytes.NewReader(input))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			house.Create(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response api.HouseCreated
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Equal(t, normalized.Address, response.Address)
+			require.NotNil(t, response.Postcode)
+			require.Equal(t, normalized.Postcode, *response.Postcode)
+
+			if len(tt.duplicates) == 0 {
+				require.Nil(t, response.PossibleDuplicates)
+				return
+			}
+
+			require.NotNil(t, response.PossibleDuplicates)
+			require.Equal(t, api.NewHouses(tt.duplicates), *response.PossibleDuplicates)
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedQuery  string
+		expectedLimit  int
+		expectedError  error
+	}{
+		{
+			name:           "found",
+			query:          "?q=+лесная+7+",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  "лесная 7",
+			expectedLimit:  20,
+		},
+		{
+			name:           "limit",
+			query:          "?q=лесная&limit=5",
+			expectedStatus: http.StatusOK,
+			expectedQuery:  "лесная",
+			expectedLimit:  5,
+		},
+		{
+			name:           "empty query",
+			query:          "?q=+",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			query:          "?q=лесная&limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "storage error",
+			query:          "?q=лесная",
+			expectedStatus: http.StatusInternalServerError,
+			expectedQuery:  "лесная",
+			expectedLimit:  20,
+			expectedError:  fmt.Errorf("mock error"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			found := []entity.House{{ID: 1, Address: "Лесная ул., 7, Москва", Year: 2000, Version: 1}}
+
+			storageMock := mocks.NewHouseStorage(t)
+			if tt.expectedQuery != "" {
+				storageMock.On("SearchHouses", mock.Anything, tt.expectedQuery, tt.expectedLimit).
+					Return(found, tt.expectedError).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/houses/search"+tt.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			house.Search(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response api.HouseSearchResult
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Equal(t, api.NewHouses(found), response.Houses)
+		})
+	}
+}
+
+func TestCreateHCoordinates(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "coordinates",
+			body:           `{"id": 1, "address": "", "year": 2000, "lat": 55.79, "lon": 37.54}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "lat without lon",
+			body:            `{"id": 1, "address": "", "year": 2000, "lat": 55.79}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "lat and lon must be set together",
+		},
+		{
+			name:            "out of range",
+			body:            `{"id": 1, "address": "", "year": 2000, "lat": 55.79, "lon": 200}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid coordinates",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewHouseStorage(t)
+			if tt.expectedStatus == http.StatusOK {
+				storageMock.On("CreateH", mock.Anything, mock.MatchedBy(func(h entity.House) bool {
+					return h.Lat != nil && *h.Lat == 55.79 && h.Lon != nil && *h.Lon == 37.54
+				})).Return(int64(1), nil).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/house/create", bytes.NewReader([]byte(tt.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			house.Create(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestCreateHDetails(t *testing.T) {
+	pik := entity.Developer{ID: 7, Name: "Группа ПИК"}
+
+	tests := []struct {
+		name            string
+		body            string
+		getDeveloper    bool
+		ensureDeveloper string
+		developerErr    error
+		created         func(h entity.House) bool
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:         "under construction with developer",
+			body:         `{"id": 1, "address": "", "year": 2026, "developer_id": 7, "floors": 25, "material": "monolith", "construction_status": "under_construction", "completion_quarter": "2027-Q3"}`,
+			getDeveloper: true,
+			created: func(h entity.House) bool {
+				return h.Developer == "Группа ПИК" && *h.DeveloperID == 7 && *h.Floors == 25 && h.Material == "monolith" &&
+					h.ConstructionStatus == "under_construction" && h.CompletionQuarter == "2027-Q3"
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "developer by name",
+			body:            `{"id": 1, "address": "", "year": 2000, "developer": "  группа   пик "}`,
+			ensureDeveloper: "группа пик",
+			created: func(h entity.House) bool {
+				return h.Developer == "Группа ПИК" && *h.DeveloperID == 7
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "developer and developer_id",
+			body:            `{"id": 1, "address": "", "year": 2000, "developer": "Группа ПИК", "developer_id": 7}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "developer and developer_id must not be set together",
+		},
+		{
+			name:            "under construction without quarter",
+			body:            `{"id": 1, "address": "", "year": 2026, "construction_status": "under_construction"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "completion_quarter must be set for a house under construction and only for it",
+		},
+		{
+			name:            "built with quarter",
+			body:            `{"id": 1, "address": "", "year": 2000, "completion_quarter": "2001-Q1"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "completion_quarter must be set for a house under construction and only for it",
+		},
+		{
+			name:            "unknown developer",
+			body:            `{"id": 1, "address": "", "year": 2000, "developer_id": 7}`,
+			getDeveloper:    true,
+			developerErr:    fmt.Errorf("storage.postgres.GetDeveloper: %w", storage.ErrNotFound),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "developer not found",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewHouseStorage(t)
+			if tt.getDeveloper {
+				storageMock.On("GetDeveloper", mock.Anything, int64(7)).Return(pik, tt.developerErr).Once()
+			}
+			if tt.ensureDeveloper != "" {
+				storageMock.On("EnsureDeveloper", mock.Anything, tt.ensureDeveloper).Return(pik, nil).Once()
+			}
+			if tt.created != nil {
+				storageMock.On("CreateH", mock.Anything, mock.MatchedBy(tt.created)).Return(int64(1), nil).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/house/create", bytes.NewReader([]byte(tt.body)))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			house.Create(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
 }
 
+func TestUpdate(t *testing.T) {
+	building := entity.House{ID: 1, Year: 2026, Version: 3, ConstructionStatus: "under_construction", CompletionQuarter: "2026-Q4"}
+
+	tests := []struct {
+		name            string
+		ifMatch         string
+		body            string
+		mode            int
+		updateErr       error
+		expectedPatch   func(p entity.HousePatch) bool
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "completed",
+			ifMatch:        `"3"`,
+			body:           `{"id": 1, "construction_status": "built"}`,
+			mode:           1,
+			expectedStatus: http.StatusOK,
+			expectedPatch: func(p entity.HousePatch) bool {
+				return *p.ConstructionStatus == "built" && *p.CompletionQuarter == "" && p.Version == 3
+			},
+		},
+		{
+			name:           "any version is pinned",
+			ifMatch:        "*",
+			body:           `{"id": 1, "floors": 17}`,
+			mode:           1,
+			expectedStatus: http.StatusOK,
+			expectedPatch: func(p entity.HousePatch) bool {
+				return *p.Floors == 17 && p.CompletionQuarter == nil && p.Version == 3
+			},
+		},
+		{
+			name:           "developer",
+			ifMatch:        `"3"`,
+			body:           `{"id": 1, "developer_id": 7}`,
+			mode:           2,
+			expectedStatus: http.StatusOK,
+			expectedPatch: func(p entity.HousePatch) bool {
+				return *p.DeveloperID == 7
+			},
+		},
+		{
+			name:            "unknown developer",
+			ifMatch:         `"3"`,
+			body:            `{"id": 1, "developer_id": 8}`,
+			mode:            2,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "developer not found",
+		},
+		{
+			name:            "quarter removed while under construction",
+			ifMatch:         `"3"`,
+			body:            `{"id": 1, "completion_quarter": ""}`,
+			mode:            3,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "completion_quarter must be set for a house under construction and only for it",
+		},
+		{
+			name:            "version mismatch",
+			ifMatch:         `"3"`,
+			body:            `{"id": 1, "year": 2027}`,
+			mode:            1,
+			updateErr:       fmt.Errorf("storage.postgres.UpdateHouse: %w", storage.ErrVersionMismatch),
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedMessage: "version mismatch",
+		},
+		{
+			name:            "house not found",
+			ifMatch:         `"3"`,
+			body:            `{"id": 2, "year": 2027}`,
+			mode:            4,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "not found",
+		},
+		{
+			name:            "nothing to update",
+			ifMatch:         `"3"`,
+			body:            `{"id": 1}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "nothing to update",
+		},
+		{
+			name:            "no If-Match",
+			body:            `{"id": 1, "year": 2027}`,
+			expectedStatus:  http.StatusPreconditionRequired,
+			expectedMessage: "If-Match is required",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewHouseStorage(t)
+
+			switch tt.mode {
+			case 1, 2, 3:
+				storageMock.On("GetHouse", mock.Anything, int64(1)).Return(building, nil).Once()
+			case 4:
+				storageMock.On("GetHouse", mock.Anything, int64(2)).
+					Return(entity.House{}, fmt.Errorf("storage.postgres.GetHouse: %w", storage.ErrNotFound)).Once()
+			}
+			if tt.mode == 2 {
+				storageMock.On("GetDeveloper", mock.Anything, int64(7)).Return(entity.Developer{ID: 7, Name: "ПИК"}, nil).Maybe()
+				storageMock.On("GetDeveloper", mock.Anything, int64(8)).
+					Return(entity.Developer{}, fmt.Errorf("storage.postgres.GetDeveloper: %w", storage.ErrNotFound)).Maybe()
+			}
+			switch {
+			case tt.expectedPatch != nil:
+				storageMock.On("UpdateHouse", mock.Anything, mock.MatchedBy(tt.expectedPatch)).
+					Return(entity.House{ID: 1, Version: 4}, nil).Once()
+			case tt.updateErr != nil:
+				storageMock.On("UpdateHouse", mock.Anything, mock.Anything).
+					Return(entity.House{}, tt.updateErr).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/house/update", bytes.NewReader([]byte(tt.body)))
+			require.NoError(t, err)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			rr := httptest.NewRecorder()
+			house.Update(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				require.Equal(t, etag.Version(4), rr.Header().Get("ETag"))
+			}
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedMessage, response.Message)
+			}
+		})
+	}
+}
+
+func TestNearby(t *testing.T) {
+	lat, lon := 55.79, 37.54
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedMessage string
+		expectedLimit   int
+	}{
+		{
+			name:           "found",
+			query:          "?lat=55.79&lon=37.54&radius=500",
+			expectedStatus: http.StatusOK,
+			expectedLimit:  20,
+		},
+		{
+			name:           "limit",
+			query:          "?lat=55.79&lon=37.54&radius=500&limit=3",
+			expectedStatus: http.StatusOK,
+			expectedLimit:  3,
+		},
+		{
+			name:            "missing lon",
+			query:           "?lat=55.79&radius=500",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid lon",
+		},
+		{
+			name:            "NaN",
+			query:           "?lat=NaN&lon=37.54&radius=500",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid lat",
+		},
+		{
+			name:            "out of range",
+			query:           "?lat=95&lon=37.54&radius=500",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid coordinates",
+		},
+		{
+			name:            "radius too big",
+			query:           "?lat=55.79&lon=37.54&radius=100000",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "radius must be in (0, 50000] meters",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			found := []entity.NearbyHouse{{House: entity.House{ID: 1, Address: "Лесная ул., 7", Lat: &lat, Lon: &lon, Version: 1}, Distance: 120.5}}
+
+			storageMock := mocks.NewHouseStorage(t)
+			if tt.expectedStatus == http.StatusOK {
+				storageMock.On("NearbyHouses", mock.Anything, geo.Point{Lat: lat, Lon: lon}, 500.0, tt.expectedLimit).
+					Return(found, nil).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/house/nearby"+tt.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			house.Nearby(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				var response httperr.Response
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedMessage, response.Message)
+				return
+			}
+
+			var response house.ResponseNearby
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Equal(t, api.NewNearbyHouses(found), response.Houses)
+		})
+	}
+}
+
+func TestBox(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBox    geo.Box
+	}{
+		{
+			name:           "box",
+			query:          "?min_lat=55&min_lon=37&max_lat=56&max_lon=38",
+			expectedStatus: http.StatusOK,
+			expectedBox:    geo.Box{MinLat: 55, MinLon: 37, MaxLat: 56, MaxLon: 38},
+		},
+		{
+			name:           "antimeridian",
+			query:          "?min_lat=64&min_lon=177&max_lat=65&max_lon=-178",
+			expectedStatus: http.StatusOK,
+			expectedBox:    geo.Box{MinLat: 64, MinLon: 177, MaxLat: 65, MaxLon: -178},
+		},
+		{
+			name:           "south above north",
+			query:          "?min_lat=56&min_lon=37&max_lat=55&max_lon=38",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewHouseStorage(t)
+			if tt.expectedStatus == http.StatusOK {
+				storageMock.On("HousesInBox", mock.Anything, tt.expectedBox, 100).
+					Return([]entity.House{{ID: 1}}, nil).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/house/bbox"+tt.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			house.Box(nil, storageMock).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
 func TestGetAllFlats(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -101,6 +677,7 @@ func TestGetAllFlats(t *testing.T) {
 		expectedError   error
 		id              string
 		role            string
+		ifNoneMatch     string
 		modeCreateFunc  int
 	}{
 		{
@@ -110,6 +687,22 @@ func TestGetAllFlats(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			modeCreateFunc: 1,
 		},
+		{
+			name:           "not modified",
+			id:             "1",
+			role:           "moderator",
+			ifNoneMatch:    `"other", ` + etag.List([]int64{1}, []int64{2}),
+			expectedStatus: http.StatusNotModified,
+			modeCreateFunc: 1,
+		},
+		{
+			name:           "modified",
+			id:             "1",
+			role:           "moderator",
+			ifNoneMatch:    etag.List([]int64{1}, []int64{1}),
+			expectedStatus: http.StatusOK,
+			modeCreateFunc: 1,
+		},
 		{
 			name:            "error decode request",
 			id:              "1",
@@ -139,10 +732,10 @@ func TestGetAllFlats(t *testing.T) {
 
 			switch tt.modeCreateFunc {
 			case 1:
-				storageMock.On("GetAllFlats", mock.Anything, mock.Anything).
-					Return([]entity.Flat{}, nil).Once()
+				storageMock.On("GetAllFlats", mock.Anything, mock.Anything, mock.Anything).
+					Return([]entity.Flat{{ID: 1, Version: 2}}, nil).Once()
 			case 2:
-				storageMock.On("GetAllFlats", mock.Anything, mock.Anything).
+				storageMock.On("GetAllFlats", mock.Anything, mock.Anything, mock.Anything).
 					Return(nil, tt.expectedError).Once()
 			case 3:
 
@@ -156,6 +749,7 @@ func TestGetAllFlats(t *testing.T) {
 
 			req, err := http.NewRequest(http.MethodGet, "/house/"+tt.id, nil)
 			require.NoError(t, err)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
 
 			rr := httptest.NewRecorder()
 
@@ -166,11 +760,15 @@ func TestGetAllFlats(t *testing.T) {
 
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
+			if tt.modeCreateFunc == 1 {
+				require.Equal(t, etag.List([]int64{1}, []int64{2}), rr.Header().Get("ETag"))
+			}
+
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
@@ -191,7 +789,7 @@ func TestSubscribe(t *testing.T) {
 			id:             "1",
 			expectedStatus: http.StatusOK,
 			modeCreateFunc: 1,
-			requestBody:    entity.Subscription{},
+			requestBody:    api.PostHouseIdSubscribeJSONRequestBody{Email: "user@example.com"},
 		},
 		{
 			name:            "empty id",
@@ -214,7 +812,7 @@ func TestSubscribe(t *testing.T) {
 			expectedStatus:  http.StatusBadRequest,
 			expectedMessage: "invalid house_id",
 			expectedError:   fmt.Errorf("mock error"),
-			requestBody:     entity.Subscription{},
+			requestBody:     api.PostHouseIdSubscribeJSONRequestBody{Email: "user@example.com"},
 			modeCreateFunc:  4,
 		},
 		{
@@ -223,7 +821,7 @@ func TestSubscribe(t *testing.T) {
 			expectedStatus:  http.StatusInternalServerError,
 			expectedMessage: "failed to subscribe",
 			expectedError:   fmt.Errorf("mock error"),
-			requestBody:     entity.Subscription{},
+			requestBody:     api.PostHouseIdSubscribeJSONRequestBody{Email: "user@example.com"},
 			modeCreateFunc:  2,
 		},
 	}
@@ -239,10 +837,10 @@ func TestSubscribe(t *testing.T) {
 
 			switch tt.modeCreateFunc {
 			case 1:
-				storageMock.On("Subscribe", mock.Anything).
+				storageMock.On("Subscribe", mock.Anything, mock.Anything).
 					Return(nil).Once()
 			case 2:
-				storageMock.On("Subscribe", mock.Anything).
+				storageMock.On("Subscribe", mock.Anything, mock.Anything).
 					Return(tt.expectedError).Once()
 			case 3:
 				patches = gomonkey.ApplyFunc(render.DecodeJSON, func(r io.Reader, v interface{}) error {
@@ -275,10 +873,10 @@ func TestSubscribe(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, rr.Code)
 
 			if tt.expectedMessage != "" {
-				var response map[string]string
+				var response httperr.Response
 				err = json.Unmarshal(rr.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedMessage, response["message"])
+				require.Equal(t, tt.expectedMessage, response.Message)
 			}
 		})
 	}
@@ -1,9 +1,11 @@
-// Code generated by mockery v2.44.2. DO NOT EDIT.
+// Code generated by mockery v2.53.5. DO NOT EDIT.
 
 package mocks
 
 import (
 	entity "avito_tech/internal/entity"
+	geo "avito_tech/internal/lib/geo"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,9 +15,9 @@ type HouseStorage struct {
 	mock.Mock
 }
 
-// CreateH provides a mock function with given fields: _a0
-func (_m *HouseStorage) CreateH(_a0 entity.House) (int64, error) {
-	ret := _m.Called(_a0)
+// CreateH provides a mock function with given fields: ctx, _a1
+func (_m *HouseStorage) CreateH(ctx context.Context, _a1 entity.House) (int64, error) {
+	ret := _m.Called(ctx, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateH")
@@ -23,17 +25,17 @@ func (_m *HouseStorage) CreateH(_a0 entity.House) (int64, error) {
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(entity.House) (int64, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.House) (int64, error)); ok {
+		return rf(ctx, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(entity.House) int64); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.House) int64); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(entity.House) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.House) error); ok {
+		r1 = rf(ctx, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,9 +43,37 @@ func (_m *HouseStorage) CreateH(_a0 entity.House) (int64, error) {
 	return r0, r1
 }
 
-// GetAllFlats provides a mock function with given fields: idHouse, role
-func (_m *HouseStorage) GetAllFlats(idHouse int64, role string) ([]entity.Flat, error) {
-	ret := _m.Called(idHouse, role)
+// EnsureDeveloper provides a mock function with given fields: ctx, name
+func (_m *HouseStorage) EnsureDeveloper(ctx context.Context, name string) (entity.Developer, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnsureDeveloper")
+	}
+
+	var r0 entity.Developer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (entity.Developer, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) entity.Developer); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(entity.Developer)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllFlats provides a mock function with given fields: ctx, idHouse, role
+func (_m *HouseStorage) GetAllFlats(ctx context.Context, idHouse int64, role string) ([]entity.Flat, error) {
+	ret := _m.Called(ctx, idHouse, role)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllFlats")
@@ -51,19 +81,165 @@ func (_m *HouseStorage) GetAllFlats(idHouse int64, role string) ([]entity.Flat,
 
 	var r0 []entity.Flat
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int64, string) ([]entity.Flat, error)); ok {
-		return rf(idHouse, role)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) ([]entity.Flat, error)); ok {
+		return rf(ctx, idHouse, role)
 	}
-	if rf, ok := ret.Get(0).(func(int64, string) []entity.Flat); ok {
-		r0 = rf(idHouse, role)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []entity.Flat); ok {
+		r0 = rf(ctx, idHouse, role)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entity.Flat)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
-		r1 = rf(idHouse, role)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, idHouse, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeveloper provides a mock function with given fields: ctx, id
+func (_m *HouseStorage) GetDeveloper(ctx context.Context, id int64) (entity.Developer, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeveloper")
+	}
+
+	var r0 entity.Developer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (entity.Developer, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) entity.Developer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entity.Developer)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHouse provides a mock function with given fields: ctx, id
+func (_m *HouseStorage) GetHouse(ctx context.Context, id int64) (entity.House, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHouse")
+	}
+
+	var r0 entity.House
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (entity.House, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) entity.House); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entity.House)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HousesInBox provides a mock function with given fields: ctx, box, limit
+func (_m *HouseStorage) HousesInBox(ctx context.Context, box geo.Box, limit int) ([]entity.House, error) {
+	ret := _m.Called(ctx, box, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HousesInBox")
+	}
+
+	var r0 []entity.House
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Box, int) ([]entity.House, error)); ok {
+		return rf(ctx, box, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Box, int) []entity.House); ok {
+		r0 = rf(ctx, box, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.House)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Box, int) error); ok {
+		r1 = rf(ctx, box, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NearbyHouses provides a mock function with given fields: ctx, center, radius, limit
+func (_m *HouseStorage) NearbyHouses(ctx context.Context, center geo.Point, radius float64, limit int) ([]entity.NearbyHouse, error) {
+	ret := _m.Called(ctx, center, radius, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NearbyHouses")
+	}
+
+	var r0 []entity.NearbyHouse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Point, float64, int) ([]entity.NearbyHouse, error)); ok {
+		return rf(ctx, center, radius, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, geo.Point, float64, int) []entity.NearbyHouse); ok {
+		r0 = rf(ctx, center, radius, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.NearbyHouse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, geo.Point, float64, int) error); ok {
+		r1 = rf(ctx, center, radius, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchHouses provides a mock function with given fields: ctx, query, limit
+func (_m *HouseStorage) SearchHouses(ctx context.Context, query string, limit int) ([]entity.House, error) {
+	ret := _m.Called(ctx, query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchHouses")
+	}
+
+	var r0 []entity.House
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]entity.House, error)); ok {
+		return rf(ctx, query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []entity.House); ok {
+		r0 = rf(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.House)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, query, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -71,17 +247,47 @@ func (_m *HouseStorage) GetAllFlats(idHouse int64, role string) ([]entity.Flat,
 	return r0, r1
 }
 
-// Subscribe provides a mock function with given fields: sub
-func (_m *HouseStorage) Subscribe(sub entity.Subscription) error {
-	ret := _m.Called(sub)
+// SimilarHouses provides a mock function with given fields: ctx, addr, postcode
+func (_m *HouseStorage) SimilarHouses(ctx context.Context, addr string, postcode string) ([]entity.House, error) {
+	ret := _m.Called(ctx, addr, postcode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SimilarHouses")
+	}
+
+	var r0 []entity.House
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]entity.House, error)); ok {
+		return rf(ctx, addr, postcode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []entity.House); ok {
+		r0 = rf(ctx, addr, postcode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.House)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, addr, postcode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: ctx, sub
+func (_m *HouseStorage) Subscribe(ctx context.Context, sub entity.Subscription) error {
+	ret := _m.Called(ctx, sub)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Subscribe")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(entity.Subscription) error); ok {
-		r0 = rf(sub)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Subscription) error); ok {
+		r0 = rf(ctx, sub)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -89,6 +295,34 @@ func (_m *HouseStorage) Subscribe(sub entity.Subscription) error {
 	return r0
 }
 
+// UpdateHouse provides a mock function with given fields: ctx, patch
+func (_m *HouseStorage) UpdateHouse(ctx context.Context, patch entity.HousePatch) (entity.House, error) {
+	ret := _m.Called(ctx, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateHouse")
+	}
+
+	var r0 entity.House
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.HousePatch) (entity.House, error)); ok {
+		return rf(ctx, patch)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.HousePatch) entity.House); ok {
+		r0 = rf(ctx, patch)
+	} else {
+		r0 = ret.Get(0).(entity.House)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.HousePatch) error); ok {
+		r1 = rf(ctx, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewHouseStorage creates a new instance of HouseStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewHouseStorage(t interface {
@@ -0,0 +1,201 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "avito_tech/internal/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PhotoStorage is an autogenerated mock type for the PhotoStorage type
+type PhotoStorage struct {
+	mock.Mock
+}
+
+// AddPhoto provides a mock function with given fields: ctx, _a1, limit
+func (_m *PhotoStorage) AddPhoto(ctx context.Context, _a1 entity.Photo, limit int) (entity.Photo, error) {
+	ret := _m.Called(ctx, _a1, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddPhoto")
+	}
+
+	var r0 entity.Photo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Photo, int) (entity.Photo, error)); ok {
+		return rf(ctx, _a1, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Photo, int) entity.Photo); ok {
+		r0 = rf(ctx, _a1, limit)
+	} else {
+		r0 = ret.Get(0).(entity.Photo)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Photo, int) error); ok {
+		r1 = rf(ctx, _a1, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePhoto provides a mock function with given fields: ctx, flatID, photoID
+func (_m *PhotoStorage) DeletePhoto(ctx context.Context, flatID int64, photoID int64) (entity.Photo, error) {
+	ret := _m.Called(ctx, flatID, photoID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePhoto")
+	}
+
+	var r0 entity.Photo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (entity.Photo, error)); ok {
+		return rf(ctx, flatID, photoID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) entity.Photo); ok {
+		r0 = rf(ctx, flatID, photoID)
+	} else {
+		r0 = ret.Get(0).(entity.Photo)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, flatID, photoID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FlatPhotos provides a mock function with given fields: ctx, flatID
+func (_m *PhotoStorage) FlatPhotos(ctx context.Context, flatID int64) ([]entity.Photo, error) {
+	ret := _m.Called(ctx, flatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlatPhotos")
+	}
+
+	var r0 []entity.Photo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]entity.Photo, error)); ok {
+		return rf(ctx, flatID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []entity.Photo); ok {
+		r0 = rf(ctx, flatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Photo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, flatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFlat provides a mock function with given fields: ctx, id
+func (_m *PhotoStorage) GetFlat(ctx context.Context, id int64) (entity.Flat, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFlat")
+	}
+
+	var r0 entity.Flat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (entity.Flat, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) entity.Flat); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entity.Flat)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPhoto provides a mock function with given fields: ctx, flatID, photoID
+func (_m *PhotoStorage) GetPhoto(ctx context.Context, flatID int64, photoID int64) (entity.Photo, error) {
+	ret := _m.Called(ctx, flatID, photoID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPhoto")
+	}
+
+	var r0 entity.Photo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (entity.Photo, error)); ok {
+		return rf(ctx, flatID, photoID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) entity.Photo); ok {
+		r0 = rf(ctx, flatID, photoID)
+	} else {
+		r0 = ret.Get(0).(entity.Photo)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, flatID, photoID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReorderPhotos provides a mock function with given fields: ctx, flatID, ids
+func (_m *PhotoStorage) ReorderPhotos(ctx context.Context, flatID int64, ids []int64) ([]entity.Photo, error) {
+	ret := _m.Called(ctx, flatID, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReorderPhotos")
+	}
+
+	var r0 []entity.Photo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []int64) ([]entity.Photo, error)); ok {
+		return rf(ctx, flatID, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []int64) []entity.Photo); ok {
+		r0 = rf(ctx, flatID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Photo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, []int64) error); ok {
+		r1 = rf(ctx, flatID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPhotoStorage creates a new instance of PhotoStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPhotoStorage(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PhotoStorage {
+	mock := &PhotoStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,463 @@
+package photo
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/blob"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/imaging"
+	"avito_tech/internal/lib/logger/slg"
+	stg "avito_tech/internal/storage"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// multipartOverhead is allowed on top of the photo for the other parts and
+// boundaries of an upload.
+const multipartOverhead = 64 << 10
+
+const thumbnailQuality = 80
+
+// extensions are the accepted content types and the extensions their blobs
+// are stored with.
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+//go:generate go run github.com/vektra/mockery/v2@latest --name=PhotoStorage
+type PhotoStorage interface {
+	GetFlat(ctx context.Context, id int64) (entity.Flat, error)
+	AddPhoto(ctx context.Context, photo entity.Photo, limit int) (entity.Photo, error)
+	FlatPhotos(ctx context.Context, flatID int64) ([]entity.Photo, error)
+	GetPhoto(ctx context.Context, flatID, photoID int64) (entity.Photo, error)
+	DeletePhoto(ctx context.Context, flatID, photoID int64) (entity.Photo, error)
+	ReorderPhotos(ctx context.Context, flatID int64, ids []int64) ([]entity.Photo, error)
+}
+
+// Settings bound uploads.
+type Settings struct {
+	// MaxSize is the largest photo file in bytes.
+	MaxSize int64
+	// MaxPerFlat is how many photos a flat can have.
+	MaxPerFlat int
+	// MaxPixels bounds width times height of a photo.
+	MaxPixels int
+	// ThumbnailSize is the longest side of a thumbnail in pixels.
+	ThumbnailSize int
+}
+
+func Upload(log *slog.Logger, storage PhotoStorage, blobs blob.Store, settings Settings) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.photo.Upload"
+		username := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		flat, ok := loadFlat(w, r, log, storage)
+		if !ok {
+			return
+		}
+
+		if flat.UserID != username {
+			httperr.Render(w, r, httperr.New(http.StatusForbidden, httperr.CodeForbidden, "only the owner can add photos"))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, settings.MaxSize+multipartOverhead)
+
+		data, kind, err := readUpload(r, settings.MaxSize)
+		if err != nil {
+			log.Error("invalid upload", slg.Err(err))
+			httperr.Render(w, r, uploadError(err))
+			return
+		}
+
+		contentType := http.DetectContentType(data)
+		ext, ok := extensions[contentType]
+		if !ok {
+			httperr.Render(w, r, httperr.New(http.StatusUnsupportedMediaType, httperr.CodeUnsupportedMediaType,
+				"only JPEG and PNG images are accepted"))
+			return
+		}
+
+		img, err := imaging.Decode(data, settings.MaxPixels)
+		if errors.Is(err, imaging.ErrTooLarge) {
+			httperr.Render(w, r, httperr.New(http.StatusRequestEntityTooLarge, httperr.CodePayloadTooLarge,
+				"image dimensions are too large"))
+			return
+		}
+		if err != nil {
+			httperr.Render(w, r, httperr.New(http.StatusUnsupportedMediaType, httperr.CodeUnsupportedMediaType,
+				"file is not a valid image"))
+			return
+		}
+
+		thumbnail, err := imaging.EncodeJPEG(imaging.Thumbnail(img, settings.ThumbnailSize), thumbnailQuality)
+		if err != nil {
+			message := "failed to make thumbnail"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.Internal(message))
+			return
+		}
+
+		name := uuid.NewString()
+		photo := entity.Photo{
+			FlatID:       flat.ID,
+			Kind:         kind,
+			ContentType:  contentType,
+			Size:         int64(len(data)),
+			Width:        img.Bounds().Dx(),
+			Height:       img.Bounds().Dy(),
+			Key:          fmt.Sprintf("flats/%d/%s%s", flat.ID, name, ext),
+			ThumbnailKey: fmt.Sprintf("flats/%d/%s_thumb.jpg", flat.ID, name),
+		}
+
+		// Blobs are written first: a photo in the database always has its
+		// files, and files of a failed upload are removed again.
+		saved := false
+		defer func() {
+			if !saved {
+				removeBlobs(context.WithoutCancel(r.Context()), log, blobs, photo)
+			}
+		}()
+
+		err = blobs.Put(r.Context(), photo.Key, bytes.NewReader(data), photo.Size, contentType)
+		if err == nil {
+			err = blobs.Put(r.Context(), photo.ThumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+		}
+		if err != nil {
+			message := "failed to store photo"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.Internal(message))
+			return
+		}
+
+		photo, err = storage.AddPhoto(r.Context(), photo, settings.MaxPerFlat)
+		if errors.Is(err, stg.ErrConflict) {
+			httperr.Render(w, r, httperr.New(http.StatusConflict, httperr.CodePhotoLimit,
+				fmt.Sprintf("a flat can have at most %d photos", settings.MaxPerFlat)))
+			return
+		}
+		if err != nil {
+			message := "failed to add photo"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+		saved = true
+
+		log.Info("photo added", slog.Int64("flat_id", flat.ID), slog.Int64("photo_id", photo.ID))
+
+		render.JSON(w, r, api.NewPhoto(photo))
+	}
+}
+
+func List(log *slog.Logger, storage PhotoStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.photo.List"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		flat, ok := loadFlat(w, r, log, storage)
+		if !ok {
+			return
+		}
+
+		if !visible(r, flat) {
+			httperr.Render(w, r, httperr.NotFound(httperr.CodeNotFound, "not found"))
+			return
+		}
+
+		photos, err := storage.FlatPhotos(r.Context(), flat.ID)
+		if err != nil {
+			message := "failed to get photos"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		render.JSON(w, r, api.PhotoList{Photos: api.NewPhotos(photos)})
+	}
+}
+
+// Image serves the file of a photo, or its thumbnail.
+func Image(log *slog.Logger, storage PhotoStorage, blobs blob.Store, thumbnail bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.photo.Image"
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		flat, ok := loadFlat(w, r, log, storage)
+		if !ok {
+			return
+		}
+
+		if !visible(r, flat) {
+			httperr.Render(w, r, httperr.NotFound(httperr.CodeNotFound, "not found"))
+			return
+		}
+
+		photo, ok := loadPhoto(w, r, log, storage, flat.ID)
+		if !ok {
+			return
+		}
+
+		key := photo.Key
+		if thumbnail {
+			key = photo.ThumbnailKey
+		}
+
+		obj, err := blobs.Get(r.Context(), key)
+		if err != nil {
+			message := "failed to read photo"
+			log.Error(message, slg.Err(err), slog.String("key", key))
+			httperr.Render(w, r, httperr.Internal(message))
+			return
+		}
+		defer obj.Close()
+
+		// Keys are never reused, but the flat can still be hidden later.
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+
+		if _, err = io.Copy(w, obj); err != nil {
+			log.Warn("failed to send photo", slg.Err(err))
+		}
+	}
+}
+
+func Delete(log *slog.Logger, storage PhotoStorage, blobs blob.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.photo.Delete"
+		username := r.Context().Value("username").(uuid.UUID)
+		role, _ := r.Context().Value("role").(string)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		flat, ok := loadFlat(w, r, log, storage)
+		if !ok {
+			return
+		}
+
+		// Moderators may remove photos that break the rules.
+		if flat.UserID != username && role != "moderator" {
+			httperr.Render(w, r, httperr.New(http.StatusForbidden, httperr.CodeForbidden, "only the owner or a moderator can delete photos"))
+			return
+		}
+
+		photoID, err := strconv.ParseInt(chi.URLParam(r, "photo_id"), 10, 64)
+		if err != nil {
+			message := "invalid photo_id"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+			return
+		}
+
+		photo, err := storage.DeletePhoto(r.Context(), flat.ID, photoID)
+		if err != nil {
+			message := "failed to delete photo"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		removeBlobs(r.Context(), log, blobs, photo)
+
+		log.Info("photo deleted", slog.Int64("flat_id", flat.ID), slog.Int64("photo_id", photo.ID))
+
+		photos, err := storage.FlatPhotos(r.Context(), flat.ID)
+		if err != nil {
+			message := "failed to get photos"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		render.JSON(w, r, api.PhotoList{Photos: api.NewPhotos(photos)})
+	}
+}
+
+func Reorder(log *slog.Logger, storage PhotoStorage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const fn = "handlers.photo.Reorder"
+		username := r.Context().Value("username").(uuid.UUID)
+
+		log := slg.WithLogger(r.Context(), log, fn)
+
+		flat, ok := loadFlat(w, r, log, storage)
+		if !ok {
+			return
+		}
+
+		if flat.UserID != username {
+			httperr.Render(w, r, httperr.New(http.StatusForbidden, httperr.CodeForbidden, "only the owner can reorder photos"))
+			return
+		}
+
+		var req api.PostFlatIdPhotosOrderJSONRequestBody
+
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			message := "failed to decode request body"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, message))
+			return
+		}
+
+		ids := make([]int64, 0, len(req.PhotoIds))
+		for _, id := range req.PhotoIds {
+			ids = append(ids, int64(id))
+		}
+
+		photos, err := storage.ReorderPhotos(r.Context(), flat.ID, ids)
+		if errors.Is(err, stg.ErrConstraint) {
+			httperr.Render(w, r, httperr.BadRequest(httperr.CodeConstraint, "photo_ids must list every photo of the flat once"))
+			return
+		}
+		if err != nil {
+			message := "failed to reorder photos"
+			log.Error(message, slg.Err(err))
+			httperr.Render(w, r, httperr.FromStorage(err, message))
+			return
+		}
+
+		log.Info("photos reordered", slog.Int64("flat_id", flat.ID))
+
+		render.JSON(w, r, api.PhotoList{Photos: api.NewPhotos(photos)})
+	}
+}
+
+// visible tells whether the user of r can see the photos of flat: those of
+// approved flats are public, others only for the owner and moderators.
+func visible(r *http.Request, flat entity.Flat) bool {
+	username, _ := r.Context().Value("username").(uuid.UUID)
+	role, _ := r.Context().Value("role").(string)
+
+	return role == "moderator" || flat.Status == "approved" || flat.UserID == username
+}
+
+func loadFlat(w http.ResponseWriter, r *http.Request, log *slog.Logger, storage PhotoStorage) (entity.Flat, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		message := "invalid id"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+		return entity.Flat{}, false
+	}
+
+	flat, err := storage.GetFlat(r.Context(), id)
+	if err != nil {
+		message := "failed to get flat"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.FromStorage(err, message))
+		return entity.Flat{}, false
+	}
+
+	return flat, true
+}
+
+func loadPhoto(w http.ResponseWriter, r *http.Request, log *slog.Logger, storage PhotoStorage, flatID int64) (entity.Photo, bool) {
+	photoID, err := strconv.ParseInt(chi.URLParam(r, "photo_id"), 10, 64)
+	if err != nil {
+		message := "invalid photo_id"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, message))
+		return entity.Photo{}, false
+	}
+
+	photo, err := storage.GetPhoto(r.Context(), flatID, photoID)
+	if err != nil {
+		message := "failed to get photo"
+		log.Error(message, slg.Err(err))
+		httperr.Render(w, r, httperr.FromStorage(err, message))
+		return entity.Photo{}, false
+	}
+
+	return photo, true
+}
+
+var (
+	errNoFile   = errors.New("file is required")
+	errTooLarge = errors.New("file is too large")
+	errBadKind  = errors.New("kind must be photo or floor_plan")
+)
+
+// readUpload reads the file and the kind of a multipart upload.
+func readUpload(r *http.Request, maxSize int64) (data []byte, kind string, err error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+
+	kind = string(api.PhotoKindPhoto)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch part.FormName() {
+		case "file":
+			data, err = io.ReadAll(io.LimitReader(part, maxSize+1))
+			if err != nil {
+				return nil, "", err
+			}
+			if int64(len(data)) > maxSize {
+				return nil, "", errTooLarge
+			}
+		case "kind":
+			value, err := io.ReadAll(io.LimitReader(part, 64))
+			if err != nil {
+				return nil, "", err
+			}
+			kind = string(value)
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, "", errNoFile
+	}
+
+	if kind != string(api.PhotoKindPhoto) && kind != string(api.PhotoKindFloorPlan) {
+		return nil, "", errBadKind
+	}
+
+	return data, kind, nil
+}
+
+func uploadError(err error) *httperr.Error {
+	var maxBytes *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, errTooLarge), errors.As(err, &maxBytes):
+		return httperr.New(http.StatusRequestEntityTooLarge, httperr.CodePayloadTooLarge, errTooLarge.Error())
+	case errors.Is(err, errNoFile), errors.Is(err, errBadKind):
+		return httperr.BadRequest(httperr.CodeInvalidBody, err.Error())
+	}
+
+	return httperr.BadRequest(httperr.CodeInvalidBody, "expected a multipart/form-data upload")
+}
+
+// removeBlobs deletes the files of photo. A failure only leaves an unused
+// file behind, so it is logged and not returned.
+func removeBlobs(ctx context.Context, log *slog.Logger, blobs blob.Store, photo entity.Photo) {
+	for _, key := range []string{photo.Key, photo.ThumbnailKey} {
+		if err := blobs.Delete(ctx, key); err != nil {
+			log.Warn("failed to delete photo file", slg.Err(err), slog.String("key", key))
+		}
+	}
+}
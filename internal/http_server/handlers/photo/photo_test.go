@@ -0,0 +1,534 @@
+package photo_test
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/handlers/house"
+	housemocks "avito_tech/internal/http_server/handlers/house/mocks"
+	"avito_tech/internal/http_server/handlers/photo"
+	"avito_tech/internal/http_server/handlers/photo/mocks"
+	"avito_tech/internal/lib/blob"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var settings = photo.Settings{
+	MaxSize:       1 << 20,
+	MaxPerFlat:    3,
+	MaxPixels:     1_000_000,
+	ThumbnailSize: 32,
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 200, A: 255})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func multipartBody(t *testing.T, file []byte, kind string) (io.Reader, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	if kind != "" {
+		require.NoError(t, mw.WriteField("kind", kind))
+	}
+
+	part, err := mw.CreateFormFile("file", "photo")
+	require.NoError(t, err)
+	_, err = part.Write(file)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	return &buf, mw.FormDataContentType()
+}
+
+func withUser(r *http.Request, userID uuid.UUID, role string) *http.Request {
+	ctx := context.WithValue(r.Context(), "username", userID)
+	ctx = context.WithValue(ctx, "role", role)
+
+	return r.WithContext(ctx)
+}
+
+func requireMessage(t *testing.T, rr *httptest.ResponseRecorder, message string) {
+	t.Helper()
+
+	var response httperr.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Contains(t, response.Message, message)
+}
+
+func TestUpload(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name            string
+		userID          uuid.UUID
+		file            []byte
+		kind            string
+		addErr          error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "photo",
+			userID:         owner,
+			file:           pngImage(t, 64, 48),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "floor plan",
+			userID:         owner,
+			file:           pngImage(t, 64, 48),
+			kind:           "floor_plan",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "not owner",
+			userID:          uuid.New(),
+			file:            pngImage(t, 64, 48),
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "only the owner can add photos",
+		},
+		{
+			name:            "not an image",
+			userID:          owner,
+			file:            []byte("just some text"),
+			expectedStatus:  http.StatusUnsupportedMediaType,
+			expectedMessage: "only JPEG and PNG images are accepted",
+		},
+		{
+			name:            "broken image",
+			userID:          owner,
+			file:            pngImage(t, 64, 48)[:40],
+			expectedStatus:  http.StatusUnsupportedMediaType,
+			expectedMessage: "file is not a valid image",
+		},
+		{
+			name:            "file too large",
+			userID:          owner,
+			file:            append(pngImage(t, 8, 8), make([]byte, settings.MaxSize)...),
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedMessage: "file is too large",
+		},
+		{
+			name:            "too many pixels",
+			userID:          owner,
+			file:            pngImage(t, 2000, 1000),
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedMessage: "image dimensions are too large",
+		},
+		{
+			name:            "unknown kind",
+			userID:          owner,
+			file:            pngImage(t, 64, 48),
+			kind:            "selfie",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "kind must be photo or floor_plan",
+		},
+		{
+			name:            "limit reached",
+			userID:          owner,
+			file:            pngImage(t, 64, 48),
+			addErr:          fmt.Errorf("storage.postgres.AddPhoto: %w", storage.ErrConflict),
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "a flat can have at most 3 photos",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3 := blob.NewLocalS3()
+			blobs := blob.NewS3(s3, "photos")
+
+			kind := tt.kind
+			if kind == "" {
+				kind = "photo"
+			}
+
+			storageMock := mocks.NewPhotoStorage(t)
+			storageMock.On("GetFlat", mock.Anything, int64(1)).
+				Return(entity.Flat{ID: 1, UserID: owner, Status: "created"}, nil).Once()
+
+			var stored entity.Photo
+			if tt.expectedStatus == http.StatusOK || tt.addErr != nil {
+				storageMock.On("AddPhoto", mock.Anything, mock.MatchedBy(func(p entity.Photo) bool {
+					stored = p
+					return p.FlatID == 1 && p.Kind == kind && p.ContentType == "image/png" &&
+						p.Width == 64 && p.Height == 48 &&
+						strings.HasPrefix(p.Key, "flats/1/") && strings.HasSuffix(p.ThumbnailKey, "_thumb.jpg")
+				}), settings.MaxPerFlat).
+					Return(func(_ context.Context, p entity.Photo, _ int) entity.Photo {
+						p.ID = 5
+						return p
+					}, tt.addErr).Once()
+			}
+
+			body, contentType := multipartBody(t, tt.file, tt.kind)
+
+			req, err := http.NewRequest(http.MethodPost, "/flat/1/photos", body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+
+			r := chi.NewRouter()
+			r.Post("/flat/{id}/photos", photo.Upload(nil, storageMock, blobs, settings))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withUser(req, tt.userID, "client"))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				requireMessage(t, rr, tt.expectedMessage)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				if stored.Key != "" {
+					_, err := blobs.Get(context.Background(), stored.Key)
+					require.ErrorIs(t, err, blob.ErrNotFound, "files of a failed upload are removed")
+				}
+				return
+			}
+
+			var response api.Photo
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Equal(t, 5, response.Id)
+			require.Equal(t, api.PhotoKind(kind), response.Kind)
+			require.Equal(t, "/flat/1/photos/5/thumbnail", response.ThumbnailUrl)
+
+			obj, err := blobs.Get(context.Background(), stored.Key)
+			require.NoError(t, err)
+			defer obj.Close()
+
+			data, err := io.ReadAll(obj)
+			require.NoError(t, err)
+			require.Equal(t, tt.file, data)
+
+			thumb, err := blobs.Get(context.Background(), stored.ThumbnailKey)
+			require.NoError(t, err)
+			defer thumb.Close()
+
+			cfg, format, err := image.DecodeConfig(thumb)
+			require.NoError(t, err)
+			require.Equal(t, "jpeg", format)
+			require.Equal(t, 32, cfg.Width)
+			require.Equal(t, 24, cfg.Height)
+		})
+	}
+}
+
+func TestUploadChangesHouseETag(t *testing.T) {
+	owner := uuid.New()
+
+	// The storage bumps the version of a flat whose photos change, the mock
+	// does the same.
+	flat := entity.Flat{ID: 1, HouseID: 7, UserID: owner, Status: "approved", Version: 1}
+
+	storageMock := mocks.NewPhotoStorage(t)
+	storageMock.On("GetFlat", mock.Anything, int64(1)).Return(flat, nil).Once()
+	storageMock.On("AddPhoto", mock.Anything, mock.Anything, settings.MaxPerFlat).
+		Run(func(mock.Arguments) { flat.Version++ }).
+		Return(entity.Photo{ID: 5, FlatID: 1}, nil).Once()
+
+	houseMock := housemocks.NewHouseStorage(t)
+	houseMock.On("GetAllFlats", mock.Anything, int64(7), "client").
+		Return(func(context.Context, int64, string) []entity.Flat { return []entity.Flat{flat} }, nil).Twice()
+
+	r := chi.NewRouter()
+	r.Post("/flat/{id}/photos", photo.Upload(nil, storageMock, blob.NewS3(blob.NewLocalS3(), "photos"), settings))
+	r.Get("/house/{id}", house.GetAllFlats(nil, houseMock))
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/house/7", nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, withUser(req, owner, "client"))
+		return rr
+	}
+
+	before := get("")
+	require.Equal(t, http.StatusOK, before.Code)
+
+	body, contentType := multipartBody(t, pngImage(t, 64, 48), "")
+	req, err := http.NewRequest(http.MethodPost, "/flat/1/photos", body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, withUser(req, owner, "client"))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	after := get(before.Header().Get("ETag"))
+	require.Equal(t, http.StatusOK, after.Code)
+	require.NotEqual(t, before.Header().Get("ETag"), after.Header().Get("ETag"))
+}
+
+func TestImage(t *testing.T) {
+	owner := uuid.New()
+	stored := entity.Photo{ID: 5, FlatID: 1, Key: "flats/1/a.png", ThumbnailKey: "flats/1/a_thumb.jpg"}
+
+	tests := []struct {
+		name           string
+		path           string
+		status         string
+		userID         uuid.UUID
+		role           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "approved flat",
+			path:           "/flat/1/photos/5",
+			status:         "approved",
+			userID:         uuid.New(),
+			role:           "client",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "original",
+		},
+		{
+			name:           "thumbnail",
+			path:           "/flat/1/photos/5/thumbnail",
+			status:         "approved",
+			userID:         uuid.New(),
+			role:           "client",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "thumbnail",
+		},
+		{
+			name:           "owner of a flat on moderation",
+			path:           "/flat/1/photos/5",
+			status:         "on moderation",
+			userID:         owner,
+			role:           "client",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "original",
+		},
+		{
+			name:           "moderator",
+			path:           "/flat/1/photos/5",
+			status:         "declined",
+			userID:         uuid.New(),
+			role:           "moderator",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "original",
+		},
+		{
+			name:           "hidden from others",
+			path:           "/flat/1/photos/5",
+			status:         "created",
+			userID:         uuid.New(),
+			role:           "client",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			blobs := blob.NewS3(blob.NewLocalS3(), "photos")
+			require.NoError(t, blobs.Put(context.Background(), stored.Key, strings.NewReader("original"), 8, "image/png"))
+			require.NoError(t, blobs.Put(context.Background(), stored.ThumbnailKey, strings.NewReader("thumbnail"), 9, "image/jpeg"))
+
+			storageMock := mocks.NewPhotoStorage(t)
+			storageMock.On("GetFlat", mock.Anything, int64(1)).
+				Return(entity.Flat{ID: 1, UserID: owner, Status: tt.status}, nil).Once()
+			if tt.expectedStatus == http.StatusOK {
+				storageMock.On("GetPhoto", mock.Anything, int64(1), int64(5)).Return(stored, nil).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/flat/{id}/photos/{photo_id}", photo.Image(nil, storageMock, blobs, false))
+			r.Get("/flat/{id}/photos/{photo_id}/thumbnail", photo.Image(nil, storageMock, blobs, true))
+
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withUser(req, tt.userID, tt.role))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				require.Equal(t, tt.expectedBody, rr.Body.String())
+				require.Equal(t, "private, max-age=3600", rr.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestReorder(t *testing.T) {
+	owner := uuid.New()
+
+	tests := []struct {
+		name            string
+		userID          uuid.UUID
+		reorderErr      error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:           "reordered",
+			userID:         owner,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "not every photo",
+			userID:          owner,
+			reorderErr:      fmt.Errorf("storage.postgres.ReorderPhotos: %w", storage.ErrConstraint),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "photo_ids must list every photo of the flat once",
+		},
+		{
+			name:            "not owner",
+			userID:          uuid.New(),
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "only the owner can reorder photos",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storageMock := mocks.NewPhotoStorage(t)
+			storageMock.On("GetFlat", mock.Anything, int64(1)).
+				Return(entity.Flat{ID: 1, UserID: owner, Status: "approved"}, nil).Once()
+			if tt.userID == owner {
+				storageMock.On("ReorderPhotos", mock.Anything, int64(1), []int64{6, 5}).
+					Return([]entity.Photo{{ID: 6, FlatID: 1, Position: 1}, {ID: 5, FlatID: 1, Position: 2}}, tt.reorderErr).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/flat/1/photos/order", strings.NewReader(`{"photo_ids": [6, 5]}`))
+			require.NoError(t, err)
+
+			r := chi.NewRouter()
+			r.Post("/flat/{id}/photos/order", photo.Reorder(nil, storageMock))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withUser(req, tt.userID, "client"))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			if tt.expectedMessage != "" {
+				requireMessage(t, rr, tt.expectedMessage)
+				return
+			}
+
+			var response api.PhotoList
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Len(t, response.Photos, 2)
+			require.Equal(t, 6, response.Photos[0].Id)
+			require.Equal(t, 1, response.Photos[0].Position)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	owner := uuid.New()
+	deleted := entity.Photo{ID: 5, FlatID: 1, Key: "flats/1/a.png", ThumbnailKey: "flats/1/a_thumb.jpg"}
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		role           string
+		expectedStatus int
+	}{
+		{
+			name:           "owner",
+			userID:         owner,
+			role:           "client",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "moderator",
+			userID:         uuid.New(),
+			role:           "moderator",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "someone else",
+			userID:         uuid.New(),
+			role:           "client",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			blobs := blob.NewS3(blob.NewLocalS3(), "photos")
+			require.NoError(t, blobs.Put(context.Background(), deleted.Key, strings.NewReader("original"), 8, "image/png"))
+			require.NoError(t, blobs.Put(context.Background(), deleted.ThumbnailKey, strings.NewReader("thumbnail"), 9, "image/jpeg"))
+
+			storageMock := mocks.NewPhotoStorage(t)
+			storageMock.On("GetFlat", mock.Anything, int64(1)).
+				Return(entity.Flat{ID: 1, UserID: owner, Status: "approved"}, nil).Once()
+			if tt.expectedStatus == http.StatusOK {
+				storageMock.On("DeletePhoto", mock.Anything, int64(1), int64(5)).Return(deleted, nil).Once()
+				storageMock.On("FlatPhotos", mock.Anything, int64(1)).
+					Return([]entity.Photo{{ID: 6, FlatID: 1, Position: 1}}, nil).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodDelete, "/flat/1/photos/5", nil)
+			require.NoError(t, err)
+
+			r := chi.NewRouter()
+			r.Delete("/flat/{id}/photos/{photo_id}", photo.Delete(nil, storageMock, blobs))
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, withUser(req, tt.userID, tt.role))
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+
+			for _, key := range []string{deleted.Key, deleted.ThumbnailKey} {
+				obj, err := blobs.Get(context.Background(), key)
+				if tt.expectedStatus == http.StatusOK {
+					require.ErrorIs(t, err, blob.ErrNotFound)
+					continue
+				}
+				require.NoError(t, err)
+				obj.Close()
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package auth
 
 import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/middleware/logger"
+	"avito_tech/internal/lib/httperr"
 	"avito_tech/internal/lib/logger/slg"
+	"avito_tech/internal/storage"
 	"context"
+	"errors"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/render"
 	"github.com/google/uuid"
 	"log/slog"
 	"net/http"
@@ -15,18 +18,24 @@ import (
 
 var MySigningKey = []byte(os.Getenv("MY_SIGNING_KEY"))
 
-func JWTAuth(log *slog.Logger, next http.Handler) http.HandlerFunc {
+type SessionProvider interface {
+	SessionUser(ctx context.Context, sessionID, userID uuid.UUID) (entity.User, error)
+}
+
+// JWTAuth lets a request through only with a token of a live session: the
+// session must be neither revoked nor expired and its user active. The role
+// is taken from the user, not from the token, so a role change applies
+// right away.
+func JWTAuth(log *slog.Logger, sessions SessionProvider, next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const fn = "handlers.auth.JWTModerator"
-		reqID := middleware.GetReqID(r.Context())
 
-		log = slg.WithLogger(fn, reqID)
+		log := slg.WithLogger(r.Context(), log, fn)
 
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			log.Error("Unauthorized")
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"message": "Unauthorized"})
+			httperr.Render(w, r, httperr.Unauthorized(httperr.CodeUnauthorized, "Unauthorized"))
 			return
 		}
 
@@ -38,56 +47,82 @@ func JWTAuth(log *slog.Logger, next http.Handler) http.HandlerFunc {
 		if err != nil {
 			message := "failed check sing token"
 			log.Error(message, slg.Err(err))
-			render.Status(r, http.StatusForbidden)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeInvalidToken, message))
 			return
 		}
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			role, okRole := claims["role"].(string)
 			usernameString, okName := claims["username"].(string)
+			sessionString, okSession := claims["sid"].(string)
+
+			username, errName := uuid.Parse(usernameString)
+			sessionID, errSession := uuid.Parse(sessionString)
 
-			if !okRole || !okName {
+			if !okName || !okSession || errName != nil || errSession != nil {
 				log.Error("Forbidden")
-				render.Status(r, http.StatusForbidden)
-				render.JSON(w, r, map[string]string{"message": "Forbidden"})
+				httperr.Render(w, r, httperr.Forbidden(httperr.CodeInvalidToken, "Forbidden"))
+				return
+			}
+
+			user, err := sessions.SessionUser(r.Context(), sessionID, username)
+			if errors.Is(err, storage.ErrNotFound) {
+				message := "session is revoked or expired"
+				log.Error(message)
+				httperr.Render(w, r, httperr.Unauthorized(httperr.CodeInvalidToken, message))
+				return
+			}
+			if err != nil {
+				log.Error("failed to get session", slg.Err(err))
+				httperr.Render(w, r, httperr.FromStorage(err, "failed to get session"))
 				return
 			}
 
-			username, _ := uuid.Parse(usernameString)
+			if user.Status != "active" {
+				message := "user is " + user.Status
+				log.Error(message)
+				httperr.Render(w, r, httperr.Forbidden(httperr.CodeUserInactive, message))
+				return
+			}
+
+			role := user.UserType
+
+			logger.SetUser(r.Context(), username, role)
 
 			ctx := context.WithValue(r.Context(), "role", role)
 			ctx = context.WithValue(ctx, "username", username)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		} else {
 			log.Error("message", slg.Err(err))
-			render.Status(r, http.StatusUnauthorized)
-			render.JSON(w, r, map[string]string{"message": "Unauthorized"})
+			httperr.Render(w, r, httperr.Unauthorized(httperr.CodeInvalidToken, "Unauthorized"))
 		}
 	}
 }
 
 func RequireModerator(log *slog.Logger, next http.Handler) http.HandlerFunc {
+	return requireRole(log, "handlers.auth.RequireModerator", "moderator", next)
+}
+
+func RequireAdmin(log *slog.Logger, next http.Handler) http.HandlerFunc {
+	return requireRole(log, "handlers.auth.RequireAdmin", "admin", next)
+}
+
+func requireRole(log *slog.Logger, fn, required string, next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const fn = "handlers.auth.RequireModerator"
-		reqID := middleware.GetReqID(r.Context())
 		role, ok := r.Context().Value("role").(string)
 
+		log := slg.WithLogger(r.Context(), log, fn)
+
 		if !ok {
 			message := "failed to get role"
-			log.Error(message, fn)
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, map[string]string{"message": message})
+			log.Error(message)
+			httperr.Render(w, r, httperr.Internal(message))
 			return
 		}
 
-		log = slg.WithLogger(fn, reqID)
-
-		if role != "moderator" {
+		if role != required {
 			message := "Forbidden"
 			log.Error(message)
-			render.Status(r, http.StatusForbidden)
-			render.JSON(w, r, map[string]string{"message": message})
+			httperr.Render(w, r, httperr.Forbidden(httperr.CodeForbidden, message))
 			return
 		}
 
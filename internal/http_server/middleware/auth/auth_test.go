@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sessionStore knows the live sessions, anything else is revoked or expired.
+type sessionStore map[uuid.UUID]entity.User
+
+func (s sessionStore) SessionUser(_ context.Context, sessionID, userID uuid.UUID) (entity.User, error) {
+	user, ok := s[sessionID]
+	if !ok || user.ID != userID {
+		return entity.User{}, fmt.Errorf("storage.postgres.SessionUser: %w", storage.ErrNotFound)
+	}
+
+	return user, nil
+}
+
+func TestJWTAuth(t *testing.T) {
+	userID := uuid.New()
+	live, revoked, suspended := uuid.New(), uuid.New(), uuid.New()
+
+	sessions := sessionStore{
+		live:      {ID: userID, UserType: "client", Status: "active"},
+		suspended: {ID: userID, UserType: "client", Status: "suspended"},
+	}
+
+	token := func(claims jwt.MapClaims) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(MySigningKey)
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+		expectedRole   string
+	}{
+		{
+			name:           "live session",
+			token:          token(jwt.MapClaims{"username": userID.String(), "sid": live.String(), "role": "client"}),
+			expectedStatus: http.StatusOK,
+			expectedRole:   "client",
+		},
+		{
+			name:           "role is taken from the user",
+			token:          token(jwt.MapClaims{"username": userID.String(), "sid": live.String(), "role": "moderator"}),
+			expectedStatus: http.StatusOK,
+			expectedRole:   "client",
+		},
+		{
+			name:           "revoked session",
+			token:          token(jwt.MapClaims{"username": userID.String(), "sid": revoked.String(), "role": "client"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "session of another user",
+			token:          token(jwt.MapClaims{"username": uuid.NewString(), "sid": live.String(), "role": "client"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "suspended user",
+			token:          token(jwt.MapClaims{"username": userID.String(), "sid": suspended.String(), "role": "client"}),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no session id",
+			token:          token(jwt.MapClaims{"username": userID.String(), "role": "client"}),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var role string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				role, _ = r.Context().Value("role").(string)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/house/1", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			rr := httptest.NewRecorder()
+			JWTAuth(nil, sessions, next).ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			require.Equal(t, tt.expectedRole, role)
+		})
+	}
+}
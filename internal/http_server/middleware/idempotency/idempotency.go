@@ -0,0 +1,175 @@
+package idempotency
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	"avito_tech/internal/storage"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// Header is the request header with the key a client picks for all
+	// attempts of one operation.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from the store.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+type Store interface {
+	ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, ttl, lease time.Duration) (entity.IdempotentResponse, bool, error)
+	SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, resp entity.IdempotentResponse) error
+	ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservedAt time.Time) error
+}
+
+// New makes requests with an Idempotency-Key safe to retry for ttl: the
+// first response is stored and replayed for retries with the same request,
+// a retry while the first attempt is in flight gets 409 and a different
+// request under the same key 422. A request that never stored a response,
+// e.g. because its replica crashed, holds the key for lease only, after
+// which a retry takes it over. Keys are per user, so mount it after
+// JWTAuth. Requests without the header are not affected.
+func New(log *slog.Logger, store Store, ttl, lease time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const fn = "middleware.idempotency"
+
+			key := r.Header.Get(Header)
+			userID, ok := r.Context().Value("username").(uuid.UUID)
+			if key == "" || !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := slg.WithLogger(r.Context(), log, fn)
+
+			if len(key) > maxKeyLength {
+				httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, "Idempotency-Key is too long"))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidBody, "failed to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			fp := fingerprint(r.Method, r.URL.Path, body)
+
+			stored, reserved, err := store.ReserveIdempotencyKey(r.Context(), userID, key, fp, ttl, lease)
+			if errors.Is(err, storage.ErrNotFound) {
+				httperr.Render(w, r, inProgress())
+				return
+			}
+			if err != nil {
+				log.Error("failed to reserve idempotency key", slg.Err(err))
+				httperr.Render(w, r, httperr.FromStorage(err, "failed to reserve idempotency key"))
+				return
+			}
+
+			if !reserved {
+				switch {
+				case stored.Fingerprint != fp:
+					httperr.Render(w, r, httperr.New(http.StatusUnprocessableEntity, httperr.CodeIdempotencyMismatch,
+						"Idempotency-Key was used for a different request"))
+				case stored.Status == 0:
+					httperr.Render(w, r, inProgress())
+				default:
+					replay(w, stored)
+				}
+				return
+			}
+
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			// The key is released if the handler panics or fails with a
+			// 5xx, so that a retry runs again.
+			done := false
+			defer func() {
+				ctx := context.WithoutCancel(r.Context())
+
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+
+				if done && status < http.StatusInternalServerError {
+					err := store.SaveIdempotentResponse(ctx, userID, key, entity.IdempotentResponse{
+						Fingerprint: fp,
+						Status:      status,
+						ContentType: ww.Header().Get("Content-Type"),
+						ETag:        ww.Header().Get("ETag"),
+						Body:        buf.Bytes(),
+						CreatedAt:   stored.CreatedAt,
+					})
+					if err == nil {
+						return
+					}
+					log.Error("failed to save idempotent response", slg.Err(err))
+				}
+
+				if err := store.ReleaseIdempotencyKey(ctx, userID, key, stored.CreatedAt); err != nil {
+					log.Error("failed to release idempotency key", slg.Err(err))
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+			done = true
+		})
+	}
+}
+
+func inProgress() *httperr.Error {
+	e := httperr.New(http.StatusConflict, httperr.CodeIdempotencyInProgress, "a request with this Idempotency-Key is in progress")
+	e.RetryAfter = time.Second
+
+	return e
+}
+
+func replay(w http.ResponseWriter, stored entity.IdempotentResponse) {
+	if stored.ContentType != "" {
+		w.Header().Set("Content-Type", stored.ContentType)
+	}
+	if stored.ETag != "" {
+		w.Header().Set("ETag", stored.ETag)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// fingerprint identifies a request by route and body. JSON bodies are
+// compared after re-encoding, so key order and whitespace do not matter.
+func fingerprint(method, path string, body []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v any
+	if dec.Decode(&v) == nil && !dec.More() {
+		if canonical, err := json.Marshal(v); err == nil {
+			body = canonical
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
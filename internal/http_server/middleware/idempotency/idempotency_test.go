@@ -0,0 +1,185 @@
+package idempotency
+
+import (
+	"avito_tech/internal/entity"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore is the subset of postgres semantics the middleware relies on.
+type memoryStore struct {
+	mu        sync.Mutex
+	responses map[string]entity.IdempotentResponse
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{responses: make(map[string]entity.IdempotentResponse)}
+}
+
+func (s *memoryStore) ReserveIdempotencyKey(_ context.Context, userID uuid.UUID, key, fingerprint string, _, lease time.Duration) (entity.IdempotentResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp, ok := s.responses[userID.String()+key]; ok && (resp.Status != 0 || time.Since(resp.CreatedAt) < lease) {
+		return resp, false, nil
+	}
+
+	resp := entity.IdempotentResponse{Fingerprint: fingerprint, CreatedAt: time.Now()}
+	s.responses[userID.String()+key] = resp
+
+	return resp, true, nil
+}
+
+func (s *memoryStore) SaveIdempotentResponse(_ context.Context, userID uuid.UUID, key string, resp entity.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.responses[userID.String()+key]; ok && current.Status == 0 && current.CreatedAt.Equal(resp.CreatedAt) {
+		s.responses[userID.String()+key] = resp
+	}
+
+	return nil
+}
+
+func (s *memoryStore) ReleaseIdempotencyKey(_ context.Context, userID uuid.UUID, key string, reservedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.responses[userID.String()+key]; ok && current.Status == 0 && current.CreatedAt.Equal(reservedAt) {
+		delete(s.responses, userID.String()+key)
+	}
+
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	userID := uuid.New()
+
+	request := func(key, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/flat/create", strings.NewReader(body))
+		r.Header.Set(Header, key)
+
+		return r.WithContext(context.WithValue(r.Context(), "username", userID))
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		calls := 0
+		handler := New(log, newMemoryStore(), time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			body, _ := io.ReadAll(r.Body)
+			require.JSONEq(t, `{"house_id": 1, "price": 100}`, string(body))
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"1"`)
+			w.Write([]byte(`{"id": 7}`))
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, request("k", `{"house_id": 1, "price": 100}`))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get(ReplayedHeader))
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, request("k", `{"price":100,"house_id":1}`))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "true", rec.Header().Get(ReplayedHeader))
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		require.Equal(t, `"1"`, rec.Header().Get("ETag"))
+		require.Equal(t, `{"id": 7}`, rec.Body.String())
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		handler := New(log, newMemoryStore(), time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), request("k", `{"price": 100}`))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, request("k", `{"price": 200}`))
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		store := newMemoryStore()
+		started, release := make(chan struct{}), make(chan struct{})
+
+		handler := New(log, store, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), request("k", `{}`))
+		}()
+		<-started
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, request("k", `{}`))
+		require.Equal(t, http.StatusConflict, rec.Code)
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+		close(release)
+		<-done
+	})
+
+	t.Run("expired lease is taken over", func(t *testing.T) {
+		store := newMemoryStore()
+		// Reserved by a request that crashed before saving or releasing.
+		store.responses[userID.String()+"k"] = entity.IdempotentResponse{
+			Fingerprint: fingerprint(http.MethodPost, "/flat/create", []byte(`{}`)),
+			CreatedAt:   time.Now().Add(-time.Hour),
+		}
+
+		calls := 0
+		handler := New(log, store, 24*time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, request("k", `{}`))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, 1, calls)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, request("k", `{}`))
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, "true", rec.Header().Get(ReplayedHeader))
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("server error releases key", func(t *testing.T) {
+		calls := 0
+		handler := New(log, newMemoryStore(), time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), request("k", `{}`))
+		handler.ServeHTTP(httptest.NewRecorder(), request("k", `{}`))
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		calls := 0
+		handler := New(log, newMemoryStore(), time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), request("", `{}`))
+		handler.ServeHTTP(httptest.NewRecorder(), request("", `{}`))
+		require.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type ctxKey struct{}
+
+// user is filled in by the auth middleware deeper in the chain, which only
+// sees a derived context, so the access log reads it through a pointer.
+type user struct {
+	id   uuid.UUID
+	role string
+}
+
+// SetUser records the authenticated user of the request for the access log.
+// It does nothing outside of New.
+func SetUser(ctx context.Context, id uuid.UUID, role string) {
+	if u, ok := ctx.Value(ctxKey{}).(*user); ok {
+		u.id = id
+		u.role = role
+	}
+}
+
+// New derives a request-scoped logger from log, tagged with the request id
+// and trace, for the handlers to pick up with slg.WithLogger, and writes one
+// access-log line per request. Mount it after RequestID and tracing.
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			scoped := log
+			for _, attr := range slg.RequestAttrs(r.Context()) {
+				scoped = scoped.With(attr)
+			}
+
+			u := &user{}
+			ctx := context.WithValue(slg.NewContext(r.Context(), scoped), ctxKey{}, u)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if u.id != uuid.Nil {
+				attrs = append(attrs, slog.String("user_id", u.id.String()), slog.String("role", u.role))
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+
+			scoped.LogAttrs(ctx, level, "request completed", attrs...)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package logger_test
+
+import (
+	"avito_tech/internal/http_server/middleware/logger"
+	"avito_tech/internal/lib/logger/slg"
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	userID := uuid.New()
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(logger.New(log))
+	router.Get("/house/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.SetUser(r.Context(), userID, "client")
+		slg.WithLogger(r.Context(), nil, "handlers.house.Flats").Info("flats found")
+
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"not found"}`))
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/house/42", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var handlerLine, accessLine map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &handlerLine))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &accessLine))
+
+	require.Equal(t, "handlers.house.Flats", handlerLine["fn"])
+	require.NotEmpty(t, handlerLine["id_request"])
+	require.Equal(t, handlerLine["id_request"], accessLine["id_request"])
+
+	require.Equal(t, "WARN", accessLine["level"])
+	require.Equal(t, "/house/{id}", accessLine["route"])
+	require.Equal(t, float64(http.StatusNotFound), accessLine["status"])
+	require.Equal(t, float64(len(`{"message":"not found"}`)), accessLine["bytes"])
+	require.Equal(t, userID.String(), accessLine["user_id"])
+	require.Equal(t, "client", accessLine["role"])
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(New())
+	router.Get("/house/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.Post("/flat/create", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		labels []string
+	}{
+		{
+			name:   "pattern instead of path",
+			method: http.MethodGet,
+			target: "/house/42",
+			labels: []string{"/house/{id}", http.MethodGet, "404"},
+		},
+		{
+			name:   "implicit status",
+			method: http.MethodPost,
+			target: "/flat/create",
+			labels: []string{"/flat/create", http.MethodPost, "200"},
+		},
+		{
+			name:   "unmatched",
+			method: http.MethodGet,
+			target: "/wp-admin",
+			labels: []string{unmatched, http.MethodGet, "404"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(requests.WithLabelValues(tt.labels...))
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.target, nil))
+
+			require.Equal(t, before+1, testutil.ToFloat64(requests.WithLabelValues(tt.labels...)))
+		})
+	}
+}
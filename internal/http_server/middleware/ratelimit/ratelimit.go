@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	"avito_tech/internal/lib/ratelimit"
+	"github.com/google/uuid"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// New limits the requests of a route group by policy. Authenticated
+// requests are limited per user, so mount it after JWTAuth; anonymous ones
+// per client IP. If the limiter fails the request is let through.
+func New(log *slog.Logger, limiter ratelimit.Limiter, policy *ratelimit.Policy, group string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const fn = "middleware.ratelimit"
+
+			rule := policy.Rule()
+			if !rule.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := limiter.Allow(r.Context(), group+":"+principal(r), rule)
+			if err != nil {
+				log := slg.WithLogger(r.Context(), log, fn)
+				log.Warn("rate limiter failed, request let through", slg.Err(err))
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				httperr.Render(w, r, httperr.TooManyRequests("too many requests", res.RetryAfter))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// principal is the user of the JWT, or the client IP for anonymous routes.
+func principal(r *http.Request) string {
+	if id, ok := r.Context().Value("username").(uuid.UUID); ok {
+		return "user:" + id.String()
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
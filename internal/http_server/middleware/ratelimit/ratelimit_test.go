@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"avito_tech/internal/lib/ratelimit"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type limiterFunc func(ctx context.Context, key string, rule ratelimit.Rule) (ratelimit.Result, error)
+
+func (f limiterFunc) Allow(ctx context.Context, key string, rule ratelimit.Rule) (ratelimit.Result, error) {
+	return f(ctx, key, rule)
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("limited", func(t *testing.T) {
+		policy := ratelimit.NewPolicy(ratelimit.Rule{Rate: 1, Burst: 1})
+		handler := New(log, ratelimit.NewMemory(), policy, "write")(ok)
+
+		req := httptest.NewRequest(http.MethodPost, "/flat/create", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		require.Equal(t, "1", rec.Header().Get("RateLimit-Reset"))
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		policy := ratelimit.NewPolicy(ratelimit.Rule{})
+		handler := New(log, ratelimit.NewMemory(), policy, "read")(ok)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/house/1", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	})
+
+	t.Run("limiter failure lets through", func(t *testing.T) {
+		limiter := limiterFunc(func(context.Context, string, ratelimit.Rule) (ratelimit.Result, error) {
+			return ratelimit.Result{}, errors.New("db is down")
+		})
+		policy := ratelimit.NewPolicy(ratelimit.Rule{Rate: 1, Burst: 1})
+
+		rec := httptest.NewRecorder()
+		New(log, limiter, policy, "read")(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/house/1", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("key", func(t *testing.T) {
+		var key string
+		limiter := limiterFunc(func(_ context.Context, k string, rule ratelimit.Rule) (ratelimit.Result, error) {
+			key = k
+			return ratelimit.Result{Allowed: true, Limit: rule.Burst, Reset: time.Second}, nil
+		})
+		policy := ratelimit.NewPolicy(ratelimit.Rule{Rate: 1, Burst: 1})
+		handler := New(log, limiter, policy, "read")(ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/house/1", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		require.Equal(t, "read:ip:10.0.0.1", key)
+
+		id := uuid.New()
+		req = req.WithContext(context.WithValue(req.Context(), "username", id))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		require.Equal(t, "read:user:"+id.String(), key)
+	})
+}
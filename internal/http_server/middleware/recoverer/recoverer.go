@@ -0,0 +1,37 @@
+package recoverer
+
+import (
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// New replaces chi's Recoverer: a panic is logged with its stack and answered
+// with the regular error body instead of an empty 500.
+func New(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const fn = "middleware.recoverer"
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				log := slg.WithLogger(r.Context(), log, fn)
+				log.Error("panic recovered", slog.Any("panic", rec), slog.String("stack", string(debug.Stack())))
+
+				httperr.Render(w, r, httperr.Internal("internal error"))
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
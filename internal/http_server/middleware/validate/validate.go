@@ -0,0 +1,159 @@
+package validate
+
+import (
+	"avito_tech/internal/lib/auth"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/logger/slg"
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ResponseInvalidRequest struct {
+	httperr.Response
+	Errors []FieldError `json:"errors"`
+}
+
+func init() {
+	openapi3.DefineStringFormatValidator("email", openapi3.NewCallbackValidator(func(email string) error {
+		if !auth.IsValidEmail(email) {
+			return errors.New("invalid email address")
+		}
+		return nil
+	}))
+}
+
+// New returns a middleware that validates path, query and body of every
+// request described in doc before it reaches a handler. Requests to routes
+// that are not in doc are passed through untouched.
+func New(log *slog.Logger, doc *openapi3.T) (func(next http.Handler) http.Handler, error) {
+	const fn = "middleware.validate.New"
+
+	if len(doc.Servers) == 0 {
+		doc.Servers = openapi3.Servers{{URL: "/"}}
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	// Multipart bodies are file uploads: the validator would read them into
+	// memory whole, so they are left to the handler to check as it streams.
+	uploadOptions := *options
+	uploadOptions.ExcludeRequestBody = true
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const fn = "middleware.validate"
+			reqID := middleware.GetReqID(r.Context())
+
+			log := slg.WithLogger(r.Context(), log, fn)
+
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength != 0 && r.Header.Get("Content-Type") == "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			opts := options
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+				opts = &uploadOptions
+			}
+
+			err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    opts,
+			})
+			if err != nil {
+				message := "invalid request"
+				fields := fieldErrors(err)
+
+				log.Error(message, slog.Any("errors", fields))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, ResponseInvalidRequest{
+					Response: httperr.Response{
+						Message:   message,
+						RequestID: reqID,
+						Code:      httperr.CodeInvalidRequest,
+					},
+					Errors: fields,
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func fieldErrors(err error) []FieldError {
+	// Type switch rather than errors.As: MultiError matches errors.As on any of
+	// its elements, which would hide all but the first field.
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var fields []FieldError
+		for _, nested := range e {
+			fields = append(fields, fieldErrors(nested)...)
+		}
+		return fields
+
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			return []FieldError{{Field: e.Parameter.Name, Message: reason(e)}}
+		}
+
+		if e.Err != nil {
+			if nested := fieldErrors(e.Err); len(nested) != 0 {
+				return nested
+			}
+		}
+
+		return []FieldError{{Field: "body", Message: reason(e)}}
+
+	case *openapi3.SchemaError:
+		field := strings.Join(e.JSONPointer(), ".")
+		if field == "" {
+			field = "body"
+		}
+		return []FieldError{{Field: field, Message: e.Reason}}
+	}
+
+	return []FieldError{{Field: "request", Message: err.Error()}}
+}
+
+func reason(reqErr *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(reqErr.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+
+	if reqErr.Reason != "" {
+		return reqErr.Reason
+	}
+
+	return reqErr.Error()
+}
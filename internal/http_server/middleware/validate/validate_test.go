@@ -0,0 +1,189 @@
+package validate_test
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/http_server/middleware/validate"
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		contentType    string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:           "valid house",
+			method:         http.MethodPost,
+			path:           "/house/create",
+			body:           `{"id": 1, "address": "Лесная улица, 7", "year": 2000}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "negative year",
+			method:         http.MethodPost,
+			path:           "/house/create",
+			body:           `{"id": 1, "address": "Лесная улица, 7", "year": -1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"year"},
+		},
+		{
+			name:           "missing fields",
+			method:         http.MethodPost,
+			path:           "/flat/create",
+			body:           `{"house_id": 0}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"house_id", "price", "rooms"},
+		},
+		{
+			name:           "unknown status",
+			method:         http.MethodPost,
+			path:           "/flat/update",
+			body:           `{"id": 1, "status": "sold"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"status"},
+		},
+		{
+			name:           "invalid email",
+			method:         http.MethodPost,
+			path:           "/house/1/subscribe",
+			body:           `{"email": "not an email"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"email"},
+		},
+		{
+			name:           "unknown user type",
+			method:         http.MethodGet,
+			path:           "/dummyLogin?user_type=hacker",
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"user_type"},
+		},
+		{
+			name:           "invalid path id",
+			method:         http.MethodGet,
+			path:           "/house/0",
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"id"},
+		},
+		{
+			name:           "nearby is not a house id",
+			method:         http.MethodGet,
+			path:           "/house/nearby?lat=55.79&lon=37.54&radius=500",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "latitude out of range",
+			method:         http.MethodGet,
+			path:           "/house/nearby?lat=91&lon=37.54&radius=500",
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"lat"},
+		},
+		{
+			name:           "flat detail filters",
+			method:         http.MethodGet,
+			path:           "/flats/search?area_min=40.5&renovation=euro&renovation=designer&amenities=internet",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown amenity",
+			method:         http.MethodGet,
+			path:           "/flats/search?amenities=pool",
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"amenities"},
+		},
+		{
+			name:           "ceiling too low",
+			method:         http.MethodPost,
+			path:           "/flat/create",
+			body:           `{"house_id": 1, "price": 100, "rooms": 1, "ceiling_height": 0.5}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"ceiling_height"},
+		},
+		{
+			name:           "invalid completion quarter",
+			method:         http.MethodPost,
+			path:           "/house/update",
+			body:           `{"id": 1, "construction_status": "under_construction", "completion_quarter": "2027-Q5"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"completion_quarter"},
+		},
+		{
+			name:           "unknown wall material",
+			method:         http.MethodPost,
+			path:           "/house/create",
+			body:           `{"id": 1, "address": "Лесная улица, 7", "year": 2000, "material": "straw"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"material"},
+		},
+		{
+			name:           "upload body is left to the handler",
+			method:         http.MethodPost,
+			path:           "/flat/1/photos",
+			body:           "--x\r\nContent-Disposition: form-data; name=\"kind\"\r\n\r\nphoto\r\n--x--\r\n",
+			contentType:    "multipart/form-data; boundary=x",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "route outside spec",
+			method:         http.MethodGet,
+			path:           "/unknown",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	doc, err := api.Load()
+	require.NoError(t, err)
+
+	mw, err := validate.New(nil, doc)
+	require.NoError(t, err)
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+
+			switch {
+			case tt.contentType != "":
+				req.Header.Set("Content-Type", tt.contentType)
+			case tt.body != "":
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+
+			if tt.expectedFields == nil {
+				return
+			}
+
+			var response validate.ResponseInvalidRequest
+			err = json.Unmarshal(rr.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, "invalid request", response.Message)
+
+			var fields []string
+			for _, e := range response.Errors {
+				fields = append(fields, e.Field)
+			}
+			require.ElementsMatch(t, tt.expectedFields, fields)
+		})
+	}
+}
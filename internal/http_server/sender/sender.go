@@ -1,23 +1,59 @@
 package sender
 
 import (
+	"avito_tech/internal/lib/logger/slg"
+	"avito_tech/internal/lib/tracing"
 	"context"
 	"errors"
-	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
 	"math/rand"
 	"time"
 )
 
-type Sender struct{}
+type Sender struct {
+	log *slog.Logger
+}
+
+func New(log *slog.Logger) *Sender {
+	return &Sender{log: log}
+}
+
+// logger is nil for a nil Sender, which sends like any other.
+func (s *Sender) logger() *slog.Logger {
+	if s == nil {
+		return nil
+	}
 
-func New() *Sender {
-	return &Sender{}
+	return s.log
 }
 
-func (s *Sender) SendEmail(ctx context.Context, recipient string, message string) error {
+// SendEmail sends message to recipient. The trace context of ctx is passed
+// along in the message headers, so the mail service can continue the trace.
+func (s *Sender) SendEmail(ctx context.Context, recipient string, message string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "sender.SendEmail", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "send failed")
+		}
+		span.End()
+	}()
+
+	headers := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+
 	// Имитация отправки сообщения
 	duration := time.Duration(rand.Int63n(3000)) * time.Millisecond
-	time.Sleep(duration)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(duration):
+	}
 
 	// Имитация неуспешной отправки сообщения
 	errorProbability := 0.1
@@ -25,7 +61,11 @@ func (s *Sender) SendEmail(ctx context.Context, recipient string, message string
 		return errors.New("internal error")
 	}
 
-	fmt.Printf("send message '%s' to '%s'\n", message, recipient)
+	log := slg.WithLogger(ctx, s.logger(), "sender.SendEmail")
+
+	log.Debug("trace context passed to the mail service", slog.Any("headers", map[string]string(headers)))
+	// Neither the recipient nor the message: both are personal data.
+	log.Info("email sent", slog.Int("message_bytes", len(message)))
 
 	return nil
 }
@@ -0,0 +1,388 @@
+package server
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/http_server/handlers/account"
+	"avito_tech/internal/http_server/handlers/admin"
+	"avito_tech/internal/http_server/handlers/auth"
+	"avito_tech/internal/http_server/handlers/developer"
+	"avito_tech/internal/http_server/handlers/flat"
+	"avito_tech/internal/http_server/handlers/health"
+	"avito_tech/internal/http_server/handlers/house"
+	"avito_tech/internal/http_server/handlers/photo"
+	mdr "avito_tech/internal/http_server/middleware/auth"
+	"avito_tech/internal/http_server/middleware/idempotency"
+	"avito_tech/internal/http_server/sender"
+	"avito_tech/internal/lib/blob"
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/lib/worker"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Storage interface {
+	auth.AuthStorage
+	house.HouseStorage
+	flat.FlatStorage
+	account.AccountStorage
+	admin.AdminStorage
+	mdr.SessionProvider
+	idempotency.Store
+	photo.PhotoStorage
+	developer.DeveloperStorage
+}
+
+// Server binds the existing handlers to the operations generated from
+// api.yaml. Adding an operation to the spec without implementing it here
+// breaks the build.
+type Server struct {
+	healthz http.Handler
+	readyz  http.Handler
+	metrics http.Handler
+
+	dummyLogin    http.Handler
+	login         http.Handler
+	register      http.Handler
+	resetPassword http.Handler
+
+	createHouse http.Handler
+	updateHouse http.Handler
+	houseFlats  http.Handler
+	subscribe   http.Handler
+	searchHouse http.Handler
+	nearby      http.Handler
+	houseBox    http.Handler
+
+	createDeveloper http.Handler
+	getDeveloper    http.Handler
+
+	createFlat http.Handler
+	updateFlat http.Handler
+	flatPrices http.Handler
+	searchFlat http.Handler
+
+	flatPhotos     http.Handler
+	uploadPhoto    http.Handler
+	reorderPhotos  http.Handler
+	photoImage     http.Handler
+	photoThumbnail http.Handler
+	deletePhoto    http.Handler
+
+	export http.Handler
+	erase  http.Handler
+
+	searchUsers        http.Handler
+	userFlats          http.Handler
+	userSessions       http.Handler
+	changeRole         http.Handler
+	suspend            http.Handler
+	reactivate         http.Handler
+	adminResetPassword http.Handler
+	getLogLevel        http.Handler
+	setLogLevel        http.Handler
+}
+
+var _ api.ServerInterface = (*Server)(nil)
+
+// Limits are the rate limits of the route groups: anonymous routes, and
+// reads (GET) and writes of authenticated users. A nil limit lets
+// everything through.
+type Limits struct {
+	Anonymous func(next http.Handler) http.Handler
+	Read      func(next http.Handler) http.Handler
+	Write     func(next http.Handler) http.Handler
+}
+
+func limit(mw func(next http.Handler) http.Handler, next http.Handler) http.Handler {
+	if mw == nil {
+		return next
+	}
+
+	return mw(next)
+}
+
+// byMethod limits GET requests as reads and the rest as writes.
+func (l Limits) byMethod(next http.Handler) http.Handler {
+	read := limit(l.Read, next)
+	write := limit(l.Write, next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			read.ServeHTTP(w, r)
+			return
+		}
+
+		write.ServeHTTP(w, r)
+	})
+}
+
+// Settings tune the behaviour of the handlers.
+type Settings struct {
+	// IdempotencyTTL is how long create operations replay the response to
+	// an Idempotency-Key.
+	IdempotencyTTL time.Duration
+	// IdempotencyLease is how long a request holds its Idempotency-Key
+	// before a retry may take it over, in case it never finishes.
+	IdempotencyLease time.Duration
+	// PriceDropPercent is the price drop of an approved flat, in percent,
+	// above which the subscribers of its house are notified.
+	PriceDropPercent float64
+	// Photos bound photo uploads.
+	Photos photo.Settings
+	// ReadyTimeout bounds each of ReadyChecks, the dependencies /readyz
+	// checks.
+	ReadyTimeout time.Duration
+	ReadyChecks  []health.Check
+	// Validate checks a request against api.yaml. It runs after
+	// authentication, so an anonymous caller gets 401/403 rather than the
+	// schema errors of the body. A nil Validate lets everything through.
+	Validate func(next http.Handler) http.Handler
+}
+
+func New(log *slog.Logger, storage Storage, blobs blob.Store, sender *sender.Sender, workers *worker.Group, level *slog.LevelVar, limits Limits, settings Settings) *Server {
+	validated := func(next http.Handler) http.Handler {
+		return limit(settings.Validate, next)
+	}
+
+	anonymous := func(next http.Handler) http.Handler {
+		return limit(limits.Anonymous, validated(next))
+	}
+
+	authenticated := func(next http.Handler) http.Handler {
+		return mdr.JWTAuth(log, storage, limits.byMethod(next))
+	}
+
+	authorized := func(next http.Handler) http.Handler {
+		return authenticated(validated(next))
+	}
+
+	moderator := func(next http.Handler) http.Handler {
+		return authenticated(mdr.RequireModerator(log, validated(next)))
+	}
+
+	admins := func(next http.Handler) http.Handler {
+		return authenticated(mdr.RequireAdmin(log, validated(next)))
+	}
+
+	idempotent := idempotency.New(log, storage, settings.IdempotencyTTL, settings.IdempotencyLease)
+
+	return &Server{
+		healthz: health.Live(),
+		readyz:  health.Ready(log, settings.ReadyTimeout, settings.ReadyChecks...),
+		metrics: promhttp.Handler(),
+
+		dummyLogin:    anonymous(auth.DummyLogin(log, storage)),
+		login:         anonymous(auth.Login(log, storage)),
+		register:      anonymous(auth.Register(log, storage)),
+		resetPassword: anonymous(auth.ResetPassword(log, storage)),
+
+		createHouse: moderator(idempotent(house.Create(log, storage))),
+		updateHouse: moderator(house.Update(log, storage)),
+		houseFlats:  authorized(house.GetAllFlats(log, storage)),
+		subscribe:   authorized(house.Subscribe(log, storage)),
+		searchHouse: authorized(house.Search(log, storage)),
+		nearby:      authorized(house.Nearby(log, storage)),
+		houseBox:    authorized(house.Box(log, storage)),
+
+		createDeveloper: moderator(developer.Create(log, storage)),
+		getDeveloper:    authorized(developer.Get(log, storage)),
+
+		createFlat: authorized(idempotent(flat.Create(log, storage, sender, workers))),
+		updateFlat: authorized(flat.Update(log, storage, sender, workers, settings.PriceDropPercent)),
+		flatPrices: authorized(flat.Prices(log, storage)),
+		searchFlat: authorized(flat.Search(log, storage)),
+
+		flatPhotos:     authorized(photo.List(log, storage)),
+		uploadPhoto:    authorized(photo.Upload(log, storage, blobs, settings.Photos)),
+		reorderPhotos:  authorized(photo.Reorder(log, storage)),
+		photoImage:     authorized(photo.Image(log, storage, blobs, false)),
+		photoThumbnail: authorized(photo.Image(log, storage, blobs, true)),
+		deletePhoto:    authorized(photo.Delete(log, storage, blobs)),
+
+		export: authorized(account.Export(log, storage)),
+		erase:  authorized(account.Erase(log, storage)),
+
+		searchUsers:        admins(admin.SearchUsers(log, storage)),
+		userFlats:          admins(admin.UserFlats(log, storage)),
+		userSessions:       admins(admin.UserSessions(log, storage)),
+		changeRole:         admins(admin.ChangeRole(log, storage)),
+		suspend:            admins(admin.Suspend(log, storage)),
+		reactivate:         admins(admin.Reactivate(log, storage)),
+		adminResetPassword: admins(admin.ResetPassword(log, storage)),
+		getLogLevel:        admins(admin.GetLogLevel(log, level)),
+		setLogLevel:        admins(admin.SetLogLevel(log, level)),
+	}
+}
+
+// Handler mounts every operation of the spec on router.
+func (s *Server) Handler(router chi.Router) http.Handler {
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		httperr.Render(w, r, httperr.NotFound(httperr.CodeRouteNotFound, "route not found"))
+	})
+
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		httperr.Render(w, r, httperr.New(http.StatusMethodNotAllowed, httperr.CodeMethodNotAllowed, "method not allowed"))
+	})
+
+	return api.HandlerWithOptions(s, api.ChiServerOptions{
+		BaseRouter:       router,
+		ErrorHandlerFunc: InvalidParam,
+	})
+}
+
+// InvalidParam renders parameter binding errors of the generated wrapper in
+// the same shape as the handlers do.
+func InvalidParam(w http.ResponseWriter, r *http.Request, err error) {
+	httperr.Render(w, r, httperr.BadRequest(httperr.CodeInvalidParam, err.Error()))
+}
+
+func (s *Server) GetHealthz(w http.ResponseWriter, r *http.Request) {
+	s.healthz.ServeHTTP(w, r)
+}
+
+func (s *Server) GetReadyz(w http.ResponseWriter, r *http.Request) {
+	s.readyz.ServeHTTP(w, r)
+}
+
+func (s *Server) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	s.metrics.ServeHTTP(w, r)
+}
+
+func (s *Server) GetDummyLogin(w http.ResponseWriter, r *http.Request, _ api.GetDummyLoginParams) {
+	s.dummyLogin.ServeHTTP(w, r)
+}
+
+func (s *Server) PostLogin(w http.ResponseWriter, r *http.Request) {
+	s.login.ServeHTTP(w, r)
+}
+
+func (s *Server) PostRegister(w http.ResponseWriter, r *http.Request) {
+	s.register.ServeHTTP(w, r)
+}
+
+func (s *Server) PostPasswordReset(w http.ResponseWriter, r *http.Request) {
+	s.resetPassword.ServeHTTP(w, r)
+}
+
+func (s *Server) PostHouseCreate(w http.ResponseWriter, r *http.Request, _ api.PostHouseCreateParams) {
+	s.createHouse.ServeHTTP(w, r)
+}
+
+func (s *Server) PostHouseUpdate(w http.ResponseWriter, r *http.Request, _ api.PostHouseUpdateParams) {
+	s.updateHouse.ServeHTTP(w, r)
+}
+
+func (s *Server) GetHouseId(w http.ResponseWriter, r *http.Request, _ api.HouseId, _ api.GetHouseIdParams) {
+	s.houseFlats.ServeHTTP(w, r)
+}
+
+func (s *Server) PostHouseIdSubscribe(w http.ResponseWriter, r *http.Request, _ api.HouseId) {
+	s.subscribe.ServeHTTP(w, r)
+}
+
+func (s *Server) GetHousesSearch(w http.ResponseWriter, r *http.Request, _ api.GetHousesSearchParams) {
+	s.searchHouse.ServeHTTP(w, r)
+}
+
+func (s *Server) GetHouseNearby(w http.ResponseWriter, r *http.Request, _ api.GetHouseNearbyParams) {
+	s.nearby.ServeHTTP(w, r)
+}
+
+func (s *Server) GetHouseBbox(w http.ResponseWriter, r *http.Request, _ api.GetHouseBboxParams) {
+	s.houseBox.ServeHTTP(w, r)
+}
+
+func (s *Server) PostDeveloperCreate(w http.ResponseWriter, r *http.Request) {
+	s.createDeveloper.ServeHTTP(w, r)
+}
+
+func (s *Server) GetDeveloperId(w http.ResponseWriter, r *http.Request, _ api.DeveloperId) {
+	s.getDeveloper.ServeHTTP(w, r)
+}
+
+func (s *Server) PostFlatCreate(w http.ResponseWriter, r *http.Request, _ api.PostFlatCreateParams) {
+	s.createFlat.ServeHTTP(w, r)
+}
+
+func (s *Server) PostFlatUpdate(w http.ResponseWriter, r *http.Request, _ api.PostFlatUpdateParams) {
+	s.updateFlat.ServeHTTP(w, r)
+}
+
+func (s *Server) GetFlatIdPrices(w http.ResponseWriter, r *http.Request, _ api.FlatId) {
+	s.flatPrices.ServeHTTP(w, r)
+}
+
+func (s *Server) GetFlatIdPhotos(w http.ResponseWriter, r *http.Request, _ api.FlatId) {
+	s.flatPhotos.ServeHTTP(w, r)
+}
+
+func (s *Server) PostFlatIdPhotos(w http.ResponseWriter, r *http.Request, _ api.FlatId) {
+	s.uploadPhoto.ServeHTTP(w, r)
+}
+
+func (s *Server) PostFlatIdPhotosOrder(w http.ResponseWriter, r *http.Request, _ api.FlatId) {
+	s.reorderPhotos.ServeHTTP(w, r)
+}
+
+func (s *Server) GetFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request, _ api.FlatId, _ api.PhotoId) {
+	s.photoImage.ServeHTTP(w, r)
+}
+
+func (s *Server) GetFlatIdPhotosPhotoIdThumbnail(w http.ResponseWriter, r *http.Request, _ api.FlatId, _ api.PhotoId) {
+	s.photoThumbnail.ServeHTTP(w, r)
+}
+
+func (s *Server) DeleteFlatIdPhotosPhotoId(w http.ResponseWriter, r *http.Request, _ api.FlatId, _ api.PhotoId) {
+	s.deletePhoto.ServeHTTP(w, r)
+}
+
+func (s *Server) GetFlatsSearch(w http.ResponseWriter, r *http.Request, _ api.GetFlatsSearchParams) {
+	s.searchFlat.ServeHTTP(w, r)
+}
+
+func (s *Server) GetMeExport(w http.ResponseWriter, r *http.Request) {
+	s.export.ServeHTTP(w, r)
+}
+
+func (s *Server) PostMeErase(w http.ResponseWriter, r *http.Request) {
+	s.erase.ServeHTTP(w, r)
+}
+
+func (s *Server) GetAdminUsers(w http.ResponseWriter, r *http.Request, _ api.GetAdminUsersParams) {
+	s.searchUsers.ServeHTTP(w, r)
+}
+
+func (s *Server) GetAdminUsersIdFlats(w http.ResponseWriter, r *http.Request, _ api.UserIdPath) {
+	s.userFlats.ServeHTTP(w, r)
+}
+
+func (s *Server) GetAdminUsersIdSessions(w http.ResponseWriter, r *http.Request, _ api.UserIdPath) {
+	s.userSessions.ServeHTTP(w, r)
+}
+
+func (s *Server) PostAdminUsersIdRole(w http.ResponseWriter, r *http.Request, _ api.UserIdPath) {
+	s.changeRole.ServeHTTP(w, r)
+}
+
+func (s *Server) PostAdminUsersIdSuspend(w http.ResponseWriter, r *http.Request, _ api.UserIdPath) {
+	s.suspend.ServeHTTP(w, r)
+}
+
+func (s *Server) PostAdminUsersIdReactivate(w http.ResponseWriter, r *http.Request, _ api.UserIdPath) {
+	s.reactivate.ServeHTTP(w, r)
+}
+
+func (s *Server) PostAdminUsersIdResetPassword(w http.ResponseWriter, r *http.Request, _ api.UserIdPath) {
+	s.adminResetPassword.ServeHTTP(w, r)
+}
+
+func (s *Server) GetAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+	s.getLogLevel.ServeHTTP(w, r)
+}
+
+func (s *Server) PutAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+	s.setLogLevel.ServeHTTP(w, r)
+}
@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"avito_tech/api"
+	"avito_tech/internal/http_server/sender"
+	"avito_tech/internal/http_server/server"
+	"avito_tech/internal/lib/blob"
+	"avito_tech/internal/lib/worker"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestRoutesMatchSpec fails when a route is mounted that api.yaml does not
+// describe, or an operation of api.yaml is not mounted.
+func TestRoutesMatchSpec(t *testing.T) {
+	doc, err := api.Load()
+	require.NoError(t, err)
+
+	var expected []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			expected = append(expected, method+" "+path)
+		}
+	}
+
+	router := chi.NewRouter()
+	server.New(nil, nil, blob.NewS3(blob.NewLocalS3(), "photos"), sender.New(nil), worker.New(), &slog.LevelVar{}, server.Limits{}, server.Settings{}).Handler(router)
+
+	var actual []string
+	err = chi.Walk(router, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		actual = append(actual, method+" "+route)
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Strings(expected)
+	sort.Strings(actual)
+
+	require.Equal(t, expected, actual)
+}
+
+// TestValidateAfterAuth checks that a request without a token is rejected
+// by authentication before its body is validated.
+func TestValidateAfterAuth(t *testing.T) {
+	invalid := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+	}
+
+	router := chi.NewRouter()
+	server.New(nil, nil, blob.NewS3(blob.NewLocalS3(), "photos"), sender.New(nil), worker.New(), &slog.LevelVar{}, server.Limits{}, server.Settings{Validate: invalid}).Handler(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/house/create", strings.NewReader(`{}`)))
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{}`)))
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,68 @@
+package erasure
+
+import (
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+const batchSize = 100
+
+type Storage interface {
+	PendingErasures(ctx context.Context, limit int) ([]uuid.UUID, error)
+	EraseUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// Job periodically processes pending erasure requests created by /me/erase.
+type Job struct {
+	log      *slog.Logger
+	storage  Storage
+	interval time.Duration
+}
+
+func New(log *slog.Logger, storage Storage, interval time.Duration) *Job {
+	return &Job{
+		log:      log.With(slog.String("fn", "jobs.erasure")),
+		storage:  storage,
+		interval: interval,
+	}
+}
+
+// Run processes requests every interval until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	ids, err := j.storage.PendingErasures(ctx, batchSize)
+	if err != nil {
+		j.log.Error("failed to get pending erasures", slg.Err(err))
+		return
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err = j.storage.EraseUser(ctx, id); err != nil {
+			j.log.Error("failed to erase user", slog.String("user_id", id.String()), slg.Err(err))
+			continue
+		}
+
+		j.log.Info("user erased", slog.String("user_id", id.String()))
+	}
+}
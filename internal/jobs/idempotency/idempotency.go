@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"log/slog"
+	"time"
+)
+
+type Storage interface {
+	PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration) error
+}
+
+// Job periodically deletes Idempotency-Key responses older than their ttl.
+type Job struct {
+	log      *slog.Logger
+	storage  Storage
+	ttl      time.Duration
+	interval time.Duration
+}
+
+func New(log *slog.Logger, storage Storage, ttl, interval time.Duration) *Job {
+	return &Job{
+		log:      log.With(slog.String("fn", "jobs.idempotency")),
+		storage:  storage,
+		ttl:      ttl,
+		interval: interval,
+	}
+}
+
+// Run purges expired keys every interval until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.storage.PurgeIdempotencyKeys(ctx, j.ttl); err != nil && ctx.Err() == nil {
+			j.log.Error("failed to purge idempotency keys", slg.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
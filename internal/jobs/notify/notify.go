@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/logger/slg"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const batchSize = 100
+
+type Storage interface {
+	ClaimNotifications(ctx context.Context, limit int, timeout time.Duration) ([]entity.Notification, error)
+	SetNotificationStatus(ctx context.Context, id int64, status string) error
+}
+
+type Sender interface {
+	SendEmail(ctx context.Context, recipient string, message string) error
+}
+
+// Job periodically resends notifications left pending by flat.Create when
+// the service was shutting down. Every replica may run it: a notification
+// is claimed by one of them, and handed out again if its result is not set
+// within claimTimeout.
+type Job struct {
+	log          *slog.Logger
+	storage      Storage
+	sender       Sender
+	interval     time.Duration
+	claimTimeout time.Duration
+}
+
+func New(log *slog.Logger, storage Storage, sender Sender, interval, claimTimeout time.Duration) *Job {
+	return &Job{
+		log:          log.With(slog.String("fn", "jobs.notify")),
+		storage:      storage,
+		sender:       sender,
+		interval:     interval,
+		claimTimeout: claimTimeout,
+	}
+}
+
+// Run resends notifications every interval until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Job) process(ctx context.Context) {
+	notifications, err := j.storage.ClaimNotifications(ctx, batchSize, j.claimTimeout)
+	if err != nil {
+		j.log.Error("failed to claim pending notifications", slg.Err(err))
+		return
+	}
+
+	for _, n := range notifications {
+		err = j.sender.SendEmail(ctx, n.Email, n.Message)
+		if ctx.Err() != nil {
+			// Still claimed, it is handed out again after claimTimeout.
+			return
+		}
+
+		status := "sent"
+		if err != nil {
+			status = "failed"
+			j.log.Error("failed to resend notification", slog.Int64("id", n.ID), slg.Err(err))
+		}
+
+		if err = j.storage.SetNotificationStatus(ctx, n.ID, status); err != nil {
+			j.log.Error("failed to update notification", slog.Int64("id", n.ID), slg.Err(err))
+		}
+	}
+}
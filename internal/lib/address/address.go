@@ -0,0 +1,115 @@
+package address
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// streetTypes maps spellings of address element types, without a trailing
+// dot and in lower case, to the abbreviation an address is stored with.
+// Addresses are expected in Russian.
+var streetTypes = map[string]string{
+	"улица":      "ул.",
+	"ул":         "ул.",
+	"проспект":   "пр-кт",
+	"просп":      "пр-кт",
+	"пр-кт":      "пр-кт",
+	"пр-т":       "пр-кт",
+	"переулок":   "пер.",
+	"пер":        "пер.",
+	"бульвар":    "б-р",
+	"бул":        "б-р",
+	"б-р":        "б-р",
+	"шоссе":      "ш.",
+	"ш":          "ш.",
+	"площадь":    "пл.",
+	"пл":         "пл.",
+	"набережная": "наб.",
+	"наб":        "наб.",
+	"проезд":     "пр-д",
+	"пр-д":       "пр-д",
+	"тупик":      "туп.",
+	"туп":        "туп.",
+	"микрорайон": "мкр.",
+	"мкр":        "мкр.",
+	"дом":        "д.",
+	"д":          "д.",
+	"корпус":     "корп.",
+	"корп":       "корп.",
+	"строение":   "стр.",
+	"стр":        "стр.",
+	"город":      "г.",
+	"г":          "г.",
+}
+
+// Normalize cleans up a free text address: whitespace is collapsed, parts
+// are separated by ", ", street types are abbreviated the same way and
+// lower case Russian words are capitalised. A part of six digits is taken out as
+// the postcode.
+func Normalize(raw string) (text, postcode string) {
+	parts := make([]string, 0, 4)
+
+	for _, part := range strings.Split(raw, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+
+		if postcode == "" && len(words) == 1 && isPostcode(words[0]) {
+			postcode = words[0]
+			continue
+		}
+
+		for i, w := range words {
+			words[i] = normalizeWord(w)
+		}
+
+		parts = append(parts, strings.Join(words, " "))
+	}
+
+	return strings.Join(parts, ", "), postcode
+}
+
+// Key is the form of a normalized address that is compared to find the
+// same building: case, "ё" and punctuation do not matter.
+func Key(text string) string {
+	text = strings.ToLower(text)
+	text = strings.ReplaceAll(text, "ё", "е")
+	text = strings.NewReplacer(".", " ", ",", " ").Replace(text)
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func normalizeWord(w string) string {
+	if abbr, ok := streetTypes[strings.ToLower(strings.TrimSuffix(w, "."))]; ok {
+		return abbr
+	}
+
+	// Russian words typed in lower case are names; anything with a capital
+	// letter (ЖК, МКАД, 1-я) or in another script is kept as entered.
+	if strings.ToLower(w) != w {
+		return w
+	}
+
+	r, size := utf8.DecodeRuneInString(w)
+	if !unicode.Is(unicode.Cyrillic, r) {
+		return w
+	}
+
+	return string(unicode.ToUpper(r)) + w[size:]
+}
+
+func isPostcode(w string) bool {
+	if len(w) != 6 {
+		return false
+	}
+
+	for _, r := range w {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
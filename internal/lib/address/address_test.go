@@ -0,0 +1,69 @@
+package address
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		text     string
+		postcode string
+	}{
+		{
+			name:     "postcode",
+			raw:      "Лесная улица, 7, Москва, 125196",
+			text:     "Лесная ул., 7, Москва",
+			postcode: "125196",
+		},
+		{
+			name: "whitespace",
+			raw:  "  Лесная  ул ,7 ,,Москва ",
+			text: "Лесная ул., 7, Москва",
+		},
+		{
+			name: "case",
+			raw:  "москва, УЛИЦА лесная, Д. 7, ЖК Сити",
+			text: "Москва, ул. Лесная, д. 7, ЖК Сити",
+		},
+		{
+			name: "street types",
+			raw:  "пр-т Мира, просп. Вернадского, бульвар Дмитрия Донского",
+			text: "пр-кт Мира, пр-кт Вернадского, б-р Дмитрия Донского",
+		},
+		{
+			name: "latin",
+			raw:  "Moscow street,  4",
+			text: "Moscow street, 4",
+		},
+		{
+			name: "house number is not a postcode",
+			raw:  "ул. Ленина, 125",
+			text: "ул. Ленина, 125",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			text, postcode := Normalize(tt.raw)
+			require.Equal(t, tt.text, text)
+			require.Equal(t, tt.postcode, postcode)
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	a, _ := Normalize("Лесная улица, 7, Москва, 125196")
+	b, _ := Normalize("лесная ул 7,  москва")
+	require.Equal(t, Key(a), Key(b))
+
+	c, _ := Normalize("ЛЕСНАЯ УЛ, 7, МОСКВА")
+	require.Equal(t, Key(a), Key(c))
+	require.Equal(t, "лесная ул 7 москва", Key(a))
+
+	require.Equal(t, Key("Зелёная ул."), Key("Зеленая ул"))
+}
@@ -0,0 +1,48 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store keeps binary objects such as photos under slash separated keys.
+type Store interface {
+	// Put stores size bytes of r under key, replacing an existing object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object under key, the caller closes it. A missing
+	// object is ErrNotFound.
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete removes the object under key. Deleting a missing object is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+type Object struct {
+	io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+// validKey rejects keys that could escape the root of a store: absolute,
+// empty or with "." and ".." elements.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key {
+		return ErrInvalidKey
+	}
+
+	for _, elem := range strings.Split(key, "/") {
+		if elem == "." || elem == ".." {
+			return ErrInvalidKey
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package blob
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStores(t *testing.T) {
+	fsStore, err := NewFS(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]Store{
+		"fs": fsStore,
+		"s3": NewS3(NewLocalS3(), "photos"),
+	}
+
+	for name, store := range stores {
+		store := store
+
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "flats/1/photo.jpg"
+
+			_, err := store.Get(ctx, key)
+			require.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, store.Put(ctx, key, strings.NewReader("jpeg"), 4, "image/jpeg"))
+
+			obj, err := store.Get(ctx, key)
+			require.NoError(t, err)
+
+			data, err := io.ReadAll(obj)
+			require.NoError(t, err)
+			require.NoError(t, obj.Close())
+
+			require.Equal(t, "jpeg", string(data))
+			require.Equal(t, "image/jpeg", obj.ContentType)
+			require.Equal(t, int64(4), obj.Size)
+
+			require.NoError(t, store.Delete(ctx, key))
+			require.NoError(t, store.Delete(ctx, key), "deleting twice")
+
+			_, err = store.Get(ctx, key)
+			require.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	for _, key := range []string{"", "/etc/passwd", "../secret", "flats/../../secret", "flats//1", "flats/1/"} {
+		require.ErrorIs(t, validKey(key), ErrInvalidKey, key)
+	}
+
+	require.NoError(t, validKey("flats/1/photo.jpg"))
+}
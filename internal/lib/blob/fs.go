@@ -0,0 +1,119 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FS stores objects as files under a root directory. The content type of
+// an object is derived from the extension of its key, so keys should have
+// one.
+type FS struct {
+	root string
+}
+
+func NewFS(root string) (*FS, error) {
+	const fn = "blob.NewFS"
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return &FS{root: root}, nil
+}
+
+func (s *FS) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	const fn = "blob.FS.Put"
+
+	name, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	// Written next to the target and renamed, so that a reader never sees
+	// a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+func (s *FS) Get(_ context.Context, key string) (*Object, error) {
+	const fn = "blob.FS.Get"
+
+	name, err := s.path(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %s: %w", fn, key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &Object{ReadCloser: f, ContentType: contentType, Size: info.Size()}, nil
+}
+
+func (s *FS) Delete(_ context.Context, key string) error {
+	const fn = "blob.FS.Delete"
+
+	name, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+func (s *FS) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
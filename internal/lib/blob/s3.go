@@ -0,0 +1,122 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// S3API is the part of an S3-compatible client that S3 needs. An adapter
+// for a real client has to report a missing object (NoSuchKey) as
+// ErrNotFound.
+type S3API interface {
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error
+	GetObject(ctx context.Context, bucket, key string) (body io.ReadCloser, contentType string, size int64, err error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3 stores objects in a bucket of an S3-compatible object storage.
+type S3 struct {
+	client S3API
+	bucket string
+}
+
+func NewS3(client S3API, bucket string) *S3 {
+	return &S3{client: client, bucket: bucket}
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	const fn = "blob.S3.Put"
+
+	if err := validKey(key); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err := s.client.PutObject(ctx, s.bucket, key, r, size, contentType); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (*Object, error) {
+	const fn = "blob.S3.Get"
+
+	if err := validKey(key); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	body, contentType, size, err := s.client.GetObject(ctx, s.bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return &Object{ReadCloser: body, ContentType: contentType, Size: size}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	const fn = "blob.S3.Delete"
+
+	if err := validKey(key); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err := s.client.DeleteObject(ctx, s.bucket, key); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// LocalS3 is an in-memory stand-in for an S3-compatible storage, for
+// development and tests. Objects are lost when the process exits.
+type LocalS3 struct {
+	mu      sync.RWMutex
+	objects map[string]localObject
+}
+
+type localObject struct {
+	data        []byte
+	contentType string
+}
+
+func NewLocalS3() *LocalS3 {
+	return &LocalS3{objects: make(map[string]localObject)}
+}
+
+func (l *LocalS3) PutObject(_ context.Context, bucket, key string, body io.Reader, _ int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.objects[bucket+"/"+key] = localObject{data: data, contentType: contentType}
+
+	return nil
+}
+
+func (l *LocalS3) GetObject(_ context.Context, bucket, key string) (io.ReadCloser, string, int64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	obj, ok := l.objects[bucket+"/"+key]
+	if !ok {
+		return nil, "", 0, fmt.Errorf("%s/%s: %w", bucket, key, ErrNotFound)
+	}
+
+	return io.NopCloser(bytes.NewReader(obj.data)), obj.contentType, int64(len(obj.data)), nil
+}
+
+func (l *LocalS3) DeleteObject(_ context.Context, bucket, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.objects, bucket+"/"+key)
+
+	return nil
+}
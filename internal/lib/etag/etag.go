@@ -0,0 +1,65 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid entity tag")
+
+// Version is the strong entity tag of an entity at version.
+func Version(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseIfMatch reads the version expected by an If-Match header. A "*"
+// matches any version and is returned as 0. Only a single strong tag of
+// Version is accepted.
+func ParseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, nil
+	}
+
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, ErrInvalid
+	}
+
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, ErrInvalid
+	}
+
+	return version, nil
+}
+
+// List is a weak entity tag of a collection, built from the id and version
+// of every item in order. It changes whenever an item is added, removed or
+// updated.
+func List(ids, versions []int64) string {
+	h := sha256.New()
+	for i := range ids {
+		h.Write([]byte(strconv.FormatInt(ids[i], 10) + ":" + strconv.FormatInt(versions[i], 10) + ";"))
+	}
+
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// NoneMatch reports whether an If-None-Match header lists tag, comparing
+// weakly as RFC 9110 requires for GET.
+func NoneMatch(header, tag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,49 @@
+package etag
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected int64
+		err      error
+	}{
+		{name: "version", header: `"3"`, expected: 3},
+		{name: "any", header: "*", expected: 0},
+		{name: "unquoted", header: "3", err: ErrInvalid},
+		{name: "weak", header: `W/"3"`, err: ErrInvalid},
+		{name: "list", header: `"3", "4"`, err: ErrInvalid},
+		{name: "zero", header: `"0"`, err: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := ParseIfMatch(tt.header)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	tag := List([]int64{1, 2}, []int64{1, 1})
+
+	require.Equal(t, tag, List([]int64{1, 2}, []int64{1, 1}))
+	require.NotEqual(t, tag, List([]int64{1, 2}, []int64{1, 2}), "an item was updated")
+	require.NotEqual(t, tag, List([]int64{1}, []int64{1}), "an item was removed")
+}
+
+func TestNoneMatch(t *testing.T) {
+	tag := List([]int64{1}, []int64{1})
+
+	require.True(t, NoneMatch(tag, tag))
+	require.True(t, NoneMatch(`"x", `+tag[2:], tag), "weak comparison")
+	require.True(t, NoneMatch("*", tag))
+	require.False(t, NoneMatch(`"x"`, tag))
+}
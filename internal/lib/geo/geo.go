@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Valid reports whether p is a point on the Earth: latitude in [-90, 90]
+// and longitude in [-180, 180] degrees.
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// Box is an area between two latitudes and two longitudes. A box with
+// MinLon greater than MaxLon crosses the antimeridian.
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+func (b Box) Valid() bool {
+	return Point{b.MinLat, b.MinLon}.Valid() && Point{b.MaxLat, b.MaxLon}.Valid() && b.MinLat <= b.MaxLat
+}
+
+// Distance is the great-circle distance between a and b in meters, by the
+// haversine formula.
+func Distance(a, b Point) float64 {
+	dLat := radians(b.Lat - a.Lat)
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(a.Lat))*math.Cos(radians(b.Lat))*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * EarthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// Around is a box containing every point within radius meters of center.
+// It is wider than the circle and only prefilters points for Distance.
+func Around(center Point, radius float64) Box {
+	dLat := degrees(radius / EarthRadius)
+
+	box := Box{
+		MinLat: math.Max(center.Lat-dLat, -90),
+		MaxLat: math.Min(center.Lat+dLat, 90),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+
+	// Near a pole every longitude can be within the radius.
+	if box.MinLat == -90 || box.MaxLat == 90 {
+		return box
+	}
+
+	dLon := degrees(math.Asin(math.Sin(radius/EarthRadius) / math.Cos(radians(center.Lat))))
+	if math.IsNaN(dLon) || dLon >= 180 {
+		return box
+	}
+
+	box.MinLon = wrap(center.Lon - dLon)
+	box.MaxLon = wrap(center.Lon + dLon)
+
+	return box
+}
+
+// wrap brings a longitude back into [-180, 180].
+func wrap(lon float64) float64 {
+	switch {
+	case lon < -180:
+		return lon + 360
+	case lon > 180:
+		return lon - 360
+	default:
+		return lon
+	}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
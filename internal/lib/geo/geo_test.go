@@ -0,0 +1,75 @@
+package geo
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	moscow := Point{Lat: 55.7558, Lon: 37.6173}
+	petersburg := Point{Lat: 59.9343, Lon: 30.3351}
+
+	require.InDelta(t, 634_000, Distance(moscow, petersburg), 2_000)
+	require.Zero(t, Distance(moscow, moscow))
+}
+
+func TestAround(t *testing.T) {
+	tests := []struct {
+		name   string
+		center Point
+		radius float64
+	}{
+		{name: "moscow", center: Point{Lat: 55.7558, Lon: 37.6173}, radius: 5_000},
+		{name: "equator", center: Point{Lat: 0, Lon: 0}, radius: 100_000},
+		{name: "antimeridian", center: Point{Lat: 64.7337, Lon: 177.5089}, radius: 200_000},
+		{name: "pole", center: Point{Lat: 89.9, Lon: 10}, radius: 50_000},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			box := Around(tt.center, tt.radius)
+			require.True(t, box.Valid())
+
+			// Points on the circle must be inside the box.
+			for bearing := 0.0; bearing < 360; bearing += 15 {
+				p := destination(tt.center, bearing, tt.radius*0.999)
+				require.InDelta(t, tt.radius*0.999, Distance(tt.center, p), 1)
+				require.True(t, box.contains(p), "bearing %v: %+v outside %+v", bearing, p, box)
+			}
+		})
+	}
+}
+
+func TestAroundAntimeridian(t *testing.T) {
+	box := Around(Point{Lat: 0, Lon: 179.9}, 50_000)
+
+	require.Greater(t, box.MinLon, box.MaxLon)
+	require.True(t, box.contains(Point{Lat: 0, Lon: -179.9}))
+	require.False(t, box.contains(Point{Lat: 0, Lon: 0}))
+}
+
+func (b Box) contains(p Point) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+	}
+
+	return p.Lon >= b.MinLon || p.Lon <= b.MaxLon
+}
+
+// destination is the point distance meters from p in the direction of
+// bearing degrees.
+func destination(p Point, bearing, distance float64) Point {
+	d := distance / EarthRadius
+	lat1, lon1, b := radians(p.Lat), radians(p.Lon), radians(bearing)
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lon2 := lon1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	return Point{Lat: degrees(lat2), Lon: wrap(degrees(lon2))}
+}
@@ -0,0 +1,160 @@
+package httperr
+
+import (
+	"avito_tech/internal/storage"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Code classifies an error independently of its message. Codes are part of
+// the API contract: never renumber or reuse them, only add new ones.
+type Code int
+
+const (
+	CodeInvalidRequest Code = 40000
+	CodeInvalidBody    Code = 40001
+	CodeInvalidParam   Code = 40002
+	CodeConstraint     Code = 40003
+
+	CodeUnauthorized       Code = 40100
+	CodeInvalidToken       Code = 40101
+	CodeInvalidCredentials Code = 40102
+
+	CodeForbidden     Code = 40300
+	CodeUserInactive  Code = 40301
+	CodeResetRequired Code = 40302
+
+	CodeNotFound          Code = 40400
+	CodeUserNotFound      Code = 40401
+	CodeResetTokenInvalid Code = 40402
+	CodeRouteNotFound     Code = 40403
+
+	CodeMethodNotAllowed Code = 40500
+
+	CodeConflict              Code = 40900
+	CodeIdempotencyInProgress Code = 40901
+	CodePhotoLimit            Code = 40902
+	CodeLocked                Code = 40903
+
+	CodePreconditionFailed Code = 41200
+
+	CodePayloadTooLarge Code = 41300
+
+	CodeUnsupportedMediaType Code = 41500
+
+	CodeIdempotencyMismatch Code = 42200
+
+	CodePreconditionRequired Code = 42800
+
+	CodeTooManyRequests Code = 42900
+
+	CodeInternal    Code = 50000
+	CodeUnavailable Code = 50300
+)
+
+const retryAfter = time.Second * 5
+
+// Error is an error that knows how to render itself as an HTTP response.
+type Error struct {
+	Status     int
+	Code       Code
+	Message    string
+	RetryAfter time.Duration
+}
+
+type Response struct {
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+	Code      Code   `json:"code"`
+}
+
+func New(status int, code Code, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func BadRequest(code Code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+func Unauthorized(code Code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+func Forbidden(code Code, message string) *Error {
+	return New(http.StatusForbidden, code, message)
+}
+
+func NotFound(code Code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+func Internal(message string) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message)
+}
+
+// Unavailable is a 5xx the client may retry after RetryAfter.
+func Unavailable(message string) *Error {
+	e := New(http.StatusServiceUnavailable, CodeUnavailable, message)
+	e.RetryAfter = retryAfter
+
+	return e
+}
+
+// TooManyRequests is a 429 the client may retry after retryAfter, rounded up
+// to whole seconds.
+func TooManyRequests(message string, retryAfter time.Duration) *Error {
+	e := New(http.StatusTooManyRequests, CodeTooManyRequests, message)
+	e.RetryAfter = max(time.Duration(math.Ceil(retryAfter.Seconds()))*time.Second, time.Second)
+
+	return e
+}
+
+// FromStorage maps the sentinel errors of the storage package onto a
+// response. Anything unknown becomes a 500 with message.
+func FromStorage(err error, message string) *Error {
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+		return NotFound(CodeUserNotFound, "user not found")
+	case errors.Is(err, storage.ErrNotFound):
+		return NotFound(CodeNotFound, "not found")
+	case errors.Is(err, storage.ErrConflict):
+		return New(http.StatusConflict, CodeConflict, "already exists")
+	case errors.Is(err, storage.ErrLocked):
+		return New(http.StatusConflict, CodeLocked, "locked by another user")
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return New(http.StatusPreconditionFailed, CodePreconditionFailed, "version mismatch")
+	case errors.Is(err, storage.ErrConstraint):
+		return BadRequest(CodeConstraint, "constraint violation")
+	case errors.Is(err, storage.ErrTemporary):
+		return Unavailable(message)
+	}
+
+	return Internal(message)
+}
+
+// Render writes e with the request id of r.
+func Render(w http.ResponseWriter, r *http.Request, e *Error) {
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(e.RetryAfter.Seconds())))
+	}
+
+	render.Status(r, e.Status)
+	render.JSON(w, r, Response{
+		Message:   e.Message,
+		RequestID: middleware.GetReqID(r.Context()),
+		Code:      e.Code,
+	})
+}
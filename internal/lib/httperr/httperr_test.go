@@ -0,0 +1,104 @@
+package httperr_test
+
+import (
+	"avito_tech/internal/lib/httperr"
+	"avito_tech/internal/storage"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromStorage(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedCode    httperr.Code
+		expectedMessage string
+		retry           bool
+	}{
+		{
+			name:            "user not found",
+			err:             fmt.Errorf("storage.postgres.UserStatus: %w", storage.ErrUserNotFound),
+			expectedStatus:  http.StatusNotFound,
+			expectedCode:    httperr.CodeUserNotFound,
+			expectedMessage: "user not found",
+		},
+		{
+			name:            "not found",
+			err:             fmt.Errorf("storage.postgres.Update: %w", storage.ErrNotFound),
+			expectedStatus:  http.StatusNotFound,
+			expectedCode:    httperr.CodeNotFound,
+			expectedMessage: "not found",
+		},
+		{
+			name:            "conflict",
+			err:             fmt.Errorf("storage.postgres.CreateHouse: %w", storage.ErrConflict),
+			expectedStatus:  http.StatusConflict,
+			expectedCode:    httperr.CodeConflict,
+			expectedMessage: "already exists",
+		},
+		{
+			name:            "locked",
+			err:             fmt.Errorf("storage.postgres.Update: %w", storage.ErrLocked),
+			expectedStatus:  http.StatusConflict,
+			expectedCode:    httperr.CodeLocked,
+			expectedMessage: "locked by another user",
+		},
+		{
+			name:            "constraint",
+			err:             fmt.Errorf("storage.postgres.CreateFlat: %w", storage.ErrConstraint),
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    httperr.CodeConstraint,
+			expectedMessage: "constraint violation",
+		},
+		{
+			name:            "temporary",
+			err:             fmt.Errorf("storage.postgres.Update: %w", storage.ErrTemporary),
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedCode:    httperr.CodeUnavailable,
+			expectedMessage: "failed",
+			retry:           true,
+		},
+		{
+			name:            "unknown",
+			err:             fmt.Errorf("mock error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedCode:    httperr.CodeInternal,
+			expectedMessage: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				httperr.Render(w, r, httperr.FromStorage(tt.err, "failed"))
+			}))
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code)
+			require.Equal(t, tt.retry, rr.Header().Get("Retry-After") != "")
+
+			var response httperr.Response
+			err = json.Unmarshal(rr.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedMessage, response.Message)
+			require.Equal(t, tt.expectedCode, response.Code)
+			require.NotEmpty(t, response.RequestID)
+		})
+	}
+}
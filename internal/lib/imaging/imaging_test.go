@@ -0,0 +1,56 @@
+package imaging
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			// Left half black, right half white.
+			if x >= 200 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	thumb := Thumbnail(src, 100)
+	require.Equal(t, image.Rect(0, 0, 100, 50), thumb.Bounds())
+	require.Equal(t, color.RGBA{0, 0, 0, 255}, thumb.RGBAAt(10, 10))
+	require.Equal(t, color.RGBA{255, 255, 255, 255}, thumb.RGBAAt(90, 40))
+
+	portrait := Thumbnail(image.NewRGBA(image.Rect(0, 0, 30, 300)), 100)
+	require.Equal(t, image.Rect(0, 0, 10, 100), portrait.Bounds())
+
+	small := Thumbnail(image.NewRGBA(image.Rect(5, 5, 25, 15)), 100)
+	require.Equal(t, image.Rect(0, 0, 20, 10), small.Bounds())
+}
+
+func TestDecode(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))))
+
+	img, err := Decode(buf.Bytes(), 40*30)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 40, 30), img.Bounds())
+
+	_, err = Decode(buf.Bytes(), 40*30-1)
+	require.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Decode([]byte("GIF89a not really"), 100)
+	require.ErrorIs(t, err, ErrUnsupported)
+
+	data, err := EncodeJPEG(img, 80)
+	require.NoError(t, err)
+
+	_, err = Decode(data, 40*30)
+	require.NoError(t, err)
+}
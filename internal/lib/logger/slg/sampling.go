@@ -0,0 +1,76 @@
+package slg
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// samplingHandler thins out repeated info records. Warnings and errors are
+// always logged.
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+func newSamplingHandler(next slog.Handler, first, thereafter int, tick time.Duration) slog.Handler {
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	return samplingHandler{
+		Handler: next,
+		sampler: &sampler{
+			first:      first,
+			thereafter: thereafter,
+			tick:       tick,
+			counts:     make(map[string]int),
+		},
+	}
+}
+
+func (h samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelInfo && !h.sampler.allow(r.Message, r.Time) {
+		return nil
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return samplingHandler{h.Handler.WithAttrs(attrs), h.sampler}
+}
+
+func (h samplingHandler) WithGroup(name string) slog.Handler {
+	return samplingHandler{h.Handler.WithGroup(name), h.sampler}
+}
+
+type sampler struct {
+	first      int
+	thereafter int
+	tick       time.Duration
+
+	mu     sync.Mutex
+	counts map[string]int
+	reset  time.Time
+}
+
+func (s *sampler) allow(message string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !now.Before(s.reset) {
+		clear(s.counts)
+		s.reset = now.Add(s.tick)
+	}
+
+	s.counts[message]++
+	n := s.counts[message]
+
+	if n <= s.first {
+		return true
+	}
+
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
@@ -1,8 +1,16 @@
 package slg
 
 import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 )
 
 const (
@@ -11,24 +19,106 @@ const (
 	envProd  = "prod"
 )
 
-func SetupLogger(env string) *slog.Logger {
-	var log *slog.Logger
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	OutputStdout = "stdout"
+)
+
+// Options override what env implies. Zero values keep the defaults.
+type Options struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// Format is text or json.
+	Format string
+	// Output is stdout or the path of a file rotated once it grows past
+	// MaxSizeMB, keeping MaxBackups old files for at most MaxAgeDays.
+	Output     string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	// Sampling of info records: per message and SampleTick, the first
+	// SampleFirst are logged, then every SampleThereafter-th. Off when
+	// SampleFirst is zero.
+	SampleFirst      int
+	SampleThereafter int
+	SampleTick       time.Duration
+}
+
+// SetupLogger builds the logger of the service. local logs text at debug,
+// dev json at debug, prod and anything else json at info. The returned
+// LevelVar changes the level at runtime.
+func SetupLogger(env string, opts Options) (*slog.Logger, *slog.LevelVar, error) {
+	const fn = "lib.logger.slg.SetupLogger"
+
+	level := slog.LevelInfo
+	format := FormatJSON
 
 	switch env {
 	case envLocal:
-		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		level, format = slog.LevelDebug, FormatText
 	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+		level = slog.LevelDebug
+	}
+
+	if opts.Level != "" {
+		if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", fn, err)
+		}
+	}
+
+	if opts.Format != "" {
+		format = opts.Format
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.Output != "" && opts.Output != OutputStdout {
+		out = &lumberjack.Logger{
+			Filename:   opts.Output,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+		}
+	}
+
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(level)
+
+	handlerOpts := &slog.HandlerOptions{Level: levelVar, ReplaceAttr: redact}
+
+	var handler slog.Handler
+	switch format {
+	case FormatText:
+		handler = slog.NewTextHandler(out, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		return nil, nil, fmt.Errorf("%s: unknown format %q", fn, format)
+	}
+
+	if opts.SampleFirst > 0 {
+		handler = newSamplingHandler(handler, opts.SampleFirst, opts.SampleThereafter, opts.SampleTick)
+	}
+
+	return slog.New(traceHandler{handler}), levelVar, nil
+}
+
+// sensitive are attribute keys whose values never reach the logs.
+var sensitive = map[string]bool{
+	"password":      true,
+	"new_password":  true,
+	"token":         true,
+	"authorization": true,
+	"secret":        true,
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if sensitive[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, "[REDACTED]")
 	}
 
-	return log
+	return a
 }
 
 func Err(err error) slog.Attr {
@@ -38,9 +128,66 @@ func Err(err error) slog.Attr {
 	}
 }
 
-func WithLogger(fn, reqID string) *slog.Logger {
-	return slog.With(
-		slog.String("fn", fn),
-		slog.String("id_request", reqID),
-	)
+type ctxKey struct{}
+
+// NewContext returns ctx carrying the request-scoped log.
+func NewContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, log)
+}
+
+// WithLogger returns the request-scoped logger of ctx tagged with fn. Without
+// one (e.g. in handler tests) it falls back to log, tagged with the request
+// id and trace of ctx.
+func WithLogger(ctx context.Context, log *slog.Logger, fn string) *slog.Logger {
+	if scoped, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return scoped.With(slog.String("fn", fn))
+	}
+
+	if log == nil {
+		log = slog.Default()
+	}
+
+	args := []any{slog.String("fn", fn)}
+	for _, attr := range RequestAttrs(ctx) {
+		args = append(args, attr)
+	}
+
+	return log.With(args...)
+}
+
+// RequestAttrs returns the request id and trace ids of ctx.
+func RequestAttrs(ctx context.Context) []slog.Attr {
+	return append([]slog.Attr{slog.String("id_request", middleware.GetReqID(ctx))}, traceAttrs(ctx)...)
+}
+
+// traceHandler adds the trace and span ids of the context to records logged
+// with the *Context methods.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(traceAttrs(ctx)...)
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
+
+func traceAttrs(ctx context.Context) []slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []slog.Attr{
+		slog.String("trace_id", sc.TraceID().String()),
+		slog.String("span_id", sc.SpanID().String()),
+	}
 }
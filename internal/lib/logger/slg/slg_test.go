@@ -0,0 +1,68 @@
+package slg
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redact}))
+
+	log.Info("login", slog.String("email", "user@example.com"), slog.String("Password", "qwerty"), slog.String("token", "abc"))
+
+	require.Contains(t, buf.String(), "email=user@example.com")
+	require.Contains(t, buf.String(), "Password=[REDACTED]")
+	require.Contains(t, buf.String(), "token=[REDACTED]")
+	require.NotContains(t, buf.String(), "qwerty")
+}
+
+func TestSetupLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		opts     Options
+		expected slog.Level
+		wantErr  bool
+	}{
+		{name: "local", env: envLocal, expected: slog.LevelDebug},
+		{name: "prod", env: envProd, expected: slog.LevelInfo},
+		{name: "unknown env", env: "staging", expected: slog.LevelInfo},
+		{name: "level override", env: envProd, opts: Options{Level: "warn"}, expected: slog.LevelWarn},
+		{name: "invalid level", env: envProd, opts: Options{Level: "verbose"}, wantErr: true},
+		{name: "invalid format", env: envProd, opts: Options{Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, level, err := SetupLogger(tt.env, tt.opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, log)
+			require.Equal(t, tt.expected, level.Level())
+		})
+	}
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(newSamplingHandler(slog.NewTextHandler(&buf, nil), 2, 3, time.Hour))
+
+	for i := 0; i < 8; i++ {
+		log.Info("request completed")
+	}
+	log.Error("failed")
+	log.Error("failed")
+
+	// 2 first, then the 3rd and 6th of the rest, and every error.
+	require.Equal(t, 4, strings.Count(buf.String(), "request completed"))
+	require.Equal(t, 2, strings.Count(buf.String(), "failed"))
+}
@@ -0,0 +1,190 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Rule is a token bucket: Rate tokens per second refill a bucket of Burst.
+// A zero Rate disables limiting.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+func (r Rule) Enabled() bool {
+	return r.Rate > 0 && r.Burst > 0
+}
+
+// Result describes the bucket after a request took a token from it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is when the next token is available, zero if Allowed.
+	RetryAfter time.Duration
+	// Reset is when the bucket is full again.
+	Reset time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// Policy holds the rule of a route group. It can be swapped at runtime on
+// config reload.
+type Policy struct {
+	rule atomic.Pointer[Rule]
+}
+
+func NewPolicy(rule Rule) *Policy {
+	p := &Policy{}
+	p.Set(rule)
+
+	return p
+}
+
+func (p *Policy) Rule() Rule {
+	return *p.rule.Load()
+}
+
+func (p *Policy) Set(rule Rule) {
+	p.rule.Store(&rule)
+}
+
+// result builds a Result from the tokens left in the bucket, which are
+// negative by less than one when the request was rejected.
+func result(rule Rule, tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rule.Burst,
+		Remaining: max(int(math.Floor(tokens)), 0),
+		Reset:     seconds((float64(rule.Burst) - tokens) / rule.Rate),
+	}
+
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rule.Rate)
+	}
+
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket has refilled, after that it can be dropped.
+	full time.Time
+}
+
+// Memory keeps buckets in process. Each replica limits on its own, so the
+// effective limit is multiplied by the number of replicas.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	swept   time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// sweepInterval is how often refilled buckets are dropped, so keys of
+// one-off clients do not pile up.
+const sweepInterval = time.Minute
+
+func (m *Memory) Allow(_ context.Context, key string, rule Rule) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = min(float64(rule.Burst), b.tokens+now.Sub(b.last).Seconds()*rule.Rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	res := result(rule, b.tokens, allowed)
+	b.full = now.Add(res.Reset)
+
+	return res, nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.swept) < sweepInterval {
+		return
+	}
+	m.swept = now
+
+	for key, b := range m.buckets {
+		if now.After(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// TokenStore keeps buckets shared by all replicas, see postgres.TakeToken.
+type TokenStore interface {
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (allowed bool, tokens float64, err error)
+	PurgeTokens(ctx context.Context, idle time.Duration) error
+}
+
+// Shared keeps buckets in a store all replicas use.
+type Shared struct {
+	store TokenStore
+}
+
+func NewShared(store TokenStore) *Shared {
+	return &Shared{store: store}
+}
+
+func (s *Shared) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	allowed, tokens, err := s.store.TakeToken(ctx, key, rule.Rate, rule.Burst)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result(rule, tokens, allowed), nil
+}
+
+// purgeIdle is how long a shared bucket is kept unused. Buckets of any
+// sane rule are full long before.
+const purgeIdle = time.Hour
+
+// Run purges idle shared buckets every sweepInterval until ctx is done.
+func (s *Shared) Run(ctx context.Context, onError func(err error)) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.store.PurgeTokens(ctx, purgeIdle); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemory_Allow(t *testing.T) {
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	rule := Rule{Rate: 1, Burst: 2}
+
+	res, err := m.Allow(context.Background(), "k", rule)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Limit)
+	require.Equal(t, 1, res.Remaining)
+
+	res, _ = m.Allow(context.Background(), "k", rule)
+	require.True(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+	require.Equal(t, 2*time.Second, res.Reset)
+
+	res, _ = m.Allow(context.Background(), "k", rule)
+	require.False(t, res.Allowed)
+	require.Equal(t, time.Second, res.RetryAfter)
+
+	res, _ = m.Allow(context.Background(), "other", rule)
+	require.True(t, res.Allowed, "buckets are per key")
+
+	now = now.Add(time.Second)
+
+	res, _ = m.Allow(context.Background(), "k", rule)
+	require.True(t, res.Allowed, "a token is refilled after 1/rate seconds")
+}
+
+func TestMemory_Sweep(t *testing.T) {
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	_, _ = m.Allow(context.Background(), "k", Rule{Rate: 1, Burst: 2})
+	require.Len(t, m.buckets, 1)
+
+	now = now.Add(2 * sweepInterval)
+
+	_, _ = m.Allow(context.Background(), "other", Rule{Rate: 1, Burst: 2})
+	require.Len(t, m.buckets, 1)
+	require.Contains(t, m.buckets, "other")
+}
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy(Rule{Rate: 1, Burst: 1})
+	require.True(t, p.Rule().Enabled())
+
+	p.Set(Rule{})
+	require.False(t, p.Rule().Enabled())
+}
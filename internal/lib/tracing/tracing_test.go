@@ -0,0 +1,19 @@
+package tracing_test
+
+import (
+	"avito_tech/internal/lib/tracing"
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []string{tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP} {
+		shutdown, err := tracing.Setup(context.Background(), "test", exporter, "localhost:4318", 1)
+		require.NoError(t, err, exporter)
+		require.NoError(t, shutdown(context.Background()), exporter)
+	}
+
+	_, err := tracing.Setup(context.Background(), "test", "jaeger", "", 1)
+	require.Error(t, err)
+}
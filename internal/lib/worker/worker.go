@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// Group tracks background tasks started by handlers, so that main can wait
+// for them on shutdown instead of killing them mid-way.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func New() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs task in the background. The context given to task is cancelled
+// when Shutdown stops waiting; a task must then persist whatever it has not
+// done yet and return. Once the group is shutting down, task runs inline
+// with an already cancelled context.
+func (g *Group) Go(task func(ctx context.Context)) {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		task(ctx)
+		return
+	}
+	g.wg.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer g.wg.Done()
+		task(g.ctx)
+	}()
+}
+
+// Shutdown stops accepting tasks and waits for running ones until ctx is
+// done. After that their context is cancelled and Shutdown waits for them to
+// persist their work and return. The error is ctx.Err() if tasks had to be
+// cancelled.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		g.cancel()
+		return nil
+	case <-ctx.Done():
+		g.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
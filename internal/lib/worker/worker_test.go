@@ -0,0 +1,57 @@
+package worker_test
+
+import (
+	"avito_tech/internal/lib/worker"
+	"context"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Run("waits for tasks", func(t *testing.T) {
+		group := worker.New()
+
+		var finished atomic.Bool
+		group.Go(func(ctx context.Context) {
+			time.Sleep(time.Millisecond * 50)
+			finished.Store(ctx.Err() == nil)
+		})
+
+		err := group.Shutdown(context.Background())
+		require.NoError(t, err)
+		require.True(t, finished.Load())
+	})
+
+	t.Run("cancels tasks after deadline", func(t *testing.T) {
+		group := worker.New()
+
+		var persisted atomic.Bool
+		group.Go(func(ctx context.Context) {
+			<-ctx.Done()
+			persisted.Store(true)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		err := group.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.True(t, persisted.Load())
+	})
+
+	t.Run("runs late tasks inline with cancelled context", func(t *testing.T) {
+		group := worker.New()
+
+		err := group.Shutdown(context.Background())
+		require.NoError(t, err)
+
+		var cancelled bool
+		group.Go(func(ctx context.Context) {
+			cancelled = ctx.Err() != nil
+		})
+
+		require.True(t, cancelled)
+	})
+}
@@ -0,0 +1,465 @@
+package instrumented
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/http_server/server"
+	"avito_tech/internal/jobs/erasure"
+	"avito_tech/internal/jobs/idempotency"
+	"avito_tech/internal/jobs/notify"
+	"avito_tech/internal/lib/geo"
+	"avito_tech/internal/lib/ratelimit"
+	"avito_tech/internal/lib/tracing"
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/codes"
+	"time"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "storage_query_duration_seconds",
+		Help:    "Duration of storage calls by method.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 3},
+	}, []string{"method"})
+
+	queryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "storage_query_errors_total",
+		Help: "Failed storage calls by method and storage error kind.",
+	}, []string{"method", "kind"})
+
+	// notifications counts notifications by the status they were stored
+	// with: sent, failed, or pending (queued for jobs/notify).
+	notifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_total",
+		Help: "Notifications by delivery status.",
+	}, []string{"status"})
+)
+
+// Backend is everything the service calls on the storage.
+type Backend interface {
+	server.Storage
+	erasure.Storage
+	notify.Storage
+	idempotency.Storage
+	ratelimit.TokenStore
+	EnsureAdmin(ctx context.Context, user entity.User) (bool, error)
+	Ping(ctx context.Context) error
+	CheckMigrations(ctx context.Context) error
+}
+
+// Storage decorates a Backend with a span and duration and error metrics
+// per method.
+type Storage struct {
+	next Backend
+}
+
+var _ Backend = (*Storage)(nil)
+
+func New(next Backend) *Storage {
+	return &Storage{next: next}
+}
+
+// start opens a span for a storage call. The returned func ends it and
+// records the duration and error of the call.
+func (s *Storage) start(ctx context.Context, method string) (context.Context, func(err *error)) {
+	ctx, span := tracing.Tracer().Start(ctx, "storage."+method)
+	start := time.Now()
+
+	return ctx, func(err *error) {
+		queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+		if *err != nil {
+			queryErrors.WithLabelValues(method, kind(*err)).Inc()
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, kind(*err))
+		}
+
+		span.End()
+	}
+}
+
+func kind(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, storage.ErrConflict):
+		return "conflict"
+	case errors.Is(err, storage.ErrConstraint):
+		return "constraint"
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return "version_mismatch"
+	case errors.Is(err, storage.ErrTemporary):
+		return "temporary"
+	default:
+		return "other"
+	}
+}
+
+func (s *Storage) ExportUser(ctx context.Context, userID uuid.UUID) (_ entity.UserExport, err error) {
+	ctx, end := s.start(ctx, "ExportUser")
+	defer end(&err)
+
+	return s.next.ExportUser(ctx, userID)
+}
+
+func (s *Storage) RequestErasure(ctx context.Context, userID uuid.UUID) (err error) {
+	ctx, end := s.start(ctx, "RequestErasure")
+	defer end(&err)
+
+	return s.next.RequestErasure(ctx, userID)
+}
+
+func (s *Storage) PendingErasures(ctx context.Context, limit int) (_ []uuid.UUID, err error) {
+	ctx, end := s.start(ctx, "PendingErasures")
+	defer end(&err)
+
+	return s.next.PendingErasures(ctx, limit)
+}
+
+func (s *Storage) EraseUser(ctx context.Context, userID uuid.UUID) (err error) {
+	ctx, end := s.start(ctx, "EraseUser")
+	defer end(&err)
+
+	return s.next.EraseUser(ctx, userID)
+}
+
+func (s *Storage) SearchUsers(ctx context.Context, actorID uuid.UUID, email string) (_ []entity.User, err error) {
+	ctx, end := s.start(ctx, "SearchUsers")
+	defer end(&err)
+
+	return s.next.SearchUsers(ctx, actorID, email)
+}
+
+func (s *Storage) GetUserFlats(ctx context.Context, actorID, userID uuid.UUID) (_ []entity.Flat, err error) {
+	ctx, end := s.start(ctx, "GetUserFlats")
+	defer end(&err)
+
+	return s.next.GetUserFlats(ctx, actorID, userID)
+}
+
+func (s *Storage) GetUserSessions(ctx context.Context, actorID, userID uuid.UUID) (_ []entity.Session, err error) {
+	ctx, end := s.start(ctx, "GetUserSessions")
+	defer end(&err)
+
+	return s.next.GetUserSessions(ctx, actorID, userID)
+}
+
+func (s *Storage) EnsureAdmin(ctx context.Context, user entity.User) (_ bool, err error) {
+	ctx, end := s.start(ctx, "EnsureAdmin")
+	defer end(&err)
+
+	return s.next.EnsureAdmin(ctx, user)
+}
+
+func (s *Storage) SetUserType(ctx context.Context, actorID, userID uuid.UUID, userType string) (err error) {
+	ctx, end := s.start(ctx, "SetUserType")
+	defer end(&err)
+
+	return s.next.SetUserType(ctx, actorID, userID, userType)
+}
+
+func (s *Storage) SetUserStatus(ctx context.Context, actorID, userID uuid.UUID, status string) (err error) {
+	ctx, end := s.start(ctx, "SetUserStatus")
+	defer end(&err)
+
+	return s.next.SetUserStatus(ctx, actorID, userID, status)
+}
+
+func (s *Storage) ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (_ uuid.UUID, err error) {
+	ctx, end := s.start(ctx, "ForcePasswordReset")
+	defer end(&err)
+
+	return s.next.ForcePasswordReset(ctx, actorID, userID)
+}
+
+func (s *Storage) ClaimNotifications(ctx context.Context, limit int, timeout time.Duration) (_ []entity.Notification, err error) {
+	ctx, end := s.start(ctx, "ClaimNotifications")
+	defer end(&err)
+
+	return s.next.ClaimNotifications(ctx, limit, timeout)
+}
+
+func (s *Storage) SetNotificationStatus(ctx context.Context, id int64, status string) (err error) {
+	ctx, end := s.start(ctx, "SetNotificationStatus")
+	defer end(&err)
+
+	if err = s.next.SetNotificationStatus(ctx, id, status); err == nil {
+		notifications.WithLabelValues(status).Inc()
+	}
+
+	return err
+}
+
+func (s *Storage) Ping(ctx context.Context) (err error) {
+	ctx, end := s.start(ctx, "Ping")
+	defer end(&err)
+
+	return s.next.Ping(ctx)
+}
+
+func (s *Storage) CheckMigrations(ctx context.Context) (err error) {
+	ctx, end := s.start(ctx, "CheckMigrations")
+	defer end(&err)
+
+	return s.next.CheckMigrations(ctx)
+}
+
+func (s *Storage) CreateUser(ctx context.Context, user entity.User) (_ uuid.UUID, err error) {
+	ctx, end := s.start(ctx, "CreateUser")
+	defer end(&err)
+
+	return s.next.CreateUser(ctx, user)
+}
+
+func (s *Storage) CreateH(ctx context.Context, house entity.House) (_ int64, err error) {
+	ctx, end := s.start(ctx, "CreateH")
+	defer end(&err)
+
+	return s.next.CreateH(ctx, house)
+}
+
+func (s *Storage) GetHouse(ctx context.Context, id int64) (_ entity.House, err error) {
+	ctx, end := s.start(ctx, "GetHouse")
+	defer end(&err)
+
+	return s.next.GetHouse(ctx, id)
+}
+
+func (s *Storage) UpdateHouse(ctx context.Context, patch entity.HousePatch) (_ entity.House, err error) {
+	ctx, end := s.start(ctx, "UpdateHouse")
+	defer end(&err)
+
+	return s.next.UpdateHouse(ctx, patch)
+}
+
+func (s *Storage) CreateDeveloper(ctx context.Context, developer entity.Developer) (_ entity.Developer, err error) {
+	ctx, end := s.start(ctx, "CreateDeveloper")
+	defer end(&err)
+
+	return s.next.CreateDeveloper(ctx, developer)
+}
+
+func (s *Storage) EnsureDeveloper(ctx context.Context, name string) (_ entity.Developer, err error) {
+	ctx, end := s.start(ctx, "EnsureDeveloper")
+	defer end(&err)
+
+	return s.next.EnsureDeveloper(ctx, name)
+}
+
+func (s *Storage) GetDeveloper(ctx context.Context, id int64) (_ entity.Developer, err error) {
+	ctx, end := s.start(ctx, "GetDeveloper")
+	defer end(&err)
+
+	return s.next.GetDeveloper(ctx, id)
+}
+
+func (s *Storage) GetAllFlats(ctx context.Context, id int64, role string) (_ []entity.Flat, err error) {
+	ctx, end := s.start(ctx, "GetAllFlats")
+	defer end(&err)
+
+	return s.next.GetAllFlats(ctx, id, role)
+}
+
+func (s *Storage) CreateF(ctx context.Context, flat entity.Flat) (_ int64, err error) {
+	ctx, end := s.start(ctx, "CreateF")
+	defer end(&err)
+
+	return s.next.CreateF(ctx, flat)
+}
+
+func (s *Storage) GetFlat(ctx context.Context, id int64) (_ entity.Flat, err error) {
+	ctx, end := s.start(ctx, "GetFlat")
+	defer end(&err)
+
+	return s.next.GetFlat(ctx, id)
+}
+
+func (s *Storage) Update(ctx context.Context, patch entity.FlatPatch, idMod uuid.UUID) (_ entity.Flat, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+
+	return s.next.Update(ctx, patch, idMod)
+}
+
+func (s *Storage) Register(ctx context.Context, user entity.User) (_ string, err error) {
+	ctx, end := s.start(ctx, "Register")
+	defer end(&err)
+
+	return s.next.Register(ctx, user)
+}
+
+func (s *Storage) Login(ctx context.Context, email string) (_ entity.User, err error) {
+	ctx, end := s.start(ctx, "Login")
+	defer end(&err)
+
+	return s.next.Login(ctx, email)
+}
+
+func (s *Storage) SessionUser(ctx context.Context, sessionID, userID uuid.UUID) (_ entity.User, err error) {
+	ctx, end := s.start(ctx, "SessionUser")
+	defer end(&err)
+
+	return s.next.SessionUser(ctx, sessionID, userID)
+}
+
+func (s *Storage) CreateSession(ctx context.Context, session entity.Session) (_ uuid.UUID, err error) {
+	ctx, end := s.start(ctx, "CreateSession")
+	defer end(&err)
+
+	return s.next.CreateSession(ctx, session)
+}
+
+func (s *Storage) ResetPassword(ctx context.Context, token uuid.UUID, password string) (err error) {
+	ctx, end := s.start(ctx, "ResetPassword")
+	defer end(&err)
+
+	return s.next.ResetPassword(ctx, token, password)
+}
+
+func (s *Storage) Subscribe(ctx context.Context, sub entity.Subscription) (err error) {
+	ctx, end := s.start(ctx, "Subscribe")
+	defer end(&err)
+
+	return s.next.Subscribe(ctx, sub)
+}
+
+func (s *Storage) GetSubscribers(ctx context.Context, houseID int64) (_ []string, err error) {
+	ctx, end := s.start(ctx, "GetSubscribers")
+	defer end(&err)
+
+	return s.next.GetSubscribers(ctx, houseID)
+}
+
+func (s *Storage) SaveNotification(ctx context.Context, n entity.Notification) (err error) {
+	ctx, end := s.start(ctx, "SaveNotification")
+	defer end(&err)
+
+	if err = s.next.SaveNotification(ctx, n); err == nil {
+		notifications.WithLabelValues(n.Status).Inc()
+	}
+
+	return err
+}
+
+func (s *Storage) TakeToken(ctx context.Context, key string, rate float64, burst int) (_ bool, _ float64, err error) {
+	ctx, end := s.start(ctx, "TakeToken")
+	defer end(&err)
+
+	return s.next.TakeToken(ctx, key, rate, burst)
+}
+
+func (s *Storage) PurgeTokens(ctx context.Context, idle time.Duration) (err error) {
+	ctx, end := s.start(ctx, "PurgeTokens")
+	defer end(&err)
+
+	return s.next.PurgeTokens(ctx, idle)
+}
+
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, ttl, lease time.Duration) (_ entity.IdempotentResponse, _ bool, err error) {
+	ctx, end := s.start(ctx, "ReserveIdempotencyKey")
+	defer end(&err)
+
+	return s.next.ReserveIdempotencyKey(ctx, userID, key, fingerprint, ttl, lease)
+}
+
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, resp entity.IdempotentResponse) (err error) {
+	ctx, end := s.start(ctx, "SaveIdempotentResponse")
+	defer end(&err)
+
+	return s.next.SaveIdempotentResponse(ctx, userID, key, resp)
+}
+
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservedAt time.Time) (err error) {
+	ctx, end := s.start(ctx, "ReleaseIdempotencyKey")
+	defer end(&err)
+
+	return s.next.ReleaseIdempotencyKey(ctx, userID, key, reservedAt)
+}
+
+func (s *Storage) PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration) (err error) {
+	ctx, end := s.start(ctx, "PurgeIdempotencyKeys")
+	defer end(&err)
+
+	return s.next.PurgeIdempotencyKeys(ctx, ttl)
+}
+
+func (s *Storage) PriceHistory(ctx context.Context, flatID int64) (_ []entity.PricePoint, err error) {
+	ctx, end := s.start(ctx, "PriceHistory")
+	defer end(&err)
+
+	return s.next.PriceHistory(ctx, flatID)
+}
+
+func (s *Storage) SearchFlats(ctx context.Context, filter entity.FlatFilter) (_ entity.FlatPage, err error) {
+	ctx, end := s.start(ctx, "SearchFlats")
+	defer end(&err)
+
+	return s.next.SearchFlats(ctx, filter)
+}
+
+func (s *Storage) SimilarHouses(ctx context.Context, addr, postcode string) (_ []entity.House, err error) {
+	ctx, end := s.start(ctx, "SimilarHouses")
+	defer end(&err)
+
+	return s.next.SimilarHouses(ctx, addr, postcode)
+}
+
+func (s *Storage) SearchHouses(ctx context.Context, query string, limit int) (_ []entity.House, err error) {
+	ctx, end := s.start(ctx, "SearchHouses")
+	defer end(&err)
+
+	return s.next.SearchHouses(ctx, query, limit)
+}
+
+func (s *Storage) NearbyHouses(ctx context.Context, center geo.Point, radius float64, limit int) (_ []entity.NearbyHouse, err error) {
+	ctx, end := s.start(ctx, "NearbyHouses")
+	defer end(&err)
+
+	return s.next.NearbyHouses(ctx, center, radius, limit)
+}
+
+func (s *Storage) HousesInBox(ctx context.Context, box geo.Box, limit int) (_ []entity.House, err error) {
+	ctx, end := s.start(ctx, "HousesInBox")
+	defer end(&err)
+
+	return s.next.HousesInBox(ctx, box, limit)
+}
+
+func (s *Storage) AddPhoto(ctx context.Context, photo entity.Photo, limit int) (_ entity.Photo, err error) {
+	ctx, end := s.start(ctx, "AddPhoto")
+	defer end(&err)
+
+	return s.next.AddPhoto(ctx, photo, limit)
+}
+
+func (s *Storage) FlatPhotos(ctx context.Context, flatID int64) (_ []entity.Photo, err error) {
+	ctx, end := s.start(ctx, "FlatPhotos")
+	defer end(&err)
+
+	return s.next.FlatPhotos(ctx, flatID)
+}
+
+func (s *Storage) GetPhoto(ctx context.Context, flatID, photoID int64) (_ entity.Photo, err error) {
+	ctx, end := s.start(ctx, "GetPhoto")
+	defer end(&err)
+
+	return s.next.GetPhoto(ctx, flatID, photoID)
+}
+
+func (s *Storage) DeletePhoto(ctx context.Context, flatID, photoID int64) (_ entity.Photo, err error) {
+	ctx, end := s.start(ctx, "DeletePhoto")
+	defer end(&err)
+
+	return s.next.DeletePhoto(ctx, flatID, photoID)
+}
+
+func (s *Storage) ReorderPhotos(ctx context.Context, flatID int64, ids []int64) (_ []entity.Photo, err error) {
+	ctx, end := s.start(ctx, "ReorderPhotos")
+	defer end(&err)
+
+	return s.next.ReorderPhotos(ctx, flatID, ids)
+}
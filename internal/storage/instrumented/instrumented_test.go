@@ -0,0 +1,79 @@
+package instrumented
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// backend implements only the methods under test, the rest panic.
+type backend struct {
+	Backend
+	err error
+}
+
+func (b *backend) CreateF(ctx context.Context, flat entity.Flat) (int64, error) {
+	return 1, b.err
+}
+
+func (b *backend) SaveNotification(ctx context.Context, n entity.Notification) error {
+	return b.err
+}
+
+func TestObserve(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind string
+	}{
+		{
+			name: "ok",
+		},
+		{
+			name: "conflict",
+			err:  fmt.Errorf("wrapped: %w", storage.ErrConflict),
+			kind: "conflict",
+		},
+		{
+			name: "unknown",
+			err:  errors.New("mock error"),
+			kind: "other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(&backend{err: tt.err})
+
+			var before float64
+			if tt.kind != "" {
+				before = testutil.ToFloat64(queryErrors.WithLabelValues("CreateF", tt.kind))
+			}
+
+			id, err := s.CreateF(context.Background(), entity.Flat{})
+			require.Equal(t, int64(1), id)
+			require.Equal(t, tt.err, err)
+
+			if tt.kind != "" {
+				require.Equal(t, before+1, testutil.ToFloat64(queryErrors.WithLabelValues("CreateF", tt.kind)))
+			}
+		})
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	before := testutil.ToFloat64(notifications.WithLabelValues("pending"))
+
+	s := New(&backend{})
+	require.NoError(t, s.SaveNotification(context.Background(), entity.Notification{Status: "pending"}))
+	require.Equal(t, before+1, testutil.ToFloat64(notifications.WithLabelValues("pending")))
+
+	s = New(&backend{err: errors.New("mock error")})
+	require.Error(t, s.SaveNotification(context.Background(), entity.Notification{Status: "pending"}))
+	require.Equal(t, before+1, testutil.ToFloat64(notifications.WithLabelValues("pending")))
+}
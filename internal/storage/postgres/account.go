@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// erasedPassword is stored instead of the bcrypt hash of an erased user. It is
+// not a valid hash, so no password can ever match it again.
+const erasedPassword = "erased"
+
+func (s *Storage) ExportUser(ctx context.Context, userID uuid.UUID) (entity.UserExport, error) {
+	const fn = "storage.postgres.ExportUser"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer tx.Rollback(ctx)
+
+	export := entity.UserExport{
+		Flats:         []entity.Flat{},
+		Subscriptions: []entity.Subscription{},
+		Notifications: []entity.Notification{},
+		ExportedAt:    time.Now().UTC(),
+	}
+
+	user := &export.User
+	err = tx.QueryRow(ctx, `
+		SELECT id, email, user_type, status, reset_token IS NOT NULL, created_at
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&user.ID, &user.Email, &user.UserType, &user.Status, &user.ResetRequired, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT `+flatColumns+`
+		FROM flats
+		WHERE user_id = $1
+		ORDER BY id
+	`, userID)
+	if err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	for rows.Next() {
+		flat, err := scanFlat(rows)
+		if err != nil {
+			rows.Close()
+			return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		export.Flats = append(export.Flats, flat)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT house_id, email, created_at
+		FROM subscriptions
+		WHERE email = $1
+		ORDER BY id
+	`, user.Email)
+	if err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	for rows.Next() {
+		var sub entity.Subscription
+		if err = rows.Scan(&sub.HouseID, &sub.Email, &sub.CreatedAt); err != nil {
+			rows.Close()
+			return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		export.Subscriptions = append(export.Subscriptions, sub)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT id, house_id, email, message, status, created_at
+		FROM notifications
+		WHERE email = $1
+		ORDER BY id
+	`, user.Email)
+	if err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	for rows.Next() {
+		var n entity.Notification
+		if err = rows.Scan(&n.ID, &n.HouseID, &n.Email, &n.Message, &n.Status, &n.CreatedAt); err != nil {
+			rows.Close()
+			return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		export.Notifications = append(export.Notifications, n)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return entity.UserExport{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return export, nil
+}
+
+func (s *Storage) RequestErasure(ctx context.Context, userID uuid.UUID) error {
+	const fn = "storage.postgres.RequestErasure"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.Insert("erasure_requests").
+		Columns("user_id").
+		Values(userID).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	_, err = s.db.Exec(ctx, query, args...)
+
+	err = classify(err)
+	if errors.Is(err, storage.ErrConflict) {
+		// A pending request already exists for this user.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) PendingErasures(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	const fn = "storage.postgres.PendingErasures"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.Select("user_id").
+		From("erasure_requests").
+		Where(squirrel.Eq{"processed_at": nil}).
+		OrderBy("requested_at").
+		Limit(uint64(limit)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return ids, nil
+}
+
+// EraseUser anonymises the account and everything keyed by its email. Flats
+// are kept, still referencing the (now anonymous) users row, so moderation
+// history stays intact.
+func (s *Storage) EraseUser(ctx context.Context, userID uuid.UUID) error {
+	const fn = "storage.postgres.EraseUser"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var email string
+	err = tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", fn, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	anonymous := fmt.Sprintf("erased-%s@erased.invalid", userID)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET email = $2, password = $3, status = 'erased', reset_token = NULL, reset_expires_at = NULL
+		WHERE id = $1
+	`, userID, anonymous, erasedPassword)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE subscriptions SET email = $2 WHERE email = $1`, email, anonymous); err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE notifications SET email = $2 WHERE email = $1`, email, anonymous); err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = revokeSessions(ctx, tx, userID); err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE erasure_requests
+		SET processed_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND processed_at IS NULL
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
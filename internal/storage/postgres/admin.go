@@ -0,0 +1,415 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+const searchUsersLimit = 50
+
+// resetTokenTTL bounds how long a token issued by ForcePasswordReset stays valid.
+const resetTokenTTL = 24 * time.Hour
+
+// Every admin method runs in its own transaction together with the audit_log
+// insert, so an action is never applied without being recorded.
+
+func (s *Storage) SearchUsers(ctx context.Context, actorID uuid.UUID, email string) ([]entity.User, error) {
+	const fn = "storage.postgres.SearchUsers"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var users []entity.User
+
+	// The audit entry outlives an erasure of the users it found, so it keeps
+	// a hash of the query and the ids of the matches, never an email.
+	details := map[string]string{"query_sha256": hashQuery(email)}
+
+	err := s.withAudit(ctx, actorID, "search_users", uuid.Nil, details, func(tx pgx.Tx) error {
+		query, args, err := squirrel.Select("id", "email", "user_type", "status", "reset_token IS NOT NULL", "created_at").
+			From("users").
+			Where(squirrel.ILike{"email": "%" + escapeLike(email) + "%"}).
+			OrderBy("email").
+			Limit(searchUsersLimit).
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var user entity.User
+			err = rows.Scan(&user.ID, &user.Email, &user.UserType, &user.Status, &user.ResetRequired, &user.CreatedAt)
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		details["user_ids"] = userIDs(users)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return users, nil
+}
+
+func (s *Storage) GetUserFlats(ctx context.Context, actorID, userID uuid.UUID) ([]entity.Flat, error) {
+	const fn = "storage.postgres.GetUserFlats"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var flats []entity.Flat
+
+	err := s.withAudit(ctx, actorID, "view_flats", userID, nil, func(tx pgx.Tx) error {
+		if err := userExists(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		query, args, err := squirrel.Select(flatColumns).
+			From("flats").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("id").
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			flat, err := scanFlat(rows)
+			if err != nil {
+				return err
+			}
+			flats = append(flats, flat)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return flats, nil
+}
+
+func (s *Storage) GetUserSessions(ctx context.Context, actorID, userID uuid.UUID) ([]entity.Session, error) {
+	const fn = "storage.postgres.GetUserSessions"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var sessions []entity.Session
+
+	err := s.withAudit(ctx, actorID, "view_sessions", userID, nil, func(tx pgx.Tx) error {
+		if err := userExists(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		query, args, err := squirrel.Select("id", "user_id", "created_at", "expires_at", "revoked_at").
+			From("sessions").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at DESC").
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var session entity.Session
+			err = rows.Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.ExpiresAt, &session.RevokedAt)
+			if err != nil {
+				return err
+			}
+			sessions = append(sessions, session)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return sessions, nil
+}
+
+// EnsureAdmin creates user as an admin unless a user with its email exists.
+// It reports whether the user was created; an existing user that is not an
+// admin is storage.ErrConflict. The creation is audited as done by the new
+// admin itself, there is no other actor.
+func (s *Storage) EnsureAdmin(ctx context.Context, user entity.User) (bool, error) {
+	const fn = "storage.postgres.EnsureAdmin"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var userType string
+	err := s.db.QueryRow(ctx, `SELECT user_type FROM users WHERE email = $1`, user.Email).Scan(&userType)
+	if err == nil {
+		if userType != "admin" {
+			return false, fmt.Errorf("%s: %s is a %s: %w", fn, user.Email, userType, storage.ErrConflict)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email, password, user_type)
+		VALUES ($1, $2, 'admin')
+		RETURNING id
+	`, user.Email, user.Password).Scan(&id)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = insertAudit(ctx, tx, id, "bootstrap_admin", id, nil); err != nil {
+		return false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return true, nil
+}
+
+func (s *Storage) SetUserType(ctx context.Context, actorID, userID uuid.UUID, userType string) error {
+	const fn = "storage.postgres.SetUserType"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	err := s.withAudit(ctx, actorID, "change_role", userID, map[string]string{"user_type": userType}, func(tx pgx.Tx) error {
+		if err := updateUser(ctx, tx, userID, map[string]interface{}{"user_type": userType}); err != nil {
+			return err
+		}
+
+		// The user logs in again to get a token for the new role.
+		return revokeSessions(ctx, tx, userID)
+	})
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
+
+func (s *Storage) SetUserStatus(ctx context.Context, actorID, userID uuid.UUID, status string) error {
+	const fn = "storage.postgres.SetUserStatus"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	err := s.withAudit(ctx, actorID, "set_status", userID, map[string]string{"status": status}, func(tx pgx.Tx) error {
+		if err := updateUser(ctx, tx, userID, map[string]interface{}{"status": status}); err != nil {
+			return err
+		}
+
+		if status == "active" {
+			return nil
+		}
+
+		return revokeSessions(ctx, tx, userID)
+	})
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
+
+func (s *Storage) ForcePasswordReset(ctx context.Context, actorID, userID uuid.UUID) (uuid.UUID, error) {
+	const fn = "storage.postgres.ForcePasswordReset"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	token := uuid.New()
+
+	err := s.withAudit(ctx, actorID, "force_password_reset", userID, nil, func(tx pgx.Tx) error {
+		if err := updateUser(ctx, tx, userID, map[string]interface{}{
+			"reset_token":      token,
+			"reset_expires_at": time.Now().Add(resetTokenTTL),
+		}); err != nil {
+			return err
+		}
+
+		return revokeSessions(ctx, tx, userID)
+	})
+
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return token, nil
+}
+
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(query)))
+	return hex.EncodeToString(sum[:])
+}
+
+func userIDs(users []entity.User) string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID.String())
+	}
+
+	return strings.Join(ids, ",")
+}
+
+func (s *Storage) withAudit(ctx context.Context, actorID uuid.UUID, action string, targetID uuid.UUID, details map[string]string, do func(tx pgx.Tx) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = do(tx); err != nil {
+		return err
+	}
+
+	if err = insertAudit(ctx, tx, actorID, action, targetID, details); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertAudit(ctx context.Context, tx pgx.Tx, actorID uuid.UUID, action string, targetID uuid.UUID, details map[string]string) error {
+	var target interface{}
+	if targetID != uuid.Nil {
+		target = targetID
+	}
+
+	var (
+		rawDetails interface{}
+		err        error
+	)
+	if len(details) != 0 {
+		if rawDetails, err = json.Marshal(details); err != nil {
+			return err
+		}
+	}
+
+	query, args, err := squirrel.Insert("audit_log").
+		Columns("actor_id", "action", "target_id", "details").
+		Values(actorID, action, target, rawDetails).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, args...)
+
+	return err
+}
+
+func userExists(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	var exists bool
+
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func updateUser(ctx context.Context, tx pgx.Tx, userID uuid.UUID, set map[string]interface{}) error {
+	query, args, err := squirrel.Update("users").
+		SetMap(set).
+		Where(squirrel.Eq{"id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func revokeSessions(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+
+	return err
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
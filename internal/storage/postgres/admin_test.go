@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestSearchAuditDetails checks that the search_users audit entry cannot
+// reveal the email of a user erased later.
+func TestSearchAuditDetails(t *testing.T) {
+	email := "Ivan@Example.com"
+	first, second := uuid.New(), uuid.New()
+
+	details := map[string]string{
+		"query_sha256": hashQuery(email),
+		"user_ids":     userIDs([]entity.User{{ID: first, Email: email}, {ID: second}}),
+	}
+
+	for _, value := range details {
+		require.NotContains(t, value, "ivan")
+		require.NotContains(t, value, "Ivan")
+	}
+
+	require.Equal(t, hashQuery("ivan@example.com"), details["query_sha256"])
+	require.Len(t, details["query_sha256"], 64)
+	require.Equal(t, first.String()+","+second.String(), details["user_ids"])
+	require.Empty(t, userIDs(nil))
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateDeveloper adds a developer and returns it as stored. Names are
+// unique regardless of case, a taken one is storage.ErrConflict.
+func (s *Storage) CreateDeveloper(ctx context.Context, developer entity.Developer) (entity.Developer, error) {
+	const fn = "storage.postgres.CreateDeveloper"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO developers (name, website)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`, developer.Name, nullString(developer.Website)).Scan(&developer.ID, &developer.CreatedAt)
+	if err != nil {
+		return entity.Developer{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return developer, nil
+}
+
+// ensureDeveloperQuery returns the developer named $1 regardless of case,
+// adding it first if there is none.
+const ensureDeveloperQuery = `
+	WITH added AS (
+		INSERT INTO developers (name)
+		VALUES ($1)
+		ON CONFLICT ((lower(name))) DO NOTHING
+		RETURNING id, name, website, created_at
+	)
+	SELECT id, name, website, created_at FROM added
+	UNION ALL
+	SELECT id, name, website, created_at FROM developers WHERE lower(name) = lower($1)
+	LIMIT 1
+`
+
+// EnsureDeveloper returns the developer with name, creating it if needed.
+func (s *Storage) EnsureDeveloper(ctx context.Context, name string) (entity.Developer, error) {
+	const fn = "storage.postgres.EnsureDeveloper"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		developer entity.Developer
+		website   *string
+		err       error
+	)
+
+	// A developer added by a concurrent call is not visible to the snapshot
+	// of the statement that waited for it, the second attempt sees it.
+	for attempt := 0; attempt < 2; attempt++ {
+		err = s.db.QueryRow(ctx, ensureDeveloperQuery, name).Scan(&developer.ID, &developer.Name, &website, &developer.CreatedAt)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+	}
+	if err != nil {
+		return entity.Developer{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if website != nil {
+		developer.Website = *website
+	}
+
+	return developer, nil
+}
+
+func (s *Storage) GetDeveloper(ctx context.Context, id int64) (entity.Developer, error) {
+	const fn = "storage.postgres.GetDeveloper"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		developer entity.Developer
+		website   *string
+	)
+
+	err := s.db.QueryRow(ctx, `
+		SELECT id, name, website, created_at
+		FROM developers
+		WHERE id = $1
+	`, id).Scan(&developer.ID, &developer.Name, &website, &developer.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Developer{}, fmt.Errorf("%s: developer %d: %w", fn, id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.Developer{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if website != nil {
+		developer.Website = *website
+	}
+
+	return developer, nil
+}
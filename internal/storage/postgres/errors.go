@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeCheckViolation       = "23514"
+	codeNotNullViolation     = "23502"
+	codeStringTooLong        = "22001"
+	codeNumericOutOfRange    = "22003"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+	codeLockNotAvailable     = "55P03"
+	codeTooManyConnections   = "53300"
+	codeAdminShutdown        = "57P01"
+	codeCannotConnectNow     = "57P03"
+)
+
+// classify maps err onto the sentinels of the storage package so callers can
+// match it with errors.Is. The original error stays in the chain for logs.
+// Errors that are already classified, or unknown, are returned unchanged.
+func classify(err error) error {
+	if err == nil || isClassified(err) {
+		return err
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %w", storage.ErrNotFound, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case codeUniqueViolation:
+			return fmt.Errorf("%w: %w", storage.ErrConflict, err)
+		case codeForeignKeyViolation, codeCheckViolation, codeNotNullViolation,
+			codeStringTooLong, codeNumericOutOfRange:
+			return fmt.Errorf("%w: %w", storage.ErrConstraint, err)
+		case codeSerializationFailure, codeDeadlockDetected, codeLockNotAvailable,
+			codeTooManyConnections, codeAdminShutdown, codeCannotConnectNow:
+			return fmt.Errorf("%w: %w", storage.ErrTemporary, err)
+		}
+
+		return err
+	}
+
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", storage.ErrTemporary, err)
+	}
+
+	return err
+}
+
+func isClassified(err error) bool {
+	return errors.Is(err, storage.ErrNotFound) ||
+		errors.Is(err, storage.ErrConflict) ||
+		errors.Is(err, storage.ErrConstraint) ||
+		errors.Is(err, storage.ErrVersionMismatch) ||
+		errors.Is(err, storage.ErrTemporary)
+}
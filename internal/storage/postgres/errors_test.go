@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "no rows",
+			err:      pgx.ErrNoRows,
+			expected: storage.ErrNotFound,
+		},
+		{
+			name:     "unique violation",
+			err:      &pgconn.PgError{Code: codeUniqueViolation},
+			expected: storage.ErrConflict,
+		},
+		{
+			name:     "foreign key violation",
+			err:      &pgconn.PgError{Code: codeForeignKeyViolation},
+			expected: storage.ErrConstraint,
+		},
+		{
+			name:     "check violation",
+			err:      fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: codeCheckViolation}),
+			expected: storage.ErrConstraint,
+		},
+		{
+			name:     "serialization failure",
+			err:      &pgconn.PgError{Code: codeSerializationFailure},
+			expected: storage.ErrTemporary,
+		},
+		{
+			name:     "deadline exceeded",
+			err:      context.DeadlineExceeded,
+			expected: storage.ErrTemporary,
+		},
+		{
+			name:     "already classified",
+			err:      storage.ErrUserNotFound,
+			expected: storage.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := classify(tt.err)
+
+			require.ErrorIs(t, err, tt.expected)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		err := errors.New("mock error")
+		require.Equal(t, err, classify(err))
+
+		pgErr := &pgconn.PgError{Code: "42P01"}
+		require.Equal(t, error(pgErr), classify(pgErr))
+
+		require.NoError(t, classify(nil))
+	})
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/geo"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"strconv"
+)
+
+// distanceExpr is the haversine distance in meters from the point ($1, $2)
+// to a house. It is plain SQL, PostGIS is not needed.
+var distanceExpr = `2 * ` + strconv.FormatFloat(geo.EarthRadius, 'f', -1, 64) + ` * asin(sqrt(least(1,
+	power(sin(radians(lat - ?) / 2), 2) +
+	cos(radians(?)) * cos(radians(lat)) * power(sin(radians(lon - ?) / 2), 2)
+)))`
+
+// NearbyHouses returns houses with coordinates within radius meters of
+// center, nearest first. The bounding box of the circle is checked first so
+// that the index on coordinates is used and only the houses in it get their
+// distance computed.
+func (s *Storage) NearbyHouses(ctx context.Context, center geo.Point, radius float64, limit int) ([]entity.NearbyHouse, error) {
+	const fn = "storage.postgres.NearbyHouses"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	inner := squirrel.Select(houseColumns).
+		Column(squirrel.Expr(distanceExpr+" AS distance", center.Lat, center.Lat, center.Lon)).
+		From("houses").
+		Where(boxCondition(geo.Around(center, radius)))
+
+	query, args, err := squirrel.Select("*").
+		FromSelect(inner, "h").
+		Where(squirrel.LtOrEq{"distance": radius}).
+		OrderBy("distance", "id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer rows.Close()
+
+	houses := make([]entity.NearbyHouse, 0)
+
+	for rows.Next() {
+		var nearby entity.NearbyHouse
+
+		nearby.House, err = scanHouse(rows, &nearby.Distance)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+
+		houses = append(houses, nearby)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return houses, nil
+}
+
+// HousesInBox returns houses with coordinates inside box, for a map view.
+func (s *Storage) HousesInBox(ctx context.Context, box geo.Box, limit int) ([]entity.House, error) {
+	const fn = "storage.postgres.HousesInBox"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.Select(houseColumns).
+		From("houses").
+		Where(boxCondition(box)).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	houses, err := scanHouses(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return houses, nil
+}
+
+// boxCondition selects houses inside box. Houses without coordinates never
+// match.
+func boxCondition(box geo.Box) squirrel.And {
+	where := squirrel.And{
+		squirrel.GtOrEq{"lat": box.MinLat},
+		squirrel.LtOrEq{"lat": box.MaxLat},
+	}
+
+	switch {
+	case box.MinLon == -180 && box.MaxLon == 180:
+		where = append(where, squirrel.NotEq{"lon": nil})
+	case box.MinLon <= box.MaxLon:
+		where = append(where, squirrel.GtOrEq{"lon": box.MinLon}, squirrel.LtOrEq{"lon": box.MaxLon})
+	default:
+		// The box crosses the antimeridian.
+		where = append(where, squirrel.Or{squirrel.GtOrEq{"lon": box.MinLon}, squirrel.LtOrEq{"lon": box.MaxLon}})
+	}
+
+	return where
+}
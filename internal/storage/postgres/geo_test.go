@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"avito_tech/internal/lib/geo"
+	"github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBoxCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		box      geo.Box
+		expected string
+		args     []any
+	}{
+		{
+			name:     "box",
+			box:      geo.Box{MinLat: 55, MinLon: 37, MaxLat: 56, MaxLon: 38},
+			expected: "(lat >= $1 AND lat <= $2 AND lon >= $3 AND lon <= $4)",
+			args:     []any{55.0, 56.0, 37.0, 38.0},
+		},
+		{
+			name:     "antimeridian",
+			box:      geo.Box{MinLat: 64, MinLon: 177, MaxLat: 65, MaxLon: -178},
+			expected: "(lat >= $1 AND lat <= $2 AND (lon >= $3 OR lon <= $4))",
+			args:     []any{64.0, 65.0, 177.0, -178.0},
+		},
+		{
+			name:     "every longitude",
+			box:      geo.Box{MinLat: 89, MinLon: -180, MaxLat: 90, MaxLon: 180},
+			expected: "(lat >= $1 AND lat <= $2 AND lon IS NOT NULL)",
+			args:     []any{89.0, 90.0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := boxCondition(tt.box).ToSql()
+			require.NoError(t, err)
+
+			sql, err = squirrel.Dollar.ReplacePlaceholders(sql)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expected, sql)
+			require.Equal(t, tt.args, args)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// reserveKeyQuery takes the key of a user for a new request. A key older
+// than the ttl in $4 seconds, or still without a response after the lease
+// in $5 seconds, is taken over as if it did not exist.
+const reserveKeyQuery = `
+	INSERT INTO idempotency_keys AS ik (user_id, key, fingerprint)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, key) DO UPDATE SET
+		fingerprint = EXCLUDED.fingerprint,
+		status = NULL,
+		content_type = '',
+		etag = '',
+		body = NULL,
+		created_at = now()
+	WHERE ik.created_at < now() - make_interval(secs => $4)
+		OR (ik.status IS NULL AND ik.created_at < now() - make_interval(secs => $5))
+	RETURNING created_at
+`
+
+// ReserveIdempotencyKey takes key for a request of userID with fingerprint.
+// If the key is already taken it returns the stored response instead and
+// reserved is false; the response has a zero Status while the request that
+// took the key is in flight, for at most lease.
+func (s *Storage) ReserveIdempotencyKey(ctx context.Context, userID uuid.UUID, key, fingerprint string, ttl, lease time.Duration) (entity.IdempotentResponse, bool, error) {
+	const fn = "storage.postgres.ReserveIdempotencyKey"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var createdAt time.Time
+
+	err := s.db.QueryRow(ctx, reserveKeyQuery, userID, key, fingerprint, ttl.Seconds(), lease.Seconds()).Scan(&createdAt)
+	if err == nil {
+		return entity.IdempotentResponse{Fingerprint: fingerprint, CreatedAt: createdAt}, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return entity.IdempotentResponse{}, false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	var (
+		resp   entity.IdempotentResponse
+		status *int
+	)
+
+	err = s.db.QueryRow(ctx, `
+		SELECT fingerprint, status, content_type, etag, body, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`, userID, key).Scan(&resp.Fingerprint, &status, &resp.ContentType, &resp.ETag, &resp.Body, &resp.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released by the request that held it in the meantime.
+		return entity.IdempotentResponse{}, false, fmt.Errorf("%s: %w", fn, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.IdempotentResponse{}, false, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if status != nil {
+		resp.Status = *status
+	}
+
+	return resp, false, nil
+}
+
+// SaveIdempotentResponse stores the response of the request that reserved
+// key at resp.CreatedAt, to be replayed for its retries. A reservation taken
+// over after its lease expired is not overwritten.
+func (s *Storage) SaveIdempotentResponse(ctx context.Context, userID uuid.UUID, key string, resp entity.IdempotentResponse) error {
+	const fn = "storage.postgres.SaveIdempotentResponse"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status = $3, content_type = $4, etag = $5, body = $6
+		WHERE user_id = $1 AND key = $2 AND status IS NULL AND created_at = $7
+	`, userID, key, resp.Status, resp.ContentType, resp.ETag, resp.Body, resp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("%s: key %q: %w", fn, key, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a key reserved at reservedAt whose request
+// failed, so that a retry runs again instead of replaying the failure.
+func (s *Storage) ReleaseIdempotencyKey(ctx context.Context, userID uuid.UUID, key string, reservedAt time.Time) error {
+	const fn = "storage.postgres.ReleaseIdempotencyKey"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND status IS NULL AND created_at = $3
+	`, userID, key, reservedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes keys older than ttl, they are not replayed
+// anymore.
+func (s *Storage) PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration) error {
+	const fn = "storage.postgres.PurgeIdempotencyKeys"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1)`, ttl.Seconds())
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,407 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockID is the key of the advisory lock held while migrations run,
+// so that several replicas starting at once do not apply them concurrently.
+const migrationLockID = 7283461
+
+// migrations are applied in order, each one exactly once. Append new schema
+// changes to the end of the list and never edit an entry that has shipped.
+var migrations = []string{
+	`
+	CREATE TABLE IF NOT EXISTS houses (
+		id INTEGER PRIMARY KEY CHECK (id >= 1),
+		address TEXT NOT NULL,
+		year INTEGER NOT NULL CHECK (year >= 0),
+		developer TEXT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		update_at TIMESTAMP NULL
+	);
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS users (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		email TEXT NOT NULL UNIQUE CHECK (length(email) > 0),
+		password TEXT NOT NULL CHECK (length(password) > 0),
+		user_type VARCHAR(50) NOT NULL DEFAULT 'client' CHECK (user_type IN ('client', 'moderator'))
+	);
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS flats (
+		id SERIAL PRIMARY KEY,
+		user_id UUID NOT NULL REFERENCES users(id),
+		house_id INTEGER NOT NULL REFERENCES houses(id),
+		number INTEGER NOT NULL CHECK (number >= 1),
+		price INTEGER NOT NULL CHECK (price >= 0),
+		rooms INTEGER NOT NULL CHECK (rooms >= 1),
+		status VARCHAR(50) NOT NULL CHECK (status IN ('created', 'approved', 'declined', 'on moderation')),
+		last_moderator_id UUID NULL
+	);
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS subscriptions (
+		id SERIAL PRIMARY KEY,
+		house_id INT NOT NULL,
+		email TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`,
+	`
+	CREATE INDEX IF NOT EXISTS idx_flats_house_id_status
+	ON flats (house_id, status);
+	`,
+	`
+	DROP TRIGGER IF EXISTS func_update_at_trigger ON flats;
+
+	CREATE OR REPLACE FUNCTION func_update_at()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		UPDATE houses
+		SET update_at = CURRENT_TIMESTAMP
+		WHERE id = NEW.house_id;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	CREATE TRIGGER func_update_at_trigger
+	AFTER INSERT ON flats
+	FOR EACH ROW
+	EXECUTE FUNCTION func_update_at();
+	`,
+	`
+	ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS status VARCHAR(50) NOT NULL DEFAULT 'active',
+		ADD COLUMN IF NOT EXISTS reset_token UUID NULL UNIQUE,
+		ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+	ALTER TABLE users
+		DROP CONSTRAINT IF EXISTS users_user_type_check,
+		ADD CONSTRAINT users_user_type_check CHECK (user_type IN ('client', 'moderator', 'admin')),
+		ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'suspended'));
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		user_id UUID NOT NULL REFERENCES users(id),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL,
+		revoked_at TIMESTAMP NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sessions_user_id
+	ON sessions (user_id);
+
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id SERIAL PRIMARY KEY,
+		actor_id UUID NOT NULL REFERENCES users(id),
+		action VARCHAR(50) NOT NULL,
+		target_id UUID NULL,
+		details JSONB NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`,
+	`
+	ALTER TABLE users
+		DROP CONSTRAINT IF EXISTS users_status_check,
+		ADD CONSTRAINT users_status_check CHECK (status IN ('active', 'suspended', 'erased'));
+
+	CREATE TABLE IF NOT EXISTS notifications (
+		id SERIAL PRIMARY KEY,
+		house_id INTEGER NOT NULL,
+		email TEXT NOT NULL,
+		message TEXT NOT NULL,
+		status VARCHAR(50) NOT NULL CHECK (status IN ('sent', 'failed')),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_email
+	ON notifications (email);
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_email
+	ON subscriptions (email);
+
+	CREATE TABLE IF NOT EXISTS erasure_requests (
+		id SERIAL PRIMARY KEY,
+		user_id UUID NOT NULL REFERENCES users(id),
+		requested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		processed_at TIMESTAMP NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_erasure_requests_pending
+	ON erasure_requests (user_id) WHERE processed_at IS NULL;
+	`,
+	`
+	ALTER TABLE notifications
+		DROP CONSTRAINT IF EXISTS notifications_status_check,
+		ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sent', 'failed'));
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_pending
+	ON notifications (id) WHERE status = 'pending';
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS rate_limits (
+		key TEXT PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		allowed BOOLEAN NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		user_id UUID NOT NULL REFERENCES users(id),
+		key TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		status INTEGER,
+		content_type TEXT NOT NULL DEFAULT '',
+		body BYTEA,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (user_id, key)
+	);
+	CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+	`,
+	`
+	ALTER TABLE flats ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+	ALTER TABLE houses ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS flat_price_history (
+		id BIGSERIAL PRIMARY KEY,
+		flat_id INTEGER NOT NULL REFERENCES flats(id),
+		price INTEGER NOT NULL,
+		changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX IF NOT EXISTS flat_price_history_flat_id_idx ON flat_price_history (flat_id, changed_at);
+
+	INSERT INTO flat_price_history (flat_id, price)
+	SELECT id, price FROM flats;
+
+	DROP TRIGGER IF EXISTS flat_price_history_trigger ON flats;
+
+	CREATE OR REPLACE FUNCTION func_flat_price_history()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		IF TG_OP = 'INSERT' OR NEW.price IS DISTINCT FROM OLD.price THEN
+			INSERT INTO flat_price_history (flat_id, price) VALUES (NEW.id, NEW.price);
+		END IF;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	CREATE TRIGGER flat_price_history_trigger
+	AFTER INSERT OR UPDATE OF price ON flats
+	FOR EACH ROW
+	EXECUTE FUNCTION func_flat_price_history();
+	`,
+	`
+	CREATE INDEX IF NOT EXISTS flats_approved_id_idx ON flats (id) WHERE status = 'approved';
+	CREATE INDEX IF NOT EXISTS flats_approved_price_idx ON flats (price, id) WHERE status = 'approved';
+	CREATE INDEX IF NOT EXISTS flats_approved_rooms_idx ON flats (rooms, id) WHERE status = 'approved';
+	CREATE INDEX IF NOT EXISTS houses_year_idx ON houses (year);
+	CREATE INDEX IF NOT EXISTS houses_developer_idx ON houses (lower(developer));
+	CREATE INDEX IF NOT EXISTS houses_address_fts_idx ON houses USING GIN (to_tsvector('russian', address));
+	`,
+	`
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+	ALTER TABLE houses
+		ADD COLUMN IF NOT EXISTS postcode TEXT NULL,
+		ADD COLUMN IF NOT EXISTS address_key TEXT NOT NULL DEFAULT '';
+
+	-- Houses created before normalisation keep their address as entered,
+	-- their key is only lower cased and stripped of punctuation.
+	UPDATE houses SET
+		postcode = substring(address FROM '(?:^|,)\s*(\d{6})\s*(?:,|$)'),
+		address_key = btrim(regexp_replace(
+			translate(lower(address), 'ё.,', 'е  '), '\s+', ' ', 'g'
+		));
+
+	CREATE INDEX IF NOT EXISTS houses_address_key_trgm_idx ON houses USING GIN (address_key gin_trgm_ops);
+	`,
+	`
+	ALTER TABLE houses
+		ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION NULL CHECK (lat BETWEEN -90 AND 90),
+		ADD COLUMN IF NOT EXISTS lon DOUBLE PRECISION NULL CHECK (lon BETWEEN -180 AND 180),
+		ADD CONSTRAINT houses_lat_lon_check CHECK ((lat IS NULL) = (lon IS NULL));
+
+	CREATE INDEX IF NOT EXISTS houses_lat_lon_idx ON houses (lat, lon) WHERE lat IS NOT NULL;
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS flat_photos (
+		id BIGSERIAL PRIMARY KEY,
+		flat_id INTEGER NOT NULL REFERENCES flats(id) ON DELETE CASCADE,
+		kind VARCHAR(20) NOT NULL CHECK (kind IN ('photo', 'floor_plan')),
+		position INTEGER NOT NULL CHECK (position >= 1),
+		content_type TEXT NOT NULL,
+		size BIGINT NOT NULL CHECK (size > 0),
+		width INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		blob_key TEXT NOT NULL UNIQUE,
+		thumbnail_key TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL DEFAULT now(),
+		-- Deferred, so that a reorder can swap positions in one statement.
+		UNIQUE (flat_id, position) DEFERRABLE INITIALLY DEFERRED
+	);
+	`,
+	`
+	ALTER TABLE flats
+		ADD COLUMN IF NOT EXISTS floor INTEGER NULL CHECK (floor BETWEEN 1 AND 200),
+		ADD COLUMN IF NOT EXISTS total_area NUMERIC(7, 2) NULL CHECK (total_area > 0),
+		ADD COLUMN IF NOT EXISTS living_area NUMERIC(7, 2) NULL CHECK (living_area > 0),
+		ADD COLUMN IF NOT EXISTS ceiling_height NUMERIC(4, 2) NULL CHECK (ceiling_height > 0),
+		ADD COLUMN IF NOT EXISTS balcony VARCHAR(20) NULL CHECK (balcony IN ('none', 'balcony', 'loggia')),
+		ADD COLUMN IF NOT EXISTS renovation VARCHAR(20) NULL CHECK (renovation IN ('none', 'cosmetic', 'euro', 'designer')),
+		ADD COLUMN IF NOT EXISTS amenities TEXT[] NOT NULL DEFAULT '{}' CHECK (amenities <@ ARRAY[
+			'furniture', 'appliances', 'air_conditioning', 'internet',
+			'washing_machine', 'dishwasher', 'fridge', 'tv'
+		]),
+		ADD CONSTRAINT flats_living_area_check CHECK (living_area <= total_area);
+
+	CREATE INDEX IF NOT EXISTS flats_approved_total_area_idx ON flats (total_area) WHERE status = 'approved';
+	CREATE INDEX IF NOT EXISTS flats_approved_amenities_idx ON flats USING GIN (amenities) WHERE status = 'approved';
+	`,
+	`
+	CREATE TABLE IF NOT EXISTS developers (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL CHECK (length(name) > 0),
+		website TEXT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS developers_name_idx ON developers (lower(name));
+
+	-- Developers of existing houses become developers of their own, the
+	-- houses are linked to them by name.
+	INSERT INTO developers (name)
+	SELECT DISTINCT ON (lower(btrim(developer))) btrim(developer)
+	FROM houses
+	WHERE btrim(developer) <> ''
+	ORDER BY lower(btrim(developer)), btrim(developer)
+	ON CONFLICT DO NOTHING;
+
+	ALTER TABLE houses
+		ADD COLUMN IF NOT EXISTS developer_id INTEGER NULL REFERENCES developers(id),
+		ADD COLUMN IF NOT EXISTS floors INTEGER NULL CHECK (floors BETWEEN 1 AND 200),
+		ADD COLUMN IF NOT EXISTS material VARCHAR(20) NULL CHECK (material IN ('brick', 'panel', 'monolith', 'monolith_brick', 'block', 'wood')),
+		ADD COLUMN IF NOT EXISTS parking VARCHAR(20) NULL CHECK (parking IN ('none', 'open', 'covered', 'underground')),
+		ADD COLUMN IF NOT EXISTS elevators INTEGER NULL CHECK (elevators BETWEEN 0 AND 50),
+		ADD COLUMN IF NOT EXISTS construction_status VARCHAR(20) NOT NULL DEFAULT 'built' CHECK (construction_status IN ('built', 'under_construction')),
+		ADD COLUMN IF NOT EXISTS completion_quarter VARCHAR(7) NULL CHECK (completion_quarter ~ '^[0-9]{4}-Q[1-4]$'),
+		ADD CONSTRAINT houses_completion_check CHECK ((construction_status = 'under_construction') = (completion_quarter IS NOT NULL));
+
+	UPDATE houses h SET developer_id = d.id
+	FROM developers d
+	WHERE lower(btrim(h.developer)) = lower(d.name);
+
+	CREATE INDEX IF NOT EXISTS houses_developer_id_idx ON houses (developer_id);
+
+	-- func_update_at now also stamps a house when any of its own fields
+	-- changes, not only when a flat is added to it. Created after the
+	-- backfill above, which is not a change of the houses.
+	CREATE OR REPLACE FUNCTION func_update_at()
+	RETURNS TRIGGER AS $$
+	BEGIN
+		IF TG_TABLE_NAME = 'houses' THEN
+			IF (to_jsonb(NEW) - 'update_at') IS DISTINCT FROM (to_jsonb(OLD) - 'update_at') THEN
+				NEW.update_at = CURRENT_TIMESTAMP;
+			END IF;
+			RETURN NEW;
+		END IF;
+
+		UPDATE houses
+		SET update_at = CURRENT_TIMESTAMP
+		WHERE id = NEW.house_id;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS func_update_at_houses_trigger ON houses;
+
+	CREATE TRIGGER func_update_at_houses_trigger
+	BEFORE UPDATE ON houses
+	FOR EACH ROW
+	EXECUTE FUNCTION func_update_at();
+	`,
+	`
+	ALTER TABLE notifications
+		ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP NULL,
+		DROP CONSTRAINT IF EXISTS notifications_status_check,
+		ADD CONSTRAINT notifications_status_check CHECK (status IN ('pending', 'sending', 'sent', 'failed'));
+
+	DROP INDEX IF EXISTS idx_notifications_pending;
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_pending
+	ON notifications (id) WHERE status IN ('pending', 'sending');
+	`,
+	`
+	ALTER TABLE users
+		ADD COLUMN IF NOT EXISTS reset_expires_at TIMESTAMP NULL;
+
+	UPDATE users SET reset_expires_at = CURRENT_TIMESTAMP + INTERVAL '24 hours'
+	WHERE reset_token IS NOT NULL AND reset_expires_at IS NULL;
+	`,
+	`
+	UPDATE audit_log
+	SET details = jsonb_build_object('query_sha256', encode(sha256(convert_to(lower(details->>'email'), 'UTF8')), 'hex'))
+	WHERE action = 'search_users' AND details ? 'email';
+	`,
+	`
+	ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS etag TEXT NOT NULL DEFAULT '';
+	`,
+}
+
+func migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	const fn = "storage.postgres.migrate"
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	defer conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var current int
+	err = conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	for version := current + 1; version <= len(migrations); version++ {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
+		}
+
+		if _, err = tx.Exec(ctx, migrations[version-1]); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("%s: migration %d: %w", fn, version, err)
+		}
+
+		if _, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("%s: migration %d: %w", fn, version, err)
+		}
+
+		if err = tx.Commit(ctx); err != nil {
+			return fmt.Errorf("%s: migration %d: %w", fn, version, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+// claimNotificationsQuery marks up to $1 pending notifications as being
+// sent, oldest first. SKIP LOCKED keeps replicas running the job at the
+// same time from claiming the same rows. A claim older than $2 seconds is
+// taken over: its replica stopped before setting the result.
+const claimNotificationsQuery = `
+	WITH claimed AS (
+		UPDATE notifications
+		SET status = 'sending', claimed_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE status = 'pending'
+				OR (status = 'sending' AND claimed_at < now() - make_interval(secs => $2))
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, house_id, email, message, status, created_at
+	)
+	SELECT id, house_id, email, message, status, created_at
+	FROM claimed
+	ORDER BY id
+`
+
+// ClaimNotifications takes notifications that were persisted instead of
+// sent for this replica to send, oldest first. Each one must get its result
+// from SetNotificationStatus within timeout, after that it is handed out
+// again.
+func (s *Storage) ClaimNotifications(ctx context.Context, limit int, timeout time.Duration) ([]entity.Notification, error) {
+	const fn = "storage.postgres.ClaimNotifications"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, claimNotificationsQuery, limit, timeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer rows.Close()
+
+	var notifications []entity.Notification
+
+	for rows.Next() {
+		var n entity.Notification
+		if err = rows.Scan(&n.ID, &n.HouseID, &n.Email, &n.Message, &n.Status, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return notifications, nil
+}
+
+func (s *Storage) SetNotificationStatus(ctx context.Context, id int64, status string) error {
+	const fn = "storage.postgres.SetNotificationStatus"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.Update("notifications").
+		Set("status", status).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	res, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("%s: notification %d: %w", fn, id, storage.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+)
+
+// photoColumns are the columns scanned by scanPhoto, in order.
+const photoColumns = "id, flat_id, kind, position, content_type, size, width, height, blob_key, thumbnail_key, created_at"
+
+func scanPhoto(row pgx.Row) (entity.Photo, error) {
+	var p entity.Photo
+	err := row.Scan(&p.ID, &p.FlatID, &p.Kind, &p.Position, &p.ContentType, &p.Size, &p.Width, &p.Height, &p.Key, &p.ThumbnailKey, &p.CreatedAt)
+
+	return p, err
+}
+
+// AddPhoto attaches photo to its flat after the last one. A flat holds at
+// most limit photos, beyond that it is ErrConflict.
+func (s *Storage) AddPhoto(ctx context.Context, photo entity.Photo, limit int) (entity.Photo, error) {
+	const fn = "storage.postgres.AddPhoto"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the flat row also serializes concurrent uploads, which would
+	// otherwise take the same position.
+	if err = touchFlat(ctx, tx, photo.FlatID); err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	var count, last int
+	err = tx.QueryRow(ctx, `
+		SELECT count(*), COALESCE(max(position), 0)
+		FROM flat_photos
+		WHERE flat_id = $1
+	`, photo.FlatID).Scan(&count, &last)
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if count >= limit {
+		return entity.Photo{}, fmt.Errorf("%s: flat %d has %d photos: %w", fn, photo.FlatID, count, storage.ErrConflict)
+	}
+
+	photo, err = scanPhoto(tx.QueryRow(ctx, `
+		INSERT INTO flat_photos (flat_id, kind, position, content_type, size, width, height, blob_key, thumbnail_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+photoColumns,
+		photo.FlatID, photo.Kind, last+1, photo.ContentType, photo.Size, photo.Width, photo.Height, photo.Key, photo.ThumbnailKey,
+	))
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return photo, nil
+}
+
+// FlatPhotos returns the photos of a flat in display order.
+func (s *Storage) FlatPhotos(ctx context.Context, flatID int64) ([]entity.Photo, error) {
+	const fn = "storage.postgres.FlatPhotos"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	photos, err := queryPhotos(ctx, s.db, `SELECT `+photoColumns+` FROM flat_photos WHERE flat_id = $1 ORDER BY position`, flatID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return photos, nil
+}
+
+func (s *Storage) GetPhoto(ctx context.Context, flatID, photoID int64) (entity.Photo, error) {
+	const fn = "storage.postgres.GetPhoto"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	photo, err := scanPhoto(s.db.QueryRow(ctx, `
+		SELECT `+photoColumns+` FROM flat_photos WHERE flat_id = $1 AND id = $2
+	`, flatID, photoID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Photo{}, fmt.Errorf("%s: photo %d: %w", fn, photoID, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return photo, nil
+}
+
+// DeletePhoto detaches a photo and moves the photos after it one position
+// up. The deleted photo is returned, its blobs are left to the caller.
+func (s *Storage) DeletePhoto(ctx context.Context, flatID, photoID int64) (entity.Photo, error) {
+	const fn = "storage.postgres.DeletePhoto"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer tx.Rollback(ctx)
+
+	photo, err := scanPhoto(tx.QueryRow(ctx, `
+		DELETE FROM flat_photos WHERE flat_id = $1 AND id = $2 RETURNING `+photoColumns,
+		flatID, photoID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Photo{}, fmt.Errorf("%s: photo %d: %w", fn, photoID, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE flat_photos SET position = position - 1 WHERE flat_id = $1 AND position > $2
+	`, flatID, photo.Position)
+	if err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = touchFlat(ctx, tx, flatID); err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Photo{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return photo, nil
+}
+
+// ReorderPhotos puts the photos of a flat in the order of ids, which must
+// list every photo of the flat exactly once, otherwise it is ErrConstraint.
+func (s *Storage) ReorderPhotos(ctx context.Context, flatID int64, ids []int64) ([]entity.Photo, error) {
+	const fn = "storage.postgres.ReorderPhotos"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer tx.Rollback(ctx)
+
+	if err = touchFlat(ctx, tx, flatID); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	// Every photo of the flat has to be matched by exactly one id.
+	res, err := tx.Exec(ctx, `
+		UPDATE flat_photos p
+		SET position = o.position
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position)
+		WHERE p.flat_id = $1 AND p.id = o.id
+	`, flatID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	var count int64
+	if err = tx.QueryRow(ctx, `SELECT count(*) FROM flat_photos WHERE flat_id = $1`, flatID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if res.RowsAffected() != count || int64(len(ids)) != count {
+		return nil, fmt.Errorf("%s: %d ids for %d photos: %w", fn, len(ids), count, storage.ErrConstraint)
+	}
+
+	photos, err := queryPhotos(ctx, tx, `SELECT `+photoColumns+` FROM flat_photos WHERE flat_id = $1 ORDER BY position`, flatID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return photos, nil
+}
+
+// touchFlat bumps the version of a flat whose photos change, they are part
+// of its representation and so of its ETag. It locks the flat row until the
+// end of tx.
+func touchFlat(ctx context.Context, tx pgx.Tx, flatID int64) error {
+	res, err := tx.Exec(ctx, `UPDATE flats SET version = version + 1 WHERE id = $1`, flatID)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("flat %d: %w", flatID, storage.ErrNotFound)
+	}
+
+	return nil
+}
+
+// attachPhotos loads the photos of the approved flats among flats. Photos
+// of other flats are not shown, so they are not loaded.
+func (s *Storage) attachPhotos(ctx context.Context, flats []entity.Flat) error {
+	ids := make([]int64, 0, len(flats))
+	for _, f := range flats {
+		if f.Status == "approved" {
+			ids = append(ids, f.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	photos, err := queryPhotos(ctx, s.db, `
+		SELECT `+photoColumns+` FROM flat_photos WHERE flat_id = ANY($1) ORDER BY flat_id, position
+	`, ids)
+	if err != nil {
+		return err
+	}
+
+	byFlat := make(map[int64][]entity.Photo, len(ids))
+	for _, p := range photos {
+		byFlat[p.FlatID] = append(byFlat[p.FlatID], p)
+	}
+
+	for i := range flats {
+		flats[i].Photos = byFlat[flats[i].ID]
+	}
+
+	return nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func queryPhotos(ctx context.Context, q querier, sql string, args ...any) ([]entity.Photo, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	photos := make([]entity.Photo, 0)
+
+	for rows.Next() {
+		photo, err := scanPhoto(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		photos = append(photos, photo)
+	}
+
+	return photos, rows.Err()
+}
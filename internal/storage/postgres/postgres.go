@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/address"
+	"avito_tech/internal/storage"
 	"context"
 	"errors"
 	"fmt"
@@ -9,123 +11,136 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
 	"time"
 )
 
+const (
+	connectBackoff    = time.Millisecond * 100
+	maxConnectBackoff = time.Second * 2
+)
+
 type Storage struct {
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	queryTimeout time.Duration
 }
 
-func New(storagePath string) (*Storage, error) {
+// New connects to storagePath and applies pending migrations. Every storage
+// call is bounded by queryTimeout on top of the deadline of its context;
+// zero disables the bound. While the database is still starting, New keeps
+// retrying for up to connectTimeout.
+func New(storagePath string, queryTimeout, connectTimeout time.Duration) (*Storage, error) {
 	const fn = "storage.postgres.New"
-	ctx := context.Background()
 
-	pool, err := pgxpool.New(ctx, storagePath)
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	cfg, err := pgxpool.ParseConfig(storagePath)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
+	cfg.ConnConfig.Tracer = queryTracer{}
 
-	// TODO add sql migration
-	_, err = pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS houses (
-			id INTEGER PRIMARY KEY CHECK (id >= 1),
-			address TEXT NOT NULL,
-			year INTEGER NOT NULL CHECK (year >= 0),
-			developer TEXT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			update_at TIMESTAMP NULL
-		);
-	`)
-
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err = connect(ctx, pool); err != nil {
 		pool.Close()
 		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	_, err = pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS users (
-			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			email TEXT NOT NULL UNIQUE CHECK (length(email) > 0),
-			password TEXT NOT NULL CHECK (length(password) > 0),
-			user_type VARCHAR(50) NOT NULL DEFAULT 'client' CHECK (user_type IN ('client', 'moderator'))
-		);
-	`)
-
-	if err != nil {
+	if err = migrate(ctx, pool); err != nil {
 		pool.Close()
-		return nil, fmt.Errorf("%s: %w", fn, err)
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
-	_, err = pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS flats (
-			id SERIAL PRIMARY KEY,
-			user_id UUID NOT NULL REFERENCES users(id),
-			house_id INTEGER NOT NULL REFERENCES houses(id),
-			number INTEGER NOT NULL CHECK (number >= 1),
-			price INTEGER NOT NULL CHECK (price >= 0),
-			rooms INTEGER NOT NULL CHECK (rooms >= 1),
-			status VARCHAR(50) NOT NULL CHECK (status IN ('created', 'approved', 'declined', 'on moderation')),
-			last_moderator_id UUID NULL
-		);
-	`)
+	return &Storage{db: pool, queryTimeout: queryTimeout}, nil
+}
 
-	if err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("%s: %w", fn, err)
+// connect pings the database until it answers. Only temporary errors
+// (connection refused, "the database system is starting up") are retried,
+// wrong credentials fail right away.
+func connect(ctx context.Context, pool *pgxpool.Pool) error {
+	backoff := connectBackoff
+
+	for {
+		err := classify(pool.Ping(ctx))
+		if err == nil || !errors.Is(err, storage.ErrTemporary) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxConnectBackoff)
 	}
+}
 
-	_, err = pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS subscriptions (
-			id SERIAL PRIMARY KEY,
-			house_id INT NOT NULL,
-			email TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		);
-	`)
+// Ping checks that the database answers.
+func (s *Storage) Ping(ctx context.Context) error {
+	const fn = "storage.postgres.Ping"
 
-	if err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("%s: %w", fn, err)
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.db.Ping(ctx); err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
-	_, err = pool.Exec(ctx, `
-		CREATE INDEX IF NOT EXISTS idx_flats_house_id_status
-		ON flats (house_id, status);
-	`)
+	return nil
+}
+
+// CheckMigrations fails if the schema is behind the migrations this binary
+// ships, e.g. when another replica holds the migration lock.
+func (s *Storage) CheckMigrations(ctx context.Context) error {
+	const fn = "storage.postgres.CheckMigrations"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var current int
+	err := s.db.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
 	if err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("%s: %w", fn, err)
+		return fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
-	_, err = pool.Exec(ctx, `
-		DROP TRIGGER IF EXISTS func_update_at_trigger ON flats;
+	if current < len(migrations) {
+		return fmt.Errorf("%s: schema version %d, want %d", fn, current, len(migrations))
+	}
 
-		CREATE OR REPLACE FUNCTION func_update_at()
-		RETURNS TRIGGER AS $$
-		BEGIN
-			UPDATE houses
-			SET update_at = CURRENT_TIMESTAMP
-			WHERE id = NEW.house_id;
-			RETURN NEW;
-		END;
-		$$ LANGUAGE plpgsql;
+	return nil
+}
 
-		CREATE TRIGGER func_update_at_trigger
-		AFTER INSERT ON flats
-		FOR EACH ROW
-		EXECUTE FUNCTION func_update_at();
-	`)
-	if err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("%s: %w", fn, err)
+// Close closes the pool. Call it after everything using the storage has
+// stopped.
+func (s *Storage) Close() {
+	s.db.Close()
+}
+
+// Stat reports pool statistics for metrics.
+func (s *Storage) Stat() *pgxpool.Stat {
+	return s.db.Stat()
+}
+
+func (s *Storage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
 
-	return &Storage{db: pool}, nil
+	return context.WithTimeout(ctx, s.queryTimeout)
 }
 
-func (s *Storage) CreateUser(user entity.User) (uuid.UUID, error) {
+func (s *Storage) CreateUser(ctx context.Context, user entity.User) (uuid.UUID, error) {
 	const fn = "storage.postgres.CreateUser"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	query, args, err := squirrel.
 		Insert("users").
 		Columns("email", "password", "user_type").
@@ -135,23 +150,24 @@ func (s *Storage) CreateUser(user entity.User) (uuid.UUID, error) {
 		ToSql()
 
 	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, err)
+		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	var id uuid.UUID
-	ctx := context.Background()
-
 	err = s.db.QueryRow(ctx, query, args...).Scan(&id)
 	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, err)
+		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return id, nil
 }
 
-func (s *Storage) CreateH(house entity.House) (int64, error) {
+func (s *Storage) CreateH(ctx context.Context, house entity.House) (int64, error) {
 	const fn = "storage.postgres.CreateHouse"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	var developerValue interface{}
 	if house.Developer == "" {
 		developerValue = nil
@@ -159,151 +175,396 @@ func (s *Storage) CreateH(house entity.House) (int64, error) {
 		developerValue = house.Developer
 	}
 
+	constructionStatus := house.ConstructionStatus
+	if constructionStatus == "" {
+		constructionStatus = "built"
+	}
+
 	query, args, err := squirrel.
 		Insert("houses").
-		Columns("id", "address", "year", "developer", "created_at").
-		Values(house.ID, house.Address, house.Year, developerValue, time.Now()).
+		Columns("id", "address", "postcode", "address_key", "year", "developer", "lat", "lon",
+			"developer_id", "floors", "material", "parking", "elevators", "construction_status", "completion_quarter", "created_at").
+		Values(house.ID, house.Address, nullString(house.Postcode), address.Key(house.Address), house.Year, developerValue, house.Lat, house.Lon,
+			house.DeveloperID, house.Floors, nullString(house.Material), nullString(house.Parking), house.Elevators, constructionStatus, nullString(house.CompletionQuarter), time.Now()).
 		Suffix("RETURNING id").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
 	if err != nil {
-		return -1, fmt.Errorf("%s: %w", fn, err)
+		return -1, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	var id int64
-	ctx := context.Background()
-
 	err = s.db.QueryRow(ctx, query, args...).Scan(&id)
 	if err != nil {
-		return -1, fmt.Errorf("%s: %w", fn, err)
+		return -1, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return id, nil
 }
 
-func (s *Storage) GetAllFlats(id int64, role string) ([]entity.Flat, error) {
+func (s *Storage) GetHouse(ctx context.Context, id int64) (entity.House, error) {
+	const fn = "storage.postgres.GetHouse"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	house, err := scanHouse(s.db.QueryRow(ctx, `SELECT `+houseColumns+` FROM houses WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.House{}, fmt.Errorf("%s: house %d: %w", fn, id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.House{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return house, nil
+}
+
+// UpdateHouse applies patch if the house is still at patch.Version, a zero
+// Version skips the check. A new developer also replaces the developer
+// name. It returns the house as stored.
+func (s *Storage) UpdateHouse(ctx context.Context, patch entity.HousePatch) (entity.House, error) {
+	const fn = "storage.postgres.UpdateHouse"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if patch.ID == 0 || patch.Empty() {
+		return entity.House{}, fmt.Errorf("%s: invalid arguments: %w", fn, storage.ErrConstraint)
+	}
+
+	where := squirrel.And{squirrel.Eq{"id": patch.ID}}
+	if patch.Version != 0 {
+		where = append(where, squirrel.Eq{"version": patch.Version})
+	}
+
+	queryBuilder := squirrel.Update("houses").
+		Set("version", squirrel.Expr("version + 1")).
+		Suffix("RETURNING " + houseColumns).
+		PlaceholderFormat(squirrel.Dollar)
+
+	if patch.Year != nil {
+		queryBuilder = queryBuilder.Set("year", *patch.Year)
+	}
+	if patch.DeveloperID != nil {
+		queryBuilder = queryBuilder.
+			Set("developer_id", *patch.DeveloperID).
+			Set("developer", squirrel.Expr("(SELECT name FROM developers WHERE id = ?)", *patch.DeveloperID))
+	}
+	if patch.Floors != nil {
+		queryBuilder = queryBuilder.Set("floors", *patch.Floors)
+	}
+	if patch.Material != nil {
+		queryBuilder = queryBuilder.Set("material", *patch.Material)
+	}
+	if patch.Parking != nil {
+		queryBuilder = queryBuilder.Set("parking", *patch.Parking)
+	}
+	if patch.Elevators != nil {
+		queryBuilder = queryBuilder.Set("elevators", *patch.Elevators)
+	}
+	if patch.ConstructionStatus != nil {
+		queryBuilder = queryBuilder.Set("construction_status", *patch.ConstructionStatus)
+	}
+	if patch.CompletionQuarter != nil {
+		queryBuilder = queryBuilder.Set("completion_quarter", nullString(*patch.CompletionQuarter))
+	}
+
+	query, args, err := queryBuilder.Where(where).ToSql()
+	if err != nil {
+		return entity.House{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	house, err := scanHouse(s.db.QueryRow(ctx, query, args...))
+	if err == nil {
+		return house, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return entity.House{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	// Nothing updated: either there is no such house or it was changed
+	// since patch.Version.
+	var current int64
+	err = s.db.QueryRow(ctx, `SELECT version FROM houses WHERE id = $1`, patch.ID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.House{}, fmt.Errorf("%s: house %d: %w", fn, patch.ID, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.House{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return entity.House{}, fmt.Errorf("%s: house %d is at version %d: %w", fn, patch.ID, current, storage.ErrVersionMismatch)
+}
+
+func (s *Storage) GetAllFlats(ctx context.Context, id int64, role string) ([]entity.Flat, error) {
 	const fn = "storage.postgres.Get"
-	ctx := context.Background()
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
 	var rows pgx.Rows
 	var err error
 
 	if role != "moderator" {
 		rows, err = s.db.Query(ctx, `
-			SELECT *
+			SELECT `+flatColumns+`
 			FROM flats
 			WHERE house_id = $1 and status = 'approved'
+			ORDER BY id
 		`, id)
 
 	} else {
 		rows, err = s.db.Query(ctx, `
-			SELECT *
+			SELECT `+flatColumns+`
 			FROM flats
 			WHERE house_id = $1
+			ORDER BY id
 		`, id)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", fn, err)
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 	defer rows.Close()
 
 	var flats []entity.Flat
 
 	for rows.Next() {
-		var flat entity.Flat
-		err := rows.Scan(
-			&flat.HouseID,
-			&flat.Number,
-			&flat.Price,
-			&flat.Rooms,
-			&flat.Status,
-		)
+		flat, err := scanFlat(rows)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", fn, err)
+			return nil, fmt.Errorf("%s: %w", fn, classify(err))
 		}
 		flats = append(flats, flat)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: %w", fn, err)
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if err := s.attachPhotos(ctx, flats); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return flats, nil
 }
 
-func (s *Storage) CreateF(flat entity.Flat) (int64, error) {
+func (s *Storage) CreateF(ctx context.Context, flat entity.Flat) (int64, error) {
 	const fn = "storage.postgres.CreateFlat"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	amenities := flat.Amenities
+	if amenities == nil {
+		amenities = []string{}
+	}
+
 	query, args, err := squirrel.
 		Insert("flats").
-		Columns("user_id", "house_id", "number", "price", "rooms", "status").
-		Values(flat.UserID, flat.HouseID, flat.Number, flat.Price, flat.Rooms, "created").
+		Columns("user_id", "house_id", "number", "price", "rooms", "status",
+			"floor", "total_area", "living_area", "ceiling_height", "balcony", "renovation", "amenities").
+		Values(flat.UserID, flat.HouseID, flat.Number, flat.Price, flat.Rooms, "created",
+			flat.Floor, flat.TotalArea, flat.LivingArea, flat.CeilingHeight, nullString(flat.Balcony), nullString(flat.Renovation), amenities).
 		Suffix("RETURNING id").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
 	if err != nil {
-		return -1, fmt.Errorf("%s: %w", fn, err)
+		return -1, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	var id int64
-	ctx := context.Background()
-
 	err = s.db.QueryRow(ctx, query, args...).Scan(&id)
 
 	if err != nil {
-		return -1, fmt.Errorf("%s: %w", fn, err)
+		return -1, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return id, nil
 }
 
-func (s *Storage) Update(flat entity.Flat, idMod uuid.UUID) error {
+// flatColumns are the columns scanned by scanFlat, in order.
+const flatColumns = "id, user_id, house_id, number, price, rooms, status, version, " +
+	"floor, total_area, living_area, ceiling_height, balcony, renovation, amenities"
+
+// scanFlat scans flatColumns and then the columns selected after them into
+// extra.
+func scanFlat(row pgx.Row, extra ...any) (entity.Flat, error) {
+	var (
+		flat       entity.Flat
+		balcony    *string
+		renovation *string
+	)
+
+	dest := []any{
+		&flat.ID, &flat.UserID, &flat.HouseID, &flat.Number, &flat.Price, &flat.Rooms, &flat.Status, &flat.Version,
+		&flat.Floor, &flat.TotalArea, &flat.LivingArea, &flat.CeilingHeight, &balcony, &renovation, &flat.Amenities,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return entity.Flat{}, err
+	}
+
+	if balcony != nil {
+		flat.Balcony = *balcony
+	}
+	if renovation != nil {
+		flat.Renovation = *renovation
+	}
+
+	return flat, nil
+}
+
+// qualify prefixes every column of a list like flatColumns with the alias
+// of its table.
+func qualify(alias, columns string) string {
+	return alias + "." + strings.ReplaceAll(columns, ", ", ", "+alias+".")
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func (s *Storage) GetFlat(ctx context.Context, id int64) (entity.Flat, error) {
+	const fn = "storage.postgres.GetFlat"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	flat, err := scanFlat(s.db.QueryRow(ctx, `SELECT `+flatColumns+` FROM flats WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Flat{}, fmt.Errorf("%s: flat %d: %w", fn, id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return entity.Flat{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	flats := []entity.Flat{flat}
+	if err = s.attachPhotos(ctx, flats); err != nil {
+		return entity.Flat{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return flats[0], nil
+}
+
+// Update applies patch if the flat is still at patch.Version, a zero
+// Version skips the check. Only a status change takes the flat over from
+// the moderator holding it on moderation. It returns the flat as stored.
+func (s *Storage) Update(ctx context.Context, patch entity.FlatPatch, idMod uuid.UUID) (entity.Flat, error) {
 	const fn = "storage.postgres.Update"
 
-	if !checkFlat(flat) {
-		return fmt.Errorf("invalid arguments: %s", fn)
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if patch.ID == 0 || patch.Empty() {
+		return entity.Flat{}, fmt.Errorf("%s: invalid arguments: %w", fn, storage.ErrConstraint)
+	}
+
+	where := squirrel.And{squirrel.Eq{"id": patch.ID}}
+	if patch.Version != 0 {
+		where = append(where, squirrel.Eq{"version": patch.Version})
 	}
 
 	queryBuilder := squirrel.Update("flats").
-		Where(squirrel.And{
-			squirrel.Eq{"id": flat.ID},
-			squirrel.Or{
-				squirrel.NotEq{"status": "on moderation"},
-				squirrel.Eq{"last_moderator_id": idMod},
-			},
-		}).
-		Set("house_id", flat.HouseID).
-		Set("number", flat.Number).
-		Set("price", flat.Price).
-		Set("rooms", flat.Rooms).
-		Set("status", flat.Status).
-		Set("last_moderator_id", idMod).
+		Set("version", squirrel.Expr("version + 1")).
+		Suffix("RETURNING " + flatColumns).
 		PlaceholderFormat(squirrel.Dollar)
 
-	query, args, err := queryBuilder.ToSql()
+	if patch.HouseID != nil {
+		queryBuilder = queryBuilder.Set("house_id", *patch.HouseID)
+	}
+	if patch.Number != nil {
+		queryBuilder = queryBuilder.Set("number", *patch.Number)
+	}
+	if patch.Price != nil {
+		queryBuilder = queryBuilder.Set("price", *patch.Price)
+	}
+	if patch.Rooms != nil {
+		queryBuilder = queryBuilder.Set("rooms", *patch.Rooms)
+	}
+	if patch.Floor != nil {
+		queryBuilder = queryBuilder.Set("floor", *patch.Floor)
+	}
+	if patch.TotalArea != nil {
+		queryBuilder = queryBuilder.Set("total_area", *patch.TotalArea)
+	}
+	if patch.LivingArea != nil {
+		queryBuilder = queryBuilder.Set("living_area", *patch.LivingArea)
+	}
+	if patch.CeilingHeight != nil {
+		queryBuilder = queryBuilder.Set("ceiling_height", *patch.CeilingHeight)
+	}
+	if patch.Balcony != nil {
+		queryBuilder = queryBuilder.Set("balcony", *patch.Balcony)
+	}
+	if patch.Renovation != nil {
+		queryBuilder = queryBuilder.Set("renovation", *patch.Renovation)
+	}
+	if patch.Amenities != nil {
+		amenities := *patch.Amenities
+		if amenities == nil {
+			amenities = []string{}
+		}
+		queryBuilder = queryBuilder.Set("amenities", amenities)
+	}
+	if patch.Status != nil {
+		where = append(where, squirrel.Or{
+			squirrel.NotEq{"status": "on moderation"},
+			squirrel.Eq{"last_moderator_id": idMod},
+		})
+		queryBuilder = queryBuilder.
+			Set("status", *patch.Status).
+			Set("last_moderator_id", idMod)
+	}
+
+	query, args, err := queryBuilder.Where(where).ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to build query %s: %w", fn, err)
+		return entity.Flat{}, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	res, err := s.db.Exec(context.Background(), query, args...)
+	flat, err := scanFlat(s.db.QueryRow(ctx, query, args...))
+	if err == nil {
+		flats := []entity.Flat{flat}
+		if err = s.attachPhotos(ctx, flats); err != nil {
+			return entity.Flat{}, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+
+		return flats[0], nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return entity.Flat{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	// Nothing updated: either there is no such flat, it was changed since
+	// patch.Version, or another moderator holds it on moderation.
+	var current int64
+	err = s.db.QueryRow(ctx, `SELECT version FROM flats WHERE id = $1`, patch.ID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.Flat{}, fmt.Errorf("%s: flat %d: %w", fn, patch.ID, storage.ErrNotFound)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update flat %s: %w", fn, err)
+		return entity.Flat{}, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
-	rowsAffected := res.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows were update %s", fn)
+	if patch.Version != 0 && current != patch.Version {
+		return entity.Flat{}, fmt.Errorf("%s: flat %d is at version %d: %w", fn, patch.ID, current, storage.ErrVersionMismatch)
 	}
 
-	return nil
+	return entity.Flat{}, fmt.Errorf("%s: flat %d is on moderation: %w", fn, patch.ID, storage.ErrLocked)
 }
 
-func (s *Storage) Register(user entity.User) (string, error) {
+func (s *Storage) Register(ctx context.Context, user entity.User) (string, error) {
 	const fn = "storage.postgres.Register"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryBuilder := squirrel.Insert("users").
 		Columns("email", "password", "user_type").
 		Values(user.Email, user.Password, user.UserType).
@@ -312,45 +573,148 @@ func (s *Storage) Register(user entity.User) (string, error) {
 
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
-		return "", fmt.Errorf("failed to build query: %s", fn)
+		return "", fmt.Errorf("%s: %w", fn, err)
 	}
 
 	var id string
-	ctx := context.Background()
-
 	err = s.db.QueryRow(ctx, query, args...).Scan(&id)
 	if err != nil {
-		return "", fmt.Errorf("invalid argument: %s", fn)
+		return "", fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return id, nil
 }
 
-func (s *Storage) Login(email string) (entity.User, error) {
+func (s *Storage) Login(ctx context.Context, email string) (entity.User, error) {
 	const fn = "storage.postgres.Login"
 
-	queryBuilder := squirrel.Select("id", "password", "user_type").
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	queryBuilder := squirrel.Select("id", "password", "user_type", "status", "reset_token IS NOT NULL").
 		From("users").
 		Where(squirrel.Eq{"email": email}).
 		PlaceholderFormat(squirrel.Dollar)
 
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
-		return entity.User{}, fmt.Errorf("failed to build query: %s", fn)
+		return entity.User{}, fmt.Errorf("%s: %w", fn, err)
 	}
 
 	var user entity.User
-	ctx := context.Background()
+	err = s.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Password, &user.UserType, &user.Status, &user.ResetRequired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.User{}, fmt.Errorf("%s: %w", fn, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return entity.User{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return user, nil
+}
+
+// SessionUser returns the role and status of the user a session belongs
+// to. A session that is revoked, expired or belongs to someone else is
+// reported as storage.ErrNotFound.
+func (s *Storage) SessionUser(ctx context.Context, sessionID, userID uuid.UUID) (entity.User, error) {
+	const fn = "storage.postgres.SessionUser"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.Select("u.id", "u.user_type", "u.status").
+		From("sessions s").
+		Join("users u ON u.id = s.user_id").
+		Where(squirrel.Eq{"s.id": sessionID, "s.user_id": userID, "s.revoked_at": nil}).
+		// expires_at is written from a Go time the same way.
+		Where(squirrel.Gt{"s.expires_at": time.Now()}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return entity.User{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
 
-	err = s.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Password, &user.UserType)
+	var user entity.User
+	err = s.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.UserType, &user.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.User{}, fmt.Errorf("%s: %w", fn, storage.ErrNotFound)
+	}
 	if err != nil {
-		return entity.User{}, errors.New("user not found: " + fn)
+		return entity.User{}, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return user, nil
 }
 
-func (s *Storage) Subscribe(sub entity.Subscription) error {
+func (s *Storage) CreateSession(ctx context.Context, session entity.Session) (uuid.UUID, error) {
+	const fn = "storage.postgres.CreateSession"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.
+		Insert("sessions").
+		Columns("user_id", "expires_at").
+		Values(session.UserID, session.ExpiresAt).
+		Suffix("RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	var id uuid.UUID
+	err = s.db.QueryRow(ctx, query, args...).Scan(&id)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return id, nil
+}
+
+func (s *Storage) ResetPassword(ctx context.Context, token uuid.UUID, password string) error {
+	const fn = "storage.postgres.ResetPassword"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := resetPasswordQuery(token, password).ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	res, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", fn, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// resetPasswordQuery consumes a reset token only while it has not expired,
+// so a leaked or forgotten token cannot be used later.
+func resetPasswordQuery(token uuid.UUID, password string) squirrel.UpdateBuilder {
+	return squirrel.Update("users").
+		Set("password", password).
+		Set("reset_token", nil).
+		Set("reset_expires_at", nil).
+		Where(squirrel.Eq{"reset_token": token}).
+		Where("reset_expires_at > now()").
+		PlaceholderFormat(squirrel.Dollar)
+}
+
+func (s *Storage) Subscribe(ctx context.Context, sub entity.Subscription) error {
+	const fn = "storage.postgres.Subscribe"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryBuilder := squirrel.Insert("subscriptions").
 		Columns("house_id", "email").
 		Values(sub.HouseID, sub.Email).
@@ -358,22 +722,23 @@ func (s *Storage) Subscribe(sub entity.Subscription) error {
 
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to build query: %v", err)
+		return fmt.Errorf("%s: %w", fn, err)
 	}
 
-	ctx := context.Background()
-
 	_, err = s.db.Exec(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to execute query: %v", err)
+		return fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
 	return nil
 }
 
-func (s *Storage) GetSubscribers(houseID int64) ([]string, error) {
+func (s *Storage) GetSubscribers(ctx context.Context, houseID int64) ([]string, error) {
 	const fn = "storage.postgres.GetSubscribers"
 
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryBuilder := squirrel.Select("email").
 		From("subscriptions").
 		Where(squirrel.Eq{"house_id": houseID}).
@@ -381,14 +746,12 @@ func (s *Storage) GetSubscribers(houseID int64) ([]string, error) {
 
 	query, args, err := queryBuilder.ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build query: %s, %v", fn, err)
+		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	ctx := context.Background()
-
 	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %s, %v", fn, err)
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 	defer rows.Close()
 
@@ -398,23 +761,73 @@ func (s *Storage) GetSubscribers(houseID int64) ([]string, error) {
 		var email string
 		err = rows.Scan(&email)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %s, %v", fn, err)
+			return nil, fmt.Errorf("%s: %w", fn, classify(err))
 		}
 		emails = append(emails, email)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
 	return emails, nil
 }
 
-func checkFlat(flat entity.Flat) bool {
-	if flat.ID == 0 ||
-		flat.HouseID == 0 ||
-		flat.Number == 0 ||
-		flat.Price == 0 ||
-		flat.Rooms == 0 ||
-		flat.Status == "" {
-		return false
+func (s *Storage) SaveNotification(ctx context.Context, n entity.Notification) error {
+	const fn = "storage.postgres.SaveNotification"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	query, args, err := squirrel.Insert("notifications").
+		Columns("house_id", "email", "message", "status").
+		Values(n.HouseID, n.Email, n.Message, n.Status).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	_, err = s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
+
+// PriceHistory returns every price the flat had, oldest first.
+func (s *Storage) PriceHistory(ctx context.Context, flatID int64) ([]entity.PricePoint, error) {
+	const fn = "storage.postgres.PriceHistory"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT price, changed_at
+		FROM flat_price_history
+		WHERE flat_id = $1
+		ORDER BY changed_at, id
+	`, flatID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer rows.Close()
+
+	var prices []entity.PricePoint
+
+	for rows.Next() {
+		var p entity.PricePoint
+		if err = rows.Scan(&p.Price, &p.ChangedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		prices = append(prices, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
 	}
 
-	return true
+	return prices, nil
 }
@@ -0,0 +1,19 @@
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestResetPasswordQuery(t *testing.T) {
+	token := uuid.New()
+
+	query, args, err := resetPasswordQuery(token, "hash").ToSql()
+	require.NoError(t, err)
+
+	// An expired token must not match any row, so ResetPassword reports
+	// ErrUserNotFound and the handler answers "invalid reset token".
+	require.Equal(t, "UPDATE users SET password = $1, reset_token = $2, reset_expires_at = $3 WHERE reset_token = $4 AND reset_expires_at > now()", query)
+	require.Equal(t, []any{"hash", nil, nil, token.String()}, args)
+}
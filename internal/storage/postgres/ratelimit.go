@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// takeTokenQuery refills the bucket of $1 by the time passed since its last
+// update at $2 tokens per second, capped at $3, and takes a token if there
+// is one. The row lock of the upsert serializes concurrent requests.
+const takeTokenQuery = `
+	INSERT INTO rate_limits AS rl (key, tokens, allowed, updated_at)
+	VALUES ($1, $3::float8 - 1, true, now())
+	ON CONFLICT (key) DO UPDATE SET
+		tokens = CASE
+			WHEN LEAST($3::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $2) >= 1
+			THEN LEAST($3::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $2) - 1
+			ELSE LEAST($3::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $2)
+		END,
+		allowed = LEAST($3::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $2) >= 1,
+		updated_at = now()
+	RETURNING allowed, tokens
+`
+
+// TakeToken takes a token from the bucket of key shared by all replicas.
+func (s *Storage) TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, float64, error) {
+	const fn = "storage.postgres.TakeToken"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		allowed bool
+		tokens  float64
+	)
+
+	err := s.db.QueryRow(ctx, takeTokenQuery, key, rate, burst).Scan(&allowed, &tokens)
+	if err != nil {
+		return false, 0, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return allowed, tokens, nil
+}
+
+// PurgeTokens deletes buckets not used for idle, they are full by then.
+func (s *Storage) PurgeTokens(ctx context.Context, idle time.Duration) error {
+	const fn = "storage.postgres.PurgeTokens"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.Exec(ctx, `DELETE FROM rate_limits WHERE updated_at < now() - make_interval(secs => $1)`, idle.Seconds())
+	if err != nil {
+		return fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"avito_tech/internal/lib/address"
+	"avito_tech/internal/storage"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// duplicateSimilarity is the trigram similarity of address keys from which
+// two houses are reported as the same building.
+const duplicateSimilarity = 0.6
+
+// houseColumns are the columns scanned by scanHouse, in order.
+const houseColumns = "id, address, postcode, year, developer, lat, lon, " +
+	"developer_id, floors, material, parking, elevators, construction_status, completion_quarter, " +
+	"version, created_at, update_at"
+
+// sortColumns are the columns a search can be sorted by. Each is backed by
+// an index together with the approved status filter.
+var sortColumns = map[string]string{
+	"id":    "f.id",
+	"price": "f.price",
+	"rooms": "f.rooms",
+	"year":  "h.year",
+}
+
+// SearchFlats returns a page of approved flats matching filter, sorted by
+// filter.Sort and paginated by keyset, and the number of all matches.
+func (s *Storage) SearchFlats(ctx context.Context, filter entity.FlatFilter) (entity.FlatPage, error) {
+	const fn = "storage.postgres.SearchFlats"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	column, ok := sortColumns[filter.Sort]
+	if !ok || filter.Limit < 1 {
+		return entity.FlatPage{}, fmt.Errorf("%s: invalid arguments: %w", fn, storage.ErrConstraint)
+	}
+
+	where := searchConditions(filter)
+
+	countQuery, countArgs, err := squirrel.Select("count(*)").
+		From("flats f").
+		Join("houses h ON h.id = f.house_id").
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	var page entity.FlatPage
+	if err = s.db.QueryRow(ctx, countQuery, countArgs...).Scan(&page.Total); err != nil {
+		return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	direction, cmp := "ASC", ">"
+	if filter.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		where = append(where, squirrel.Expr("("+column+", f.id) "+cmp+" (?, ?)", filter.After.Value, filter.After.ID))
+	}
+
+	// One row more than asked tells whether there is a next page.
+	query, args, err := squirrel.Select(qualify("f", flatColumns), column).
+		From("flats f").
+		Join("houses h ON h.id = f.house_id").
+		Where(where).
+		OrderBy(column+" "+direction, "f.id "+direction).
+		Limit(uint64(filter.Limit) + 1).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+	defer rows.Close()
+
+	var last entity.FlatCursor
+
+	for rows.Next() {
+		if len(page.Flats) == filter.Limit {
+			page.Next = &last
+			break
+		}
+
+		flat, err := scanFlat(rows, &last.Value)
+		if err != nil {
+			return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, classify(err))
+		}
+		last.ID = flat.ID
+
+		page.Flats = append(page.Flats, flat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	// Release the connection before the next query, the row after the
+	// page may be left unread.
+	rows.Close()
+
+	if err = s.attachPhotos(ctx, page.Flats); err != nil {
+		return entity.FlatPage{}, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return page, nil
+}
+
+func searchConditions(filter entity.FlatFilter) squirrel.And {
+	where := squirrel.And{squirrel.Eq{"f.status": "approved"}}
+
+	bound := func(column string, min, max *int64) {
+		if min != nil {
+			where = append(where, squirrel.GtOrEq{column: *min})
+		}
+		if max != nil {
+			where = append(where, squirrel.LtOrEq{column: *max})
+		}
+	}
+
+	bound("f.price", filter.PriceMin, filter.PriceMax)
+	bound("f.rooms", filter.RoomsMin, filter.RoomsMax)
+	bound("h.year", filter.YearMin, filter.YearMax)
+	bound("f.floor", filter.FloorMin, filter.FloorMax)
+
+	if filter.AreaMin != nil {
+		where = append(where, squirrel.GtOrEq{"f.total_area": *filter.AreaMin})
+	}
+	if filter.AreaMax != nil {
+		where = append(where, squirrel.LtOrEq{"f.total_area": *filter.AreaMax})
+	}
+
+	if filter.HasBalcony != nil {
+		if *filter.HasBalcony {
+			where = append(where, squirrel.Eq{"f.balcony": []string{"balcony", "loggia"}})
+		} else {
+			where = append(where, squirrel.Eq{"f.balcony": "none"})
+		}
+	}
+
+	if len(filter.Renovations) > 0 {
+		where = append(where, squirrel.Eq{"f.renovation": filter.Renovations})
+	}
+
+	if len(filter.Amenities) > 0 {
+		where = append(where, squirrel.Expr("f.amenities @> ?::text[]", filter.Amenities))
+	}
+
+	if filter.Developer != "" {
+		where = append(where, squirrel.Expr("lower(h.developer) = lower(?)", filter.Developer))
+	}
+
+	if filter.Address != "" {
+		where = append(where, squirrel.Expr("to_tsvector('russian', h.address) @@ websearch_to_tsquery('russian', ?)", filter.Address))
+	}
+
+	return where
+}
+
+// SimilarHouses returns houses whose address looks like the same building as
+// addr, most similar first. Houses with another postcode are not similar.
+func (s *Storage) SimilarHouses(ctx context.Context, addr, postcode string) ([]entity.House, error) {
+	const fn = "storage.postgres.SimilarHouses"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+houseColumns+`
+		FROM houses
+		WHERE address_key % $1
+			AND similarity(address_key, $1) >= $2
+			AND ($3 = '' OR postcode IS NULL OR postcode = $3)
+		ORDER BY similarity(address_key, $1) DESC, id
+		LIMIT 5
+	`, address.Key(addr), duplicateSimilarity, postcode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	houses, err := scanHouses(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return houses, nil
+}
+
+// SearchHouses finds houses by address: words of query are matched by
+// full-text search with Russian morphology, typos and abbreviations by
+// trigram similarity. Best matches go first.
+func (s *Storage) SearchHouses(ctx context.Context, query string, limit int) ([]entity.House, error) {
+	const fn = "storage.postgres.SearchHouses"
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+houseColumns+`
+		FROM houses
+		WHERE to_tsvector('russian', address) @@ websearch_to_tsquery('russian', $1)
+			OR address_key % $2
+		ORDER BY greatest(
+			ts_rank(to_tsvector('russian', address), websearch_to_tsquery('russian', $1)),
+			similarity(address_key, $2)
+		) DESC, id
+		LIMIT $3
+	`, query, address.Key(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	houses, err := scanHouses(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, classify(err))
+	}
+
+	return houses, nil
+}
+
+func scanHouses(rows pgx.Rows) ([]entity.House, error) {
+	defer rows.Close()
+
+	houses := make([]entity.House, 0)
+
+	for rows.Next() {
+		house, err := scanHouse(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		houses = append(houses, house)
+	}
+
+	return houses, rows.Err()
+}
+
+// scanHouse scans houseColumns and then the columns selected after them
+// into extra.
+func scanHouse(row pgx.Row, extra ...any) (entity.House, error) {
+	var (
+		house     entity.House
+		postcode  *string
+		developer *string
+		material  *string
+		parking   *string
+		quarter   *string
+		updateAt  *time.Time
+	)
+
+	dest := []any{
+		&house.ID, &house.Address, &postcode, &house.Year, &developer, &house.Lat, &house.Lon,
+		&house.DeveloperID, &house.Floors, &material, &parking, &house.Elevators, &house.ConstructionStatus, &quarter,
+		&house.Version, &house.CreatedFl, &updateAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return entity.House{}, err
+	}
+
+	if postcode != nil {
+		house.Postcode = *postcode
+	}
+	if developer != nil {
+		house.Developer = *developer
+	}
+	if material != nil {
+		house.Material = *material
+	}
+	if parking != nil {
+		house.Parking = *parking
+	}
+	if quarter != nil {
+		house.CompletionQuarter = *quarter
+	}
+	if updateAt != nil {
+		house.UpdateFl = *updateAt
+	}
+
+	return house, nil
+}
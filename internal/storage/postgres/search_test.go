@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"avito_tech/internal/entity"
+	"github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSearchConditions(t *testing.T) {
+	min, max := int64(2), int64(3)
+	area := 54.5
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		filter   entity.FlatFilter
+		expected string
+		args     []any
+	}{
+		{
+			name:     "approved only",
+			filter:   entity.FlatFilter{},
+			expected: "(f.status = $1)",
+			args:     []any{"approved"},
+		},
+		{
+			name:     "bounds",
+			filter:   entity.FlatFilter{RoomsMin: &min, YearMax: &max},
+			expected: "(f.status = $1 AND f.rooms >= $2 AND h.year <= $3)",
+			args:     []any{"approved", min, max},
+		},
+		{
+			name:     "developer and address",
+			filter:   entity.FlatFilter{Developer: "ПИК", Address: "лесная 7"},
+			expected: "(f.status = $1 AND lower(h.developer) = lower($2) AND to_tsvector('russian', h.address) @@ websearch_to_tsquery('russian', $3))",
+			args:     []any{"approved", "ПИК", "лесная 7"},
+		},
+		{
+			name:     "floor and area",
+			filter:   entity.FlatFilter{FloorMin: &min, AreaMax: &area},
+			expected: "(f.status = $1 AND f.floor >= $2 AND f.total_area <= $3)",
+			args:     []any{"approved", min, area},
+		},
+		{
+			name: "balcony, renovation and amenities",
+			filter: entity.FlatFilter{
+				HasBalcony:  &yes,
+				Renovations: []string{"euro", "designer"},
+				Amenities:   []string{"internet", "furniture"},
+			},
+			expected: "(f.status = $1 AND f.balcony IN ($2,$3) AND f.renovation IN ($4,$5) AND f.amenities @> $6::text[])",
+			args:     []any{"approved", "balcony", "loggia", "euro", "designer", []string{"internet", "furniture"}},
+		},
+		{
+			name:     "without balcony",
+			filter:   entity.FlatFilter{HasBalcony: &no},
+			expected: "(f.status = $1 AND f.balcony = $2)",
+			args:     []any{"approved", "none"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := searchConditions(tt.filter).ToSql()
+			require.NoError(t, err)
+
+			sql, err = squirrel.Dollar.ReplacePlaceholders(sql)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expected, sql)
+			require.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestQualify(t *testing.T) {
+	require.Equal(t, "f.id, f.price, f.status", qualify("f", "id, price, status"))
+}
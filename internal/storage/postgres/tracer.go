@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"avito_tech/internal/lib/tracing"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"strings"
+)
+
+// maxStatementLen keeps huge statements (migrations) from bloating spans.
+const maxStatementLen = 2048
+
+var (
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	whitespace    = regexp.MustCompile(`\s+`)
+)
+
+// queryTracer opens a span for every statement sent to postgres. Arguments
+// are never recorded, and the statement is sanitized.
+type queryTracer struct{}
+
+var _ pgx.QueryTracer = queryTracer{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.Tracer().Start(ctx, "postgres.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(sanitize(data.SQL)),
+		),
+	)
+
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, "query failed")
+	} else {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+
+	span.End()
+}
+
+// sanitize replaces string literals with ? and collapses whitespace. Queries
+// built with squirrel already pass values as $N placeholders; this catches
+// literals written into hand-made statements.
+func sanitize(sql string) string {
+	sql = stringLiteral.ReplaceAllString(sql, "?")
+	sql = strings.TrimSpace(whitespace.ReplaceAllString(sql, " "))
+
+	if len(sql) > maxStatementLen {
+		sql = sql[:maxStatementLen] + "..."
+	}
+
+	return sql
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "placeholders kept",
+			sql:      "SELECT id FROM users WHERE email = $1",
+			expected: "SELECT id FROM users WHERE email = $1",
+		},
+		{
+			name:     "literals replaced",
+			sql:      "UPDATE users SET status = 'blocked', note = 'it''s me' WHERE id = $1",
+			expected: "UPDATE users SET status = ?, note = ? WHERE id = $1",
+		},
+		{
+			name:     "whitespace collapsed",
+			sql:      "\n\tSELECT *\n\t\tFROM flats\n",
+			expected: "SELECT * FROM flats",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, sanitize(tt.sql))
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		sql := sanitize("SELECT " + strings.Repeat("a", maxStatementLen))
+		require.Len(t, sql, maxStatementLen+len("..."))
+	})
+}
@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by storage implementations. Handlers match them
+// with errors.Is to pick the response status.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("already exists")
+	ErrConstraint = errors.New("constraint violation")
+	ErrTemporary  = errors.New("temporary failure")
+	// ErrVersionMismatch means the entity was changed since the version the
+	// caller based its update on.
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrLocked means another user holds the entity, e.g. a flat on
+	// moderation by another moderator.
+	ErrLocked = errors.New("locked")
+)
+
+var (
+	ErrUserNotFound = fmt.Errorf("user %w", ErrNotFound)
+)
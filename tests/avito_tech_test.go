@@ -57,8 +57,10 @@ func TestAvitoTechDummyLogin(t *testing.T) {
 	e.GET("/dummyLogin").
 		WithQuery("user_type", "hacker").
 		Expect().
-		Status(http.StatusInternalServerError).
-		JSON().Object()
+		Status(http.StatusBadRequest).
+		JSON().Object().
+		Value("errors").Array().Value(0).Object().
+		Value("field").String().IsEqual("user_type")
 }
 
 func TestAvitoTechHouseCreate(t *testing.T) {
@@ -76,10 +78,9 @@ func TestAvitoTechHouseCreate(t *testing.T) {
 		request entity.House
 	}{
 		{
-			name:    "moderator created house",
-			status:  http.StatusOK,
-			token:   tokenModerator,
-			message: "house added",
+			name:   "moderator created house",
+			status: http.StatusOK,
+			token:  tokenModerator,
 			request: entity.House{
 				ID:      7,
 				Address: "Moscow street, 4",
@@ -88,9 +89,9 @@ func TestAvitoTechHouseCreate(t *testing.T) {
 		},
 		{
 			name:    "not id house",
-			status:  http.StatusInternalServerError,
+			status:  http.StatusBadRequest,
 			token:   tokenModerator,
-			message: "failed to add house",
+			message: "invalid request",
 			request: entity.House{
 				Address: "Moscow street, 4",
 				Year:    2000,
@@ -119,14 +120,20 @@ func TestAvitoTechHouseCreate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			e := httpexpect.Default(t, u.String())
 
-			e.POST("/house/create").
+			resp := e.POST("/house/create").
 				WithHeader("Authorization", "Bearer "+tc.token).
 				WithJSON(tc.request).
 				Expect().
 				Status(tc.status).
-				JSON().Object().
-				ContainsKey("message").
-				Value("message").String().Match(tc.message)
+				JSON().Object()
+
+			if tc.message == "" {
+				resp.Value("id").Number().IsEqual(tc.request.ID)
+				resp.Value("address").String().IsEqual(tc.request.Address)
+				return
+			}
+
+			resp.Value("message").String().Match(tc.message)
 		})
 	}
 }
@@ -169,9 +176,9 @@ func TestAvitoTechFlatsCreate(t *testing.T) {
 		},
 		{
 			name:    "not id house",
-			status:  http.StatusInternalServerError,
+			status:  http.StatusBadRequest,
 			token:   tokenModerator,
-			message: "failed to add flat",
+			message: "constraint violation",
 			request: entity.Flat{
 				HouseID: 99,
 				Number:  197,
@@ -253,7 +260,7 @@ func TestAvitoTechFlatUpdate(t *testing.T) {
 		{
 			name:    "client update flat",
 			status:  http.StatusForbidden,
-			message: "Forbidden",
+			message: "not allowed to change status",
 			token:   tokenClient,
 			request: entity.Flat{
 				ID:      int64(idFlat),
@@ -266,8 +273,8 @@ func TestAvitoTechFlatUpdate(t *testing.T) {
 		},
 		{
 			name:    "new moderator update flat",
-			status:  http.StatusInternalServerError,
-			message: "failed to update flat",
+			status:  http.StatusForbidden,
+			message: "not allowed to change house_id",
 			token:   tokenNewModerator,
 			request: entity.Flat{
 				ID:      int64(idFlat),
@@ -279,10 +286,10 @@ func TestAvitoTechFlatUpdate(t *testing.T) {
 			},
 		},
 		{
-			name:    "not id house",
-			status:  http.StatusInternalServerError,
+			name:    "not status",
+			status:  http.StatusBadRequest,
 			token:   tokenModerator,
-			message: "failed to update flat",
+			message: "invalid request",
 			request: entity.Flat{
 				ID:     int64(idFlat),
 				Number: 197,
@@ -298,6 +305,7 @@ func TestAvitoTechFlatUpdate(t *testing.T) {
 
 			resp := e.POST("/flat/update").
 				WithHeader("Authorization", "Bearer "+tc.token).
+				WithHeader("If-Match", "*").
 				WithJSON(tc.request).
 				Expect().
 				Status(tc.status).